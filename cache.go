@@ -18,11 +18,14 @@ limitations under the License.
 package evego
 
 import (
+	"context"
 	"io"
 	"time"
 )
 
 // Cache is the interface expected by evego packages for a local cache.
+// GetContext and PutContext give up on the cache when their context is done;
+// a Get that gives up is a miss.
 type Cache interface {
 	io.Closer
 
@@ -30,9 +33,11 @@ type Cache interface {
 	// It also returns a boolean flag that is true if there was a hit, and false
 	// if the item was not in the cache or it was expired.
 	Get(key string) ([]byte, bool)
+	GetContext(ctx context.Context, key string) ([]byte, bool)
 
 	// Put takes a key and blob to persist in the cache, and the item's expiry
 	// time. It returns an error if something has gone wrong with the cache,
 	// or nil otherwise.
 	Put(key string, val []byte, expires time.Time) error
+	PutContext(ctx context.Context, key string, val []byte, expires time.Time) error
 }
@@ -19,18 +19,23 @@ package main
 
 import (
 	"bytes"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"text/template"
 
 	"github.com/backerman/evego"
 	"github.com/backerman/evego/pkg/cache"
 	"github.com/backerman/evego/pkg/dbaccess"
 	"github.com/backerman/evego/pkg/eveapi"
+	"github.com/backerman/evego/pkg/industry"
+	"github.com/backerman/evego/pkg/sdediff"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
@@ -70,6 +75,52 @@ var (
 		Short: "Get the character's assets",
 		Run:   characterAssets,
 	}
+	itemCmd = &cobra.Command{
+		Use:   "item",
+		Short: "Item commands",
+		Run:   callHelp,
+	}
+	itemSearchCmd = &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search for items by name",
+		Long: "Search for items whose names match the query exactly, by prefix or " +
+			"substring, or with a few typos.",
+		Run: itemSearch,
+	}
+	sdeCmd = &cobra.Command{
+		Use:   "sde",
+		Short: "Static data export commands",
+		Run:   callHelp,
+	}
+	sdeSnapshotCmd = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save a snapshot of the SDE for use without a SQL database",
+		Long: "Save a snapshot of the SDE that can be loaded into memory without " +
+			"a SQL driver. The SDE may be a SQLite conversion or a directory " +
+			"containing the official export's JSONL files. Pass a snapshot file " +
+			"(ending in .gob) as --sdepath to use it.",
+		Run: sdeSnapshot,
+	}
+	sdeDiffCmd = &cobra.Command{
+		Use:   "diff [old SDE] [new SDE]",
+		Short: "Compare two versions of the SDE",
+		Long: "Report the types, reprocessing materials, blueprints, and station " +
+			"yields that differ between two versions of the SDE. Either may be a " +
+			"snapshot file (ending in .gob).",
+		Run: sdeDiff,
+	}
+	industryCmd = &cobra.Command{
+		Use:   "industry",
+		Short: "Industry commands",
+		Run:   callHelp,
+	}
+	industryPlanCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Schedule industry jobs across characters",
+		Long: "Schedule the industry jobs in a JSON plan request across the " +
+			"characters' manufacturing, science, and reaction slots.",
+		Run: industryPlan,
+	}
 )
 
 var ts *httptest.Server
@@ -114,7 +165,27 @@ func getSDE() evego.Database {
 	if sdePath == "" {
 		log.Fatalf("Error: You must specify the SDE file's path.")
 	}
-	db := dbaccess.SQLDatabase("sqlite3", sdePath)
+	return openSDE(sdePath)
+}
+
+// Get a Database object for the SDE at the given path.
+func openSDE(sdePath string) evego.Database {
+	if strings.HasSuffix(sdePath, ".gob") {
+		snapFile, err := os.Open(sdePath)
+		if err != nil {
+			log.Fatalf("Unable to open SDE snapshot: %v", err)
+		}
+		defer snapFile.Close()
+		snap, err := dbaccess.ReadSnapshot(snapFile)
+		if err != nil {
+			log.Fatalf("Unable to load SDE snapshot: %v", err)
+		}
+		return dbaccess.MemoryDatabase(snap)
+	}
+	db, err := dbaccess.SQLDatabase("sqlite3", sdePath)
+	if err != nil {
+		log.Fatalf("Unable to open SDE: %v", err)
+	}
 	return db
 }
 
@@ -175,7 +246,7 @@ func characterInfo(cmd *cobra.Command, args []string) {
 	}
 	// Set up templating.
 	funcMap := template.FuncMap{
-		"roman": romanNumerals,
+		"roman": evego.RomanNumeral,
 	}
 	tmpl, err := template.New("charsheet").Funcs(funcMap).Parse(charsheetTmpl)
 	if err != nil {
@@ -233,6 +304,126 @@ func characterAssets(cmd *cobra.Command, args []string) {
 	printAssets(sde, assets)
 }
 
+func sdeSnapshot(cmd *cobra.Command, args []string) {
+	sdePath := viper.GetString("sdepath")
+	outPath := viper.GetString("out")
+	if sdePath == "" || outPath == "" {
+		log.Fatalf("Error: You must specify the SDE file's path and the output file.")
+	}
+	var (
+		snap *dbaccess.Snapshot
+		err  error
+	)
+	if info, statErr := os.Stat(sdePath); statErr == nil && info.IsDir() {
+		snap, err = dbaccess.SnapshotFromSDE(sdePath)
+	} else {
+		snap, err = dbaccess.SnapshotFromSQL("sqlite3", sdePath)
+	}
+	if err != nil {
+		log.Fatalf("Unable to take snapshot: %v", err)
+	}
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		log.Fatalf("Unable to create snapshot file: %v", err)
+	}
+	err = snap.Write(outFile)
+	if err != nil {
+		outFile.Close()
+		log.Fatalf("Unable to write snapshot: %v", err)
+	}
+	// The last of the data may only reach the disk on close.
+	err = outFile.Close()
+	if err != nil {
+		log.Fatalf("Unable to write snapshot: %v", err)
+	}
+}
+
+func sdeDiff(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		log.Fatalf("Error: You must specify the old and new SDE files.")
+	}
+	oldDb := openSDE(args[0])
+	defer oldDb.Close()
+	newDb := openSDE(args[1])
+	defer newDb.Close()
+	report, err := sdediff.Compare(oldDb, newDb)
+	if err != nil {
+		log.Fatalf("Unable to compare SDEs: %v", err)
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("Unable to encode report: %v", err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	if report.Empty() {
+		fmt.Println("No differences found.")
+		return
+	}
+	err = report.WriteText(os.Stdout)
+	if err != nil {
+		log.Fatalf("Unable to write report: %v", err)
+	}
+}
+
+func itemSearch(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		log.Fatalf("Error: You must specify the item to search for.")
+	}
+	query := strings.Join(args, " ")
+	sde := getSDE()
+	defer sde.Close()
+	matches, err := sde.SearchItems(query, viper.GetInt("limit"))
+	if err == sql.ErrNoRows {
+		fmt.Printf("No items match %q.\n", query)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Unable to search items: %v", err)
+	}
+	for _, m := range matches {
+		fmt.Printf("%-50s %8d  %s\n", m.Item.Name, m.Item.ID, m.Kind)
+	}
+}
+
+func industryPlan(cmd *cobra.Command, args []string) {
+	planFile := viper.GetString("plan")
+	if planFile == "" {
+		log.Fatalf("Error: You must specify the plan request file.")
+	}
+	reqBytes, err := ioutil.ReadFile(planFile)
+	if err != nil {
+		log.Fatalf("Unable to read plan request: %v", err)
+	}
+	var req industry.PlanRequest
+	err = json.Unmarshal(reqBytes, &req)
+	if err != nil {
+		log.Fatalf("Unable to parse plan request: %v", err)
+	}
+	schedule, err := industry.PlanJobs(&req)
+	if err != nil {
+		log.Fatalf("Unable to schedule jobs: %v", err)
+	}
+	if viper.GetBool("json") {
+		out, err := json.MarshalIndent(schedule, "", "  ")
+		if err != nil {
+			log.Fatalf("Unable to marshal schedule: %v", err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	tmpl, err := template.New("plan").Parse(planTmpl)
+	if err != nil {
+		log.Fatalf("Unable to parse template: %v", err)
+	}
+	err = tmpl.Execute(os.Stdout, schedule)
+	if err != nil {
+		log.Fatalf("Unable to execute template: %v", err)
+	}
+}
+
 func main() {
 	rootCmd.PersistentFlags().Int("keyid", 0, "The key ID to use for accessing the account.")
 	rootCmd.PersistentFlags().String("vcode", "", "The API key's verification code.")
@@ -254,6 +445,28 @@ func main() {
 		viper.BindPFlag(fname, charCmd.PersistentFlags().Lookup(fname))
 	}
 
+	rootCmd.AddCommand(itemCmd)
+	itemCmd.AddCommand(itemSearchCmd)
+	itemSearchCmd.Flags().Int("limit", 20, "The maximum number of results to return (0 for all).")
+	viper.BindPFlag("limit", itemSearchCmd.Flags().Lookup("limit"))
+
+	rootCmd.AddCommand(sdeCmd)
+	sdeCmd.AddCommand(sdeSnapshotCmd)
+	sdeSnapshotCmd.Flags().String("out", "", "The file to write the snapshot to.")
+	viper.BindPFlag("out", sdeSnapshotCmd.Flags().Lookup("out"))
+	sdeCmd.AddCommand(sdeDiffCmd)
+	// Not bound to viper, which already uses "json" for industry plan.
+	sdeDiffCmd.Flags().Bool("json", false, "Output the differences as JSON.")
+
+	rootCmd.AddCommand(industryCmd)
+	industryCmd.AddCommand(industryPlanCmd)
+	industryPlanCmd.Flags().String("plan", "", "A JSON file containing the jobs, characters, and facilities to plan for.")
+	industryPlanCmd.Flags().Bool("json", false, "Output the schedule as JSON.")
+	flagNames = []string{"plan", "json"}
+	for _, fname := range flagNames {
+		viper.BindPFlag(fname, industryPlanCmd.Flags().Lookup(fname))
+	}
+
 	viper.SetEnvPrefix("EVE")
 	viper.AutomaticEnv()
 
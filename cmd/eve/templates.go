@@ -17,17 +17,6 @@ limitations under the License.
 
 package main
 
-import "fmt"
-
-var numMap = [...]string{"0", "I", "II", "III", "IV", "V"}
-
-func romanNumerals(n int) string {
-	if n < 0 || n >= len(numMap) {
-		return fmt.Sprintf("(out of range: %d)", n)
-	}
-	return numMap[n]
-}
-
 var (
 	charsheetTmpl = `
 {{.Name}} ({{.ID}})
@@ -36,5 +25,11 @@ Alliance:    {{.Alliance}} ({{.AllianceID}})
 Skills:{{with .Skills}}{{range $i, $skillGroup := .}}{{range $j, $sk := .}}
 {{if eq $j 0 }}  {{$sk.Group}}: ({{len $skillGroup}} skills)
 {{end}}    {{$sk.Name}} {{roman $sk.Level}} ({{$sk.NumSkillpoints}} pts.){{end}}{{end}}{{end}}
+`
+	planTmpl = `{{range .Jobs}}
+{{.Start.Format "2006-01-02 15:04"}} – {{.End.Format "2006-01-02 15:04"}}  {{.Job.ID}}: {{.Job.Activity}} {{.Job.Product}} x{{.Job.Runs}}
+    {{.Character}} ({{.CharacterID}}) at {{.Facility}}{{end}}
+
+All jobs complete at {{.Finish.Format "2006-01-02 15:04"}}.
 `
 )
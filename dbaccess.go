@@ -18,30 +18,146 @@ limitations under the License.
 package evego
 
 import (
+	"context"
 	"io"
 )
 
 // Database is an object that returns information about items in EVE.
+//
+// Each method has a variant with the suffix Context that takes a
+// context.Context as its first argument; a canceled context aborts the
+// underlying query and returns the context's error. The methods without the
+// suffix use context.Background().
 type Database interface {
 	io.Closer
 
 	// Items
 
 	ItemForName(itemName string) (*Item, error)
+	ItemForNameContext(ctx context.Context, itemName string) (*Item, error)
 	ItemForID(itemID int) (*Item, error)
+	ItemForIDContext(ctx context.Context, itemID int) (*Item, error)
 	ItemsForIDs(itemIDs []int) ([]*Item, error)
+	ItemsForIDsContext(ctx context.Context, itemIDs []int) ([]*Item, error)
 	ItemComposition(itemID int) ([]InventoryLine, error)
+	ItemCompositionContext(ctx context.Context, itemID int) ([]InventoryLine, error)
+
+	// ItemsComposition returns the reprocessing materials of several item
+	// types, keyed by type ID. Types that have no materials are left out.
+	ItemsComposition(itemIDs []int) (map[int][]InventoryLine, error)
+	ItemsCompositionContext(ctx context.Context, itemIDs []int) (map[int][]InventoryLine, error)
+
 	MarketGroupForItem(item *Item) (*MarketGroup, error)
+	MarketGroupForItemContext(ctx context.Context, item *Item) (*MarketGroup, error)
+
+	// MarketGroupChildren returns the subgroups of a market group, sorted by
+	// name; their Parent is not set. Pass a group ID of 0 to get the top-level
+	// groups.
+	MarketGroupChildren(groupID int) ([]MarketGroup, error)
+	MarketGroupChildrenContext(ctx context.Context, groupID int) ([]MarketGroup, error)
+
+	// ItemsInMarketGroup returns the items in a market group, sorted by name.
+	// If recursive is true, the items in all of its subgroups are included.
+	ItemsInMarketGroup(groupID int, recursive bool) ([]*Item, error)
+	ItemsInMarketGroupContext(ctx context.Context, groupID int, recursive bool) ([]*Item, error)
+
+	// MarketTree returns the entire market hierarchy as a list of the
+	// top-level groups, sorted by name.
+	MarketTree() ([]*MarketGroupNode, error)
+	MarketTreeContext(ctx context.Context) ([]*MarketGroupNode, error)
+
+	// ItemIDs returns the type IDs of every item in the database, in
+	// ascending order.
+	ItemIDs() ([]int, error)
+	ItemIDsContext(ctx context.Context) ([]int, error)
+
+	// SearchItems returns the items whose names match the query, ignoring
+	// case: exact matches first, then names beginning with the query, names
+	// containing it, and names within a small edit distance of it. Within each
+	// kind of match, published items and those on the market come first. At
+	// most limit results are returned (all of them if limit is 0).
+	SearchItems(query string, limit int) ([]ItemMatch, error)
+	SearchItemsContext(ctx context.Context, query string, limit int) ([]ItemMatch, error)
 
 	// Universe locations
 
 	SolarSystemForID(systemID int) (*SolarSystem, error)
+	SolarSystemForIDContext(ctx context.Context, systemID int) (*SolarSystem, error)
 	SolarSystemForName(systemName string) (*SolarSystem, error)
+	SolarSystemForNameContext(ctx context.Context, systemName string) (*SolarSystem, error)
 	SolarSystemsForPattern(systemName string) ([]SolarSystem, error)
+	SolarSystemsForPatternContext(ctx context.Context, systemName string) ([]SolarSystem, error)
 
 	RegionForName(regionName string) (*Region, error)
+	RegionForNameContext(ctx context.Context, regionName string) (*Region, error)
 	StationForID(stationID int) (*Station, error)
+	StationForIDContext(ctx context.Context, stationID int) (*Station, error)
 	StationsForName(stationName string) ([]Station, error)
+	StationsForNameContext(ctx context.Context, stationName string) ([]Station, error)
+
+	// Universe topology
+
+	// ConstellationForID returns the constellation with the given ID.
+	ConstellationForID(constellationID int) (*Constellation, error)
+	ConstellationForIDContext(ctx context.Context, constellationID int) (*Constellation, error)
+	// ConstellationsForRegion returns a region's constellations, sorted by name.
+	ConstellationsForRegion(regionID int) ([]Constellation, error)
+	ConstellationsForRegionContext(ctx context.Context, regionID int) ([]Constellation, error)
+	// SolarSystemsForConstellation and SolarSystemsForRegion return the solar
+	// systems in a constellation or region, sorted by name.
+	SolarSystemsForConstellation(constellationID int) ([]SolarSystem, error)
+	SolarSystemsForConstellationContext(ctx context.Context, constellationID int) ([]SolarSystem, error)
+	SolarSystemsForRegion(regionID int) ([]SolarSystem, error)
+	SolarSystemsForRegionContext(ctx context.Context, regionID int) ([]SolarSystem, error)
+	// SolarSystemNeighbors returns the solar systems one stargate jump away
+	// from the given system, sorted by name.
+	SolarSystemNeighbors(systemID int) ([]SolarSystem, error)
+	SolarSystemNeighborsContext(ctx context.Context, systemID int) ([]SolarSystem, error)
+
+	// StargatesForSystem, PlanetsForSystem, MoonsForSystem, and
+	// AsteroidBeltsForSystem return the objects of each kind in a solar
+	// system, in the order in which they appear in the system.
+	StargatesForSystem(systemID int) ([]Stargate, error)
+	StargatesForSystemContext(ctx context.Context, systemID int) ([]Stargate, error)
+	PlanetsForSystem(systemID int) ([]Planet, error)
+	PlanetsForSystemContext(ctx context.Context, systemID int) ([]Planet, error)
+	MoonsForSystem(systemID int) ([]Moon, error)
+	MoonsForSystemContext(ctx context.Context, systemID int) ([]Moon, error)
+	AsteroidBeltsForSystem(systemID int) ([]AsteroidBelt, error)
+	AsteroidBeltsForSystemContext(ctx context.Context, systemID int) ([]AsteroidBelt, error)
+	// StargatesForRegion, PlanetsForRegion, MoonsForRegion, and
+	// AsteroidBeltsForRegion return the objects of each kind in every solar
+	// system of a region, in the same order as the ForSystem methods.
+	StargatesForRegion(regionID int) ([]Stargate, error)
+	StargatesForRegionContext(ctx context.Context, regionID int) ([]Stargate, error)
+	PlanetsForRegion(regionID int) ([]Planet, error)
+	PlanetsForRegionContext(ctx context.Context, regionID int) ([]Planet, error)
+	MoonsForRegion(regionID int) ([]Moon, error)
+	MoonsForRegionContext(ctx context.Context, regionID int) ([]Moon, error)
+	AsteroidBeltsForRegion(regionID int) ([]AsteroidBelt, error)
+	AsteroidBeltsForRegionContext(ctx context.Context, regionID int) ([]AsteroidBelt, error)
+
+	// NPC factions, corporations, and agents
+
+	FactionForID(factionID int) (*Faction, error)
+	FactionForIDContext(ctx context.Context, factionID int) (*Faction, error)
+	// Factions returns every faction, sorted by name.
+	Factions() ([]Faction, error)
+	FactionsContext(ctx context.Context) ([]Faction, error)
+	NPCCorporationForID(corporationID int) (*NPCCorp, error)
+	NPCCorporationForIDContext(ctx context.Context, corporationID int) (*NPCCorp, error)
+	// NPCCorporationsForFaction returns a faction's corporations, sorted by
+	// name.
+	NPCCorporationsForFaction(factionID int) ([]NPCCorp, error)
+	NPCCorporationsForFactionContext(ctx context.Context, factionID int) ([]NPCCorp, error)
+	AgentForID(agentID int) (*Agent, error)
+	AgentForIDContext(ctx context.Context, agentID int) (*Agent, error)
+	// AgentsForCorporation and AgentsForStation return the agents working for
+	// a corporation or found in a station, sorted by name.
+	AgentsForCorporation(corporationID int) ([]Agent, error)
+	AgentsForCorporationContext(ctx context.Context, corporationID int) ([]Agent, error)
+	AgentsForStation(stationID int) ([]Agent, error)
+	AgentsForStationContext(ctx context.Context, stationID int) ([]Agent, error)
 
 	// Blueprints, invention, and manufacturing
 
@@ -50,21 +166,84 @@ type Database interface {
 	// (typeName) as a string. The type name may include the percent (%) character
 	// as a wildcard.
 	BlueprintOutputs(typeName string) ([]IndustryActivity, error)
+	BlueprintOutputsContext(ctx context.Context, typeName string) ([]IndustryActivity, error)
 
 	// BlueprintForProduct returns the blueprints that can produce a given output.
 	BlueprintForProduct(typeName string) ([]IndustryActivity, error)
+	BlueprintForProductContext(ctx context.Context, typeName string) ([]IndustryActivity, error)
 
 	// BlueprintsUsingMaterial returns the blueprints that use the given input material
 	// in an industrial process (manufacturing, invention, etc.)
 	BlueprintsUsingMaterial(typeName string) ([]IndustryActivity, error)
+	BlueprintsUsingMaterialContext(ctx context.Context, typeName string) ([]IndustryActivity, error)
 
 	// BlueprintProductionInputs returns the required materials for one run
 	// of production on an unresearched (ME 0% / TE 0%) blueprint. It takes as
 	// parameters the blueprint to be used and the selected output product.
 	BlueprintProductionInputs(
 		typeName string, outputTypeName string) ([]InventoryLine, error)
+	BlueprintProductionInputsContext(ctx context.Context,
+		typeName string, outputTypeName string) ([]InventoryLine, error)
+
+	// BlueprintsInputs returns the required materials for one run of each
+	// activity of several unresearched blueprints, keyed by blueprint and
+	// activity. Activities without any materials are left out.
+	BlueprintsInputs(typeIDs []int) (map[BlueprintActivity][]InventoryLine, error)
+	BlueprintsInputsContext(ctx context.Context, typeIDs []int) (map[BlueprintActivity][]InventoryLine, error)
+
+	// ReactionFormulas returns the inputs, outputs, and run time of the reaction
+	// formulas with the given name. The type name may include the percent (%)
+	// character as a wildcard.
+	ReactionFormulas(typeName string) ([]Reaction, error)
+	ReactionFormulasContext(ctx context.Context, typeName string) ([]Reaction, error)
+
+	// ReactionsForProduct returns the reaction formulas that produce a given
+	// output. The type name may include the percent (%) character as a wildcard.
+	ReactionsForProduct(typeName string) ([]Reaction, error)
+	ReactionsForProductContext(ctx context.Context, typeName string) ([]Reaction, error)
+
+	// Dogma attributes and effects
+
+	// AttributeForID returns the definition of the dogma attribute with the
+	// given ID.
+	AttributeForID(attributeID int) (*DogmaAttribute, error)
+	AttributeForIDContext(ctx context.Context, attributeID int) (*DogmaAttribute, error)
+
+	// AttributeForName returns the definition of the dogma attribute with the
+	// given name (e.g. "cpu", "metaLevel").
+	AttributeForName(attributeName string) (*DogmaAttribute, error)
+	AttributeForNameContext(ctx context.Context, attributeName string) (*DogmaAttribute, error)
+
+	// ItemAttributes returns the dogma attribute values of an item type.
+	ItemAttributes(typeID int) ([]ItemAttribute, error)
+	ItemAttributesContext(ctx context.Context, typeID int) ([]ItemAttribute, error)
+
+	// ItemsAttributes returns the dogma attribute values of several item types,
+	// keyed by type ID.
+	ItemsAttributes(typeIDs []int) (map[int][]ItemAttribute, error)
+	ItemsAttributesContext(ctx context.Context, typeIDs []int) (map[int][]ItemAttribute, error)
+
+	// ItemAttributeValue returns the value of one of an item type's attributes,
+	// or sql.ErrNoRows if the type doesn't have that attribute.
+	ItemAttributeValue(typeID, attributeID int) (float64, error)
+	ItemAttributeValueContext(ctx context.Context, typeID, attributeID int) (float64, error)
+
+	// ItemAttributeValueForName is ItemAttributeValue with the attribute
+	// specified by name.
+	ItemAttributeValueForName(typeID int, attributeName string) (float64, error)
+	ItemAttributeValueForNameContext(ctx context.Context, typeID int, attributeName string) (float64, error)
+
+	// ItemEffects returns the dogma effects of an item type.
+	ItemEffects(typeID int) ([]ItemEffect, error)
+	ItemEffectsContext(ctx context.Context, typeID int) ([]ItemEffect, error)
+
+	// ItemsEffects returns the dogma effects of several item types, keyed by
+	// type ID.
+	ItemsEffects(typeIDs []int) (map[int][]ItemEffect, error)
+	ItemsEffectsContext(ctx context.Context, typeIDs []int) (map[int][]ItemEffect, error)
 
 	// ReprocessOutputMaterials produces a list of all materials that are possible
 	// outputs from reprocessing.
 	ReprocessOutputMaterials() ([]Item, error)
+	ReprocessOutputMaterialsContext(ctx context.Context) ([]Item, error)
 }
@@ -17,7 +17,10 @@ limitations under the License.
 
 package evego
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
 // XMLKey is a key ID / verification code pair used to retrieve data from the
 // EVE XML API.
@@ -28,35 +31,63 @@ type XMLKey struct {
 	Description string
 }
 
+// CharacterResolver looks up characters by name.
+type CharacterResolver interface {
+	io.Closer
+
+	// CharactersForNames returns the characters with the provided names,
+	// including their current corporations and alliances, in the order that
+	// they were given. Names that aren't those of characters are skipped.
+	CharactersForNames(names []string) ([]Character, error)
+	CharactersForNamesContext(ctx context.Context, names []string) ([]Character, error)
+}
+
 // XMLAPI is an interface to the EVE XML API. We could make the interface
 // sufficiently abstract to cover multiple APIs, but that seems on the silly
 // side.
+//
+// Each method that calls the API has a Context variant that cancels the HTTP
+// request along with its context.
 type XMLAPI interface {
 	io.Closer
 
 	// OutpostForID returns a conquerable station with the provided ID.
 	OutpostForID(id int) (*Station, error)
+	OutpostForIDContext(ctx context.Context, id int) (*Station, error)
 
 	// OutpostsForName returns the stations matching the provided name pattern.
 	// The percent character (%) may be used as a wildcard.
 	OutpostsForName(name string) ([]Station, error)
+	OutpostsForNameContext(ctx context.Context, name string) ([]Station, error)
 
-	// DumpOutposts returns the current list of outposts.
+	// DumpOutposts returns the current list of outposts. DumpOutposts
+	// returns the last list retrieved if it can't be refreshed;
+	// DumpOutpostsContext also returns the error.
 	DumpOutposts() []*Station
+	DumpOutpostsContext(ctx context.Context) ([]*Station, error)
 
 	// AccountCharacters returns a list of characters that the provided key can
 	// access.
 	AccountCharacters(key *XMLKey) ([]Character, error)
+	AccountCharactersContext(ctx context.Context, key *XMLKey) ([]Character, error)
 
 	// CharacterSheet returns the character sheet for the given character ID.
 	CharacterSheet(key *XMLKey, characterID int) (*CharacterSheet, error)
+	CharacterSheetContext(ctx context.Context, key *XMLKey, characterID int) (*CharacterSheet, error)
 
 	// CharacterStandings returns a character's standings.
 	CharacterStandings(key *XMLKey, characterID int) ([]Standing, error)
+	CharacterStandingsContext(ctx context.Context, key *XMLKey, characterID int) ([]Standing, error)
 
 	// Assets gets a character's assets.
 	Assets(key *XMLKey, characterID int) ([]InventoryItem, error)
+	AssetsContext(ctx context.Context, key *XMLKey, characterID int) ([]InventoryItem, error)
 
 	// Blueprints gets a character's blueprints.
 	Blueprints(key *XMLKey, characterID int, assets []InventoryItem) ([]BlueprintItem, error)
+	BlueprintsContext(ctx context.Context, key *XMLKey, characterID int, assets []InventoryItem) ([]BlueprintItem, error)
+
+	// CharactersForNames looks up characters by name; see CharacterResolver.
+	CharactersForNames(names []string) ([]Character, error)
+	CharactersForNamesContext(ctx context.Context, names []string) ([]Character, error)
 }
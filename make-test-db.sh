@@ -23,14 +23,26 @@ TESTDB=./testdb.sqlite
 
 # Dump schema for tables we use
 sqlite3 $DBLOC > out-schema.sql <<EOF
+.schema agtAgents
+.schema chrFactions
+.schema crpNPCCorporations
+.schema crpNPCDivisions
+.schema dgmAttributeTypes
+.schema dgmEffects
+.schema dgmTypeAttributes
+.schema dgmTypeEffects
+.schema industryActivity
 .schema industryActivityMaterials
 .schema industryActivityProducts
 .schema invTypes
 .schema invTypeMaterials
+.schema invVolumes
 .schema invMarketGroups
 .schema invCategories
 .schema invGroups
 .schema invNames
+.schema mapDenormalize
+.schema mapJumps
 .schema mapSolarSystems
 .schema mapSolarSystemJumps
 .schema mapConstellations
@@ -68,9 +80,11 @@ ITEMS=$(cat<<EOF
 "Small I-ax Enduring Remote Armor Repairer",
 "Shielded Radar Backup Cluster I",
 "Medium Shield Extender II",
+"EMP S",
 "EMP M",
 "Vexor Blueprint",
 "Vexor",
+"Ishtar",
 "Ishtar Blueprint",
 "Datacore - Gallentean Starship Engineering",
 "Datacore - Mechanical Engineering",
@@ -79,6 +93,12 @@ ITEMS=$(cat<<EOF
 "Small Hybrid Turret",
 "Spaceship Command",
 "Gallente Frigate",
+"Gallente Cruiser",
+"Drones",
+"Light Drone Operation",
+"Gallente Drone Specialization",
+"Memory Augmentation - Standard",
+"Ocular Filter - Standard",
 "Mining",
 "Mechanics",
 "Science",
@@ -92,7 +112,15 @@ ITEMS=$(cat<<EOF
 "Tritanium",
 "Medium Ancillary Armor Repairer Blueprint",
 "Station Container",
-"Station Warehouse Container Blueprint"
+"Station Warehouse Container Blueprint",
+"Carbon Polymers Reaction Formula",
+"Carbon Polymers",
+"Hydrocarbons",
+"Silicates",
+"Helium Fuel Block",
+"Antimatter Charge M",
+"Hobgoblin II",
+"Medium Hybrid Burst Aerator I"
 )
 EOF
 )
@@ -214,6 +242,16 @@ WHERE typeID IN bpMats
 OR    typeID IN items
 OR    materialTypeID IN bpMats;
 
+.mode insert invVolumes
+${BPMATWITH}
+SELECT * FROM invVolumes
+WHERE typeID IN (
+  SELECT typeID from invTypes
+  WHERE typeName IN ${ITEMS}
+  OR    typeID IN matTypes
+  OR    typeID IN bpTypes
+);
+
 .mode insert invGroups
 ${BPMATWITH}
 SELECT * FROM invGroups
@@ -293,25 +331,134 @@ WHERE regionID IN (
   WHERE solarSystemName IN $SYSTEMS
 ) OR regionName IN $REGIONS;
 
+-- Planets, moons, belts, and stargates in our systems, and the stargates
+-- at the other end of their jumps
+.mode insert mapDenormalize
+WITH gates AS (
+  SELECT d.itemID
+  FROM   mapDenormalize d
+  JOIN   mapSolarSystems s USING(solarSystemID)
+  WHERE  s.solarSystemName IN $SYSTEMS
+  AND    d.groupID = 10
+)
+SELECT *
+FROM   mapDenormalize
+WHERE  (groupID IN (7, 8, 9, 10) AND solarSystemID IN (
+  SELECT solarSystemID
+  FROM   mapSolarSystems
+  WHERE  solarSystemName IN $SYSTEMS
+))
+OR     itemID IN (
+  SELECT destinationID FROM mapJumps WHERE stargateID IN gates
+);
+
+.mode insert mapJumps
+SELECT *
+FROM   mapJumps
+WHERE  stargateID IN (
+  SELECT d.itemID
+  FROM   mapDenormalize d
+  JOIN   mapSolarSystems s USING(solarSystemID)
+  WHERE  s.solarSystemName IN $SYSTEMS
+  AND    d.groupID = 10
+);
+
+-- The types of the planets in our systems
+.mode insert invTypes
+SELECT *
+FROM   invTypes
+WHERE  typeID IN (
+  SELECT DISTINCT d.typeID
+  FROM   mapDenormalize d
+  JOIN   mapSolarSystems s USING(solarSystemID)
+  WHERE  s.solarSystemName IN $SYSTEMS
+  AND    d.groupID = 7
+);
+
 .mode insert staStations
 SELECT *
 FROM   staStations
 WHERE  stationName IN $STATIONS;
 
-.mode insert invNames
+-- NPC factions, corporations, and the agents of our stations' owners
+.mode insert chrFactions
 SELECT *
-FROM   invNames
-WHERE  itemID IN (
+FROM   chrFactions;
+
+.mode insert crpNPCCorporations
+SELECT *
+FROM   crpNPCCorporations;
+
+.mode insert crpNPCDivisions
+SELECT *
+FROM   crpNPCDivisions;
+
+.mode insert agtAgents
+SELECT *
+FROM   agtAgents
+WHERE  corporationID IN (
   SELECT DISTINCT corporationID
   FROM   staStations
   WHERE  stationName IN $STATIONS
 );
 
+.mode insert invNames
+SELECT *
+FROM   invNames
+WHERE  itemID IN (
+  SELECT corporationID
+  FROM   crpNPCCorporations
+) OR   itemID IN (
+  SELECT agentID
+  FROM   agtAgents
+  WHERE  corporationID IN (
+    SELECT DISTINCT corporationID
+    FROM   staStations
+    WHERE  stationName IN $STATIONS
+  )
+);
+
+-- Dogma tables
+.mode insert dgmAttributeTypes
+SELECT *
+FROM   dgmAttributeTypes;
+
+.mode insert dgmEffects
+SELECT *
+FROM   dgmEffects;
+
+.mode insert dgmTypeAttributes
+SELECT *
+FROM   dgmTypeAttributes
+WHERE  typeID IN (
+  SELECT typeID from invTypes
+  WHERE typeName IN ${ITEMS}
+);
+
+.mode insert dgmTypeEffects
+SELECT *
+FROM   dgmTypeEffects
+WHERE  typeID IN (
+  SELECT typeID from invTypes
+  WHERE typeName IN ${ITEMS}
+);
+
 -- Industry tables
 .mode insert ramActivities
 SELECT *
 FROM   ramActivities;
 
+.mode insert industryActivity
+${BPMATWITH}
+, types AS (
+  SELECT typeID from invTypes
+  WHERE typeName IN ${ITEMS}
+)
+SELECT *
+FROM   industryActivity
+WHERE  typeID IN types
+OR     typeID IN bpTypes;
+
 .mode insert industryActivityMaterials
 ${BPMATWITH}
 , types AS (
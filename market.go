@@ -18,10 +18,12 @@ limitations under the License.
 package evego
 
 import (
+	"context"
 	"io"
 )
 
-// Market returns information about market orders.
+// Market returns information about market orders. The Context variants of its
+// methods abort the request to the market service when the context is done.
 type Market interface {
 	io.Closer
 
@@ -29,12 +31,15 @@ type Market interface {
 	// location is the name of either a system or a region.
 	// type can be Buy, Sell, or All.
 	OrdersForItem(itemID *Item, location string, orderType OrderType) (*[]Order, error)
+	OrdersForItemContext(ctx context.Context, itemID *Item, location string, orderType OrderType) (*[]Order, error)
 
 	// BuyInStation returns the buy orders that are in range of the given
 	// station (i.e., can be sold to by a user there).
 	BuyInStation(itemID *Item, location *Station) (*[]Order, error)
+	BuyInStationContext(ctx context.Context, itemID *Item, location *Station) (*[]Order, error)
 
 	// OrdersInStation returns the buy orders that are in range of a given station,
 	// and the sell orders available at that station.
 	OrdersInStation(item *Item, location *Station) (*[]Order, error)
+	OrdersInStationContext(ctx context.Context, item *Item, location *Station) (*[]Order, error)
 }
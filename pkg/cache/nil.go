@@ -18,6 +18,7 @@ limitations under the License.
 package cache
 
 import (
+	"context"
 	"time"
 
 	"github.com/backerman/evego"
@@ -42,6 +43,14 @@ func (c *nilCache) Get(key string) ([]byte, bool) {
 	return nil, false
 }
 
+func (c *nilCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	return nil, false
+}
+
 func (c *nilCache) Put(key string, val []byte, expires time.Time) error {
 	return nil
 }
+
+func (c *nilCache) PutContext(ctx context.Context, key string, val []byte, expires time.Time) error {
+	return nil
+}
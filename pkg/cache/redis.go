@@ -20,8 +20,12 @@ package cache
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
+	"errors"
 	"io/ioutil"
 	"log"
+	"net"
+	"os"
 	"time"
 
 	"github.com/backerman/evego"
@@ -68,31 +72,63 @@ func (c *redisCache) Close() error {
 }
 
 func (c *redisCache) Get(key string) ([]byte, bool) {
+	return c.GetContext(context.Background(), key)
+}
+
+func (c *redisCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	if ctx.Err() != nil {
+		return nil, false
+	}
 	conn := c.pool.Get()
 	defer conn.Close()
-	cachedBytes, err := redis.Bytes(conn.Do("GET", key))
+	cachedBytes, err := redis.Bytes(do(ctx, conn, "GET", key))
 	if err == nil {
+		// Corrupt cached data is treated as a miss, so that it gets replaced.
 		r, err := zlib.NewReader(bytes.NewReader(cachedBytes))
 		if err != nil {
-			log.Fatalf("Unable to decompress cached data: %v", err)
+			log.Printf("Unable to decompress cached data: %v", err)
 			return nil, false
 		}
+		defer r.Close()
 		gotten, err := ioutil.ReadAll(r)
 		if err != nil {
-			log.Fatalf("Unable to decompress cached data: %v", err)
+			log.Printf("Unable to decompress cached data: %v", err)
 			return nil, false
 		}
-		r.Close()
 		return gotten, true
-	} else if err == redis.ErrNil {
+	} else if err == redis.ErrNil || timedOut(ctx, err) {
 		return nil, false
 	}
-	// Some error other than not having found the cached response.
-	log.Fatalf("Unable to access cache: %v", err)
+	// Some error other than not having found the cached response. The cache
+	// is only an optimization, so carry on as if the key wasn't there.
+	log.Printf("Unable to access cache: %v", err)
 	return nil, false
 }
 
+// timedOut returns true if a Redis command failed because the context's
+// deadline passed or the context was canceled. The socket's deadline is the
+// same as the context's, so the socket may time out before the context
+// notices.
+func timedOut(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if _, hasDeadline := ctx.Deadline(); hasDeadline && errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
 func (c *redisCache) Put(key string, val []byte, expires time.Time) error {
+	return c.PutContext(context.Background(), key, val, expires)
+}
+
+func (c *redisCache) PutContext(ctx context.Context, key string, val []byte, expires time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	conn := c.pool.Get()
 	defer conn.Close()
 
@@ -101,6 +137,21 @@ func (c *redisCache) Put(key string, val []byte, expires time.Time) error {
 	w.Write(val)
 	w.Close()
 	expiresSeconds := expires.Sub(time.Now()).Seconds()
-	_, err := conn.Do("SET", key, b.Bytes(), "EX", int(expiresSeconds))
+	_, err := do(ctx, conn, "SET", key, b.Bytes(), "EX", int(expiresSeconds))
 	return err
 }
+
+// do runs a Redis command, giving up when the context's deadline passes. The
+// client can't be interrupted otherwise, so a context that's canceled without
+// a deadline only takes effect between commands.
+func do(ctx context.Context, conn redis.Conn, cmd string, args ...interface{}) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return conn.Do(cmd, args...)
+	}
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return nil, context.DeadlineExceeded
+	}
+	return redis.DoWithTimeout(conn, timeout, cmd, args...)
+}
@@ -20,6 +20,7 @@ package cache_test
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
 	"io/ioutil"
 	"strconv"
 	"testing"
@@ -104,3 +105,24 @@ func TestRedisCache(t *testing.T) {
 		})
 	})
 }
+
+func TestRedisCacheUnavailable(t *testing.T) {
+	Convey("Given a Redis server that can't be reached", t, func() {
+		// Nothing listens on the TCP port multiplexer's port.
+		testCache := cache.RedisCache("127.0.0.1:1")
+		defer testCache.Close()
+
+		Convey("Getting a thing is a cache miss.", func() {
+			_, found := testCache.Get("Anything")
+			So(found, ShouldBeFalse)
+		})
+
+		Convey("A timed-out get is a cache miss.", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			defer cancel()
+			time.Sleep(2 * time.Millisecond)
+			_, found := testCache.GetContext(ctx, "Anything")
+			So(found, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,221 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package character
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/backerman/evego"
+)
+
+// requiredSkillAttributes pairs the dogma attributes that name the skills an
+// item requires with those that hold the required level.
+var requiredSkillAttributes = [][2]int{
+	{evego.AttributeRequiredSkill1, evego.AttributeRequiredSkill1Level},
+	{evego.AttributeRequiredSkill2, evego.AttributeRequiredSkill2Level},
+	{evego.AttributeRequiredSkill3, evego.AttributeRequiredSkill3Level},
+	{evego.AttributeRequiredSkill4, evego.AttributeRequiredSkill4Level},
+	{evego.AttributeRequiredSkill5, evego.AttributeRequiredSkill5Level},
+	{evego.AttributeRequiredSkill6, evego.AttributeRequiredSkill6Level},
+}
+
+// SkillRequirement is a skill level that's required in order to use an item,
+// along with the skill levels that it requires in turn.
+type SkillRequirement struct {
+	Skill         *evego.Item
+	Level         int
+	Prerequisites []SkillRequirement
+}
+
+// directRequirements returns the skills that an item requires, without their
+// prerequisites.
+func directRequirements(db evego.Database, item *evego.Item) ([]SkillRequirement, error) {
+	attrs, err := db.ItemAttributes(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[int]int, len(attrs))
+	for _, a := range attrs {
+		values[a.AttributeID] = int(a.Value)
+	}
+	reqs := []SkillRequirement{}
+	for _, attr := range requiredSkillAttributes {
+		skillID, ok := values[attr[0]]
+		if !ok || skillID == 0 {
+			continue
+		}
+		skill, err := db.ItemForID(skillID)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, SkillRequirement{Skill: skill, Level: values[attr[1]]})
+	}
+	return reqs, nil
+}
+
+// RequiredSkills returns the skills that are required in order to use an
+// item, each with the full tree of its prerequisites.
+func RequiredSkills(db evego.Database, item *evego.Item) ([]SkillRequirement, error) {
+	return expandRequirements(db, item, make(map[int]bool))
+}
+
+// expandRequirements fills in the prerequisite tree of an item's required
+// skills. ancestors holds the skills above this item in the tree, so that a
+// cycle in the static data can't recurse forever.
+func expandRequirements(db evego.Database, item *evego.Item, ancestors map[int]bool) ([]SkillRequirement, error) {
+	if ancestors[item.ID] {
+		return nil, fmt.Errorf("%v requires itself", item.Name)
+	}
+	reqs, err := directRequirements(db, item)
+	if err != nil {
+		return nil, err
+	}
+	ancestors[item.ID] = true
+	defer delete(ancestors, item.ID)
+	for i := range reqs {
+		reqs[i].Prerequisites, err = expandRequirements(db, reqs[i].Skill, ancestors)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reqs, nil
+}
+
+// directRequirements returns the skills that an item requires, caching the
+// result.
+func (t *Trainer) directRequirements(item *evego.Item) ([]SkillRequirement, error) {
+	if reqs, ok := t.requirements[item.ID]; ok {
+		return reqs, nil
+	}
+	reqs, err := directRequirements(t.db, item)
+	if err != nil {
+		return nil, err
+	}
+	t.requirements[item.ID] = reqs
+	return reqs, nil
+}
+
+// planner builds a skill plan in which every level follows its
+// prerequisites.
+type planner struct {
+	t       *Trainer
+	plan    evego.SkillPlan
+	planned map[int]int
+}
+
+// add appends a skill level to the plan, preceded by any missing
+// prerequisites and lower levels. ancestors holds the skills whose
+// prerequisites are being added.
+func (p *planner) add(skill *evego.Item, level int, ancestors map[int]bool) error {
+	have := p.t.levels[skill.ID]
+	if planned := p.planned[skill.ID]; planned > have {
+		have = planned
+	}
+	if level <= have {
+		return nil
+	}
+	if level > MaxSkillLevel {
+		return fmt.Errorf("Invalid level %d for %v", level, skill.Name)
+	}
+	if ancestors[skill.ID] {
+		return fmt.Errorf("%v requires itself", skill.Name)
+	}
+	reqs, err := p.t.directRequirements(skill)
+	if err != nil {
+		return err
+	}
+	ancestors[skill.ID] = true
+	for _, r := range reqs {
+		if err := p.add(r.Skill, r.Level, ancestors); err != nil {
+			return err
+		}
+	}
+	delete(ancestors, skill.ID)
+	for l := have + 1; l <= level; l++ {
+		p.plan = append(p.plan, evego.PlannedSkill{Skill: skill, Level: l})
+	}
+	p.planned[skill.ID] = level
+	return nil
+}
+
+// CompletePlan returns the skill levels the character must train to complete
+// a plan, in an order in which they can be trained. Missing prerequisites and
+// lower levels are inserted before the levels that need them, and levels the
+// character has already trained, or that the plan repeats, are left out.
+func (t *Trainer) CompletePlan(plan evego.SkillPlan) (evego.SkillPlan, error) {
+	p := &planner{t: t, plan: evego.SkillPlan{}, planned: make(map[int]int)}
+	for _, ps := range plan {
+		if err := p.add(ps.Skill, ps.Level, make(map[int]bool)); err != nil {
+			return nil, err
+		}
+	}
+	return p.plan, nil
+}
+
+// SkillGap is the training that a character needs in order to use something.
+type SkillGap struct {
+	// Missing holds the skill levels to be trained, in training order.
+	Missing  evego.SkillPlan
+	Duration time.Duration
+}
+
+// CanUse returns true if the character needs no further training.
+func (g *SkillGap) CanUse() bool {
+	return len(g.Missing) == 0
+}
+
+// SkillGap returns the training the character needs in order to use all of
+// the given items.
+func (t *Trainer) SkillGap(items ...*evego.Item) (*SkillGap, error) {
+	required := evego.SkillPlan{}
+	for _, item := range items {
+		reqs, err := t.directRequirements(item)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reqs {
+			required = append(required, evego.PlannedSkill{Skill: r.Skill, Level: r.Level})
+		}
+	}
+	missing, err := t.CompletePlan(required)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := t.PlanDuration(missing)
+	if err != nil {
+		return nil, err
+	}
+	return &SkillGap{Missing: missing, Duration: duration}, nil
+}
+
+// FittingGap returns the training the character needs in order to fly a
+// fitting: its ship, modules, charges, and drones. Cargo isn't included.
+func (t *Trainer) FittingGap(fit *evego.Fitting) (*SkillGap, error) {
+	items := []*evego.Item{fit.Ship}
+	for _, m := range fit.Modules {
+		items = append(items, m.Item)
+		if m.Charge != nil {
+			items = append(items, m.Charge)
+		}
+	}
+	for _, d := range fit.Drones {
+		items = append(items, d.Item)
+	}
+	return t.SkillGap(items...)
+}
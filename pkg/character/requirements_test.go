@@ -0,0 +1,147 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package character_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/character"
+	"github.com/backerman/evego/pkg/dbaccess"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// planNames returns a plan's entries as "Skill level" strings.
+func planNames(plan evego.SkillPlan) []string {
+	names := make([]string, len(plan))
+	for i, p := range plan {
+		names[i] = fmt.Sprintf("%s %d", p.Skill.Name, p.Level)
+	}
+	return names
+}
+
+func TestSkillRequirements(t *testing.T) {
+	Convey("Given a character and some items", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		sheet := &evego.CharacterSheet{
+			Skills: []evego.Skill{
+				{TypeID: 3327, NumSkillpoints: 45255, Level: 4},
+				{TypeID: 3436, NumSkillpoints: 100000, Level: 4},
+			},
+			Attributes: evego.Attributes{
+				Intelligence: 17,
+				Memory:       23,
+				Charisma:     17,
+				Perception:   25,
+				Willpower:    17,
+			},
+			Implants: []int{10208, 10216},
+		}
+		trainer, err := character.NewTrainer(db, sheet)
+		So(err, ShouldBeNil)
+		item := func(name string) *evego.Item {
+			i, err := db.ItemForName(name)
+			So(err, ShouldBeNil)
+			return i
+		}
+		vexor := item("Vexor")
+
+		Convey("The full prerequisite tree is expanded.", func() {
+			reqs, err := character.RequiredSkills(db, vexor)
+			So(err, ShouldBeNil)
+			So(reqs, ShouldHaveLength, 1)
+			So(reqs[0].Skill.Name, ShouldEqual, "Gallente Cruiser")
+			So(reqs[0].Level, ShouldEqual, 1)
+			So(reqs[0].Prerequisites, ShouldHaveLength, 1)
+			frigate := reqs[0].Prerequisites[0]
+			So(frigate.Skill.Name, ShouldEqual, "Gallente Frigate")
+			So(frigate.Level, ShouldEqual, 3)
+			So(frigate.Prerequisites, ShouldHaveLength, 1)
+			So(frigate.Prerequisites[0].Skill.Name, ShouldEqual, "Spaceship Command")
+			So(frigate.Prerequisites[0].Level, ShouldEqual, 1)
+			So(frigate.Prerequisites[0].Prerequisites, ShouldBeEmpty)
+		})
+
+		Convey("Items without requirements require nothing.", func() {
+			reqs, err := character.RequiredSkills(db, item("Tritanium"))
+			So(err, ShouldBeNil)
+			So(reqs, ShouldBeEmpty)
+			gap, err := trainer.SkillGap(item("Tritanium"))
+			So(err, ShouldBeNil)
+			So(gap.CanUse(), ShouldBeTrue)
+			So(gap.Duration, ShouldEqual, 0)
+		})
+
+		Convey("The missing skills for a ship are listed in training order.", func() {
+			gap, err := trainer.SkillGap(vexor)
+			So(err, ShouldBeNil)
+			So(gap.CanUse(), ShouldBeFalse)
+			So(planNames(gap.Missing), ShouldResemble, []string{
+				"Gallente Frigate 1",
+				"Gallente Frigate 2",
+				"Gallente Frigate 3",
+				"Gallente Cruiser 1",
+			})
+			So(gap.Duration, ShouldEqual, 28357*time.Second)
+		})
+
+		Convey("The missing skills for a fitting include its drones.", func() {
+			fit := &evego.Fitting{
+				Ship:   vexor,
+				Drones: []evego.InventoryLine{{Item: item("Hobgoblin II"), Quantity: 5}},
+				Cargo:  []evego.InventoryLine{{Item: item("Ishtar"), Quantity: 1}},
+			}
+			gap, err := trainer.FittingGap(fit)
+			So(err, ShouldBeNil)
+			So(planNames(gap.Missing), ShouldResemble, []string{
+				"Gallente Frigate 1",
+				"Gallente Frigate 2",
+				"Gallente Frigate 3",
+				"Gallente Cruiser 1",
+				"Light Drone Operation 1",
+				"Light Drone Operation 2",
+				"Light Drone Operation 3",
+				"Light Drone Operation 4",
+				"Light Drone Operation 5",
+				"Drones 5",
+				"Gallente Drone Specialization 1",
+			})
+			So(gap.Duration, ShouldEqual, 648533*time.Second)
+		})
+
+		Convey("Plans are completed without repeating trained levels.", func() {
+			frigate := item("Gallente Frigate")
+			plan, err := trainer.CompletePlan(evego.SkillPlan{
+				{Skill: frigate, Level: 2},
+				{Skill: item("Spaceship Command"), Level: 3},
+				{Skill: frigate, Level: 1},
+			})
+			So(err, ShouldBeNil)
+			So(planNames(plan), ShouldResemble, []string{
+				"Gallente Frigate 1",
+				"Gallente Frigate 2",
+			})
+		})
+	})
+}
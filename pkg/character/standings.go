@@ -21,6 +21,8 @@ package character
 import (
 	"database/sql"
 	"math"
+
+	"github.com/backerman/evego"
 )
 
 // Stolen from https://gist.github.com/DavidVaini/10308388
@@ -62,3 +64,23 @@ func EffectiveStanding(rawCorp, rawFaction sql.NullFloat64, connections, diploma
 		return round(math.Max(effStandings[0], effStandings[1]), 2)
 	}
 }
+
+// CorporationStanding calculates a character's effective standing towards an
+// NPC corporation from the character's raw standings, taking into account the
+// standing towards the faction to which the corporation belongs.
+func CorporationStanding(db evego.Database, standings []evego.Standing, corporationID, connections, diplomacy int) (float64, error) {
+	corp, err := db.NPCCorporationForID(corporationID)
+	if err != nil {
+		return 0, err
+	}
+	var rawCorp, rawFaction sql.NullFloat64
+	for _, s := range standings {
+		switch {
+		case s.EntityType == evego.NPCCorporation && s.ID == corp.ID:
+			rawCorp = sql.NullFloat64{Valid: true, Float64: s.Standing}
+		case s.EntityType == evego.NPCFaction && s.ID == corp.FactionID && corp.FactionID != 0:
+			rawFaction = sql.NullFloat64{Valid: true, Float64: s.Standing}
+		}
+	}
+	return EffectiveStanding(rawCorp, rawFaction, connections, diplomacy), nil
+}
@@ -21,6 +21,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/backerman/evego"
 	"github.com/backerman/evego/pkg/character"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -59,3 +60,50 @@ func TestStandingsCalculation(t *testing.T) {
 	})
 
 }
+
+// corpDb is a database that knows about one NPC corporation.
+type corpDb struct {
+	evego.Database
+	corp evego.NPCCorp
+}
+
+func (db corpDb) NPCCorporationForID(corporationID int) (*evego.NPCCorp, error) {
+	if corporationID != db.corp.ID {
+		return nil, sql.ErrNoRows
+	}
+	corp := db.corp
+	return &corp, nil
+}
+
+func TestCorporationStanding(t *testing.T) {
+	Convey("Given an NPC corporation in a faction", t, func() {
+		db := corpDb{corp: evego.NPCCorp{
+			Name:      "Roden Shipyards",
+			ID:        1000102,
+			FactionID: 500004,
+			Faction:   "Gallente Federation",
+		}}
+		standings := []evego.Standing{
+			{EntityType: evego.NPCCorporation, ID: 1000102, Standing: 1.0},
+			{EntityType: evego.NPCFaction, ID: 500004, Standing: 3.0},
+			{EntityType: evego.NPCFaction, ID: 500001, Standing: 9.0},
+		}
+
+		Convey("The faction standing is used if it's higher.", func() {
+			standing, err := character.CorporationStanding(db, standings, 1000102, 3, 0)
+			So(err, ShouldBeNil)
+			So(standing, ShouldEqual, 3.84)
+		})
+
+		Convey("The corporation standing is used if there's no faction standing.", func() {
+			standing, err := character.CorporationStanding(db, standings[:1], 1000102, 0, 0)
+			So(err, ShouldBeNil)
+			So(standing, ShouldEqual, 1.0)
+		})
+
+		Convey("An unknown corporation returns an error.", func() {
+			_, err := character.CorporationStanding(db, standings, 1000130, 0, 0)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,255 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package character
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/backerman/evego"
+)
+
+// MaxSkillLevel is the highest level to which a skill can be trained.
+const MaxSkillLevel = 5
+
+// SkillpointsForLevel returns the number of skillpoints that a skill of the given
+// rank has when trained to the given level.
+func SkillpointsForLevel(rank float64, level int) int {
+	if level <= 0 {
+		return 0
+	}
+	return int(math.Ceil(250.0 * rank * math.Pow(2, 2.5*float64(level-1))))
+}
+
+// skillInfo holds the dogma attributes that determine how long a skill takes
+// to train.
+type skillInfo struct {
+	rank               float64
+	primary, secondary int
+}
+
+// LevelTraining is the training required for one level of a skill.
+type LevelTraining struct {
+	Level int
+	// Skillpoints is the skill's total number of skillpoints at this level.
+	Skillpoints int
+	// Required is the number of skillpoints still to be trained to reach this
+	// level from the previous one, taking into account any skillpoints the
+	// character has already trained.
+	Required int
+	Duration time.Duration
+}
+
+// Trainer calculates how long a character takes to train skills.
+type Trainer struct {
+	db evego.Database
+	// attributes are the character's attributes including implant bonuses,
+	// keyed by attribute ID.
+	attributes  map[int]int
+	skillpoints map[int]int
+	levels      map[int]int
+	skills      map[int]*skillInfo
+	// requirements caches the skills directly required by each item type.
+	requirements map[int][]SkillRequirement
+}
+
+// NewTrainer returns a Trainer for the given character, whose attributes are
+// increased by the bonuses of the implants on the character sheet.
+func NewTrainer(db evego.Database, sheet *evego.CharacterSheet) (*Trainer, error) {
+	t := &Trainer{
+		db: db,
+		attributes: map[int]int{
+			evego.AttributeIntelligence: sheet.Attributes.Intelligence,
+			evego.AttributeMemory:       sheet.Attributes.Memory,
+			evego.AttributeCharisma:     sheet.Attributes.Charisma,
+			evego.AttributePerception:   sheet.Attributes.Perception,
+			evego.AttributeWillpower:    sheet.Attributes.Willpower,
+		},
+		skillpoints:  make(map[int]int, len(sheet.Skills)),
+		levels:       make(map[int]int, len(sheet.Skills)),
+		skills:       make(map[int]*skillInfo),
+		requirements: make(map[int][]SkillRequirement),
+	}
+	for _, sk := range sheet.Skills {
+		t.skillpoints[sk.TypeID] = sk.NumSkillpoints
+		t.levels[sk.TypeID] = sk.Level
+	}
+	implants, err := db.ItemsAttributes(sheet.Implants)
+	if err != nil {
+		return nil, err
+	}
+	bonuses := map[int]int{
+		evego.AttributeIntelligenceBonus: evego.AttributeIntelligence,
+		evego.AttributeMemoryBonus:       evego.AttributeMemory,
+		evego.AttributeCharismaBonus:     evego.AttributeCharisma,
+		evego.AttributePerceptionBonus:   evego.AttributePerception,
+		evego.AttributeWillpowerBonus:    evego.AttributeWillpower,
+	}
+	for _, attrs := range implants {
+		for _, a := range attrs {
+			if attr, ok := bonuses[a.AttributeID]; ok {
+				t.attributes[attr] += int(a.Value)
+			}
+		}
+	}
+	return t, nil
+}
+
+// skill returns the training attributes of a skill.
+func (t *Trainer) skill(skill *evego.Item) (*skillInfo, error) {
+	if info, ok := t.skills[skill.ID]; ok {
+		return info, nil
+	}
+	attrs, err := t.db.ItemAttributes(skill.ID)
+	if err != nil {
+		return nil, err
+	}
+	info := &skillInfo{}
+	for _, a := range attrs {
+		switch a.AttributeID {
+		case evego.AttributeSkillTimeConstant:
+			info.rank = a.Value
+		case evego.AttributePrimaryAttribute:
+			info.primary = int(a.Value)
+		case evego.AttributeSecondaryAttribute:
+			info.secondary = int(a.Value)
+		}
+	}
+	if info.rank == 0 || info.primary == 0 || info.secondary == 0 {
+		return nil, fmt.Errorf("%v is not a skill", skill.Name)
+	}
+	t.skills[skill.ID] = info
+	return info, nil
+}
+
+// Rank returns a skill's training time multiplier.
+func (t *Trainer) Rank(skill *evego.Item) (float64, error) {
+	info, err := t.skill(skill)
+	if err != nil {
+		return 0, err
+	}
+	return info.rank, nil
+}
+
+// SkillpointsPerMinute returns the rate at which the character trains a
+// skill.
+func (t *Trainer) SkillpointsPerMinute(skill *evego.Item) (float64, error) {
+	info, err := t.skill(skill)
+	if err != nil {
+		return 0, err
+	}
+	return float64(t.attributes[info.primary]) + float64(t.attributes[info.secondary])/2.0, nil
+}
+
+// Skillpoints returns the number of skillpoints the character has in a skill.
+func (t *Trainer) Skillpoints(skill *evego.Item) int {
+	return t.skillpoints[skill.ID]
+}
+
+// training returns the training required to take a skill to the given level
+// from the given number of skillpoints.
+func (t *Trainer) training(skill *evego.Item, level, skillpoints int) (*LevelTraining, error) {
+	if level < 1 || level > MaxSkillLevel {
+		return nil, fmt.Errorf("Invalid level %d for %v", level, skill.Name)
+	}
+	info, err := t.skill(skill)
+	if err != nil {
+		return nil, err
+	}
+	rate, _ := t.SkillpointsPerMinute(skill)
+	lt := &LevelTraining{
+		Level:       level,
+		Skillpoints: SkillpointsForLevel(info.rank, level),
+	}
+	from := SkillpointsForLevel(info.rank, level-1)
+	if skillpoints > from {
+		from = skillpoints
+	}
+	if lt.Skillpoints > from {
+		lt.Required = lt.Skillpoints - from
+	}
+	if lt.Required > 0 {
+		if rate <= 0 {
+			return nil, fmt.Errorf("Unable to train %v with no attributes", skill.Name)
+		}
+		seconds := math.Ceil(float64(lt.Required) / rate * 60.0)
+		lt.Duration = time.Duration(seconds) * time.Second
+	}
+	return lt, nil
+}
+
+// LevelTraining returns the training the character requires to take a skill
+// from the previous level to the given one.
+func (t *Trainer) LevelTraining(skill *evego.Item, level int) (*LevelTraining, error) {
+	return t.training(skill, level, t.skillpoints[skill.ID])
+}
+
+// SkillTraining returns the training the character requires for each level
+// of a skill.
+func (t *Trainer) SkillTraining(skill *evego.Item) ([]LevelTraining, error) {
+	levels := make([]LevelTraining, 0, MaxSkillLevel)
+	for level := 1; level <= MaxSkillLevel; level++ {
+		lt, err := t.LevelTraining(skill, level)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, *lt)
+	}
+	return levels, nil
+}
+
+// PlanTraining returns the training required for each entry of a skill plan,
+// in order. Each entry is assumed to start when the previous one finishes, so
+// levels that the character already has, or that the plan trains earlier,
+// take no time.
+func (t *Trainer) PlanTraining(plan evego.SkillPlan) ([]LevelTraining, error) {
+	skillpoints := make(map[int]int)
+	results := make([]LevelTraining, 0, len(plan))
+	for _, p := range plan {
+		sp, ok := skillpoints[p.Skill.ID]
+		if !ok {
+			sp = t.skillpoints[p.Skill.ID]
+		}
+		lt, err := t.training(p.Skill, p.Level, sp)
+		if err != nil {
+			return nil, err
+		}
+		if lt.Skillpoints > sp {
+			skillpoints[p.Skill.ID] = lt.Skillpoints
+		} else {
+			skillpoints[p.Skill.ID] = sp
+		}
+		results = append(results, *lt)
+	}
+	return results, nil
+}
+
+// PlanDuration returns the total time the character requires to train a
+// skill plan.
+func (t *Trainer) PlanDuration(plan evego.SkillPlan) (time.Duration, error) {
+	training, err := t.PlanTraining(plan)
+	if err != nil {
+		return 0, err
+	}
+	var total time.Duration
+	for _, lt := range training {
+		total += lt.Duration
+	}
+	return total, nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package character_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/character"
+	"github.com/backerman/evego/pkg/dbaccess"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+
+	// Register SQLite3 and PgSQL drivers
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testDbDriver, testDbPath string
+
+func init() {
+	viper.SetDefault("DBDriver", "sqlite3")
+	viper.SetDefault("DBPath", "../../testdb.sqlite")
+	viper.SetEnvPrefix("EVEGO_TEST")
+	viper.AutomaticEnv()
+	testDbDriver = viper.GetString("DBDriver")
+	testDbPath = viper.GetString("DBPath")
+}
+
+func TestSkillpoints(t *testing.T) {
+	Convey("Skillpoints are calculated from rank and level.", t, func() {
+		expected := []int{0, 250, 1415, 8000, 45255, 256000}
+		for level, sp := range expected {
+			So(character.SkillpointsForLevel(1, level), ShouldEqual, sp)
+		}
+		So(character.SkillpointsForLevel(5, 1), ShouldEqual, 1250)
+		So(character.SkillpointsForLevel(5, 2), ShouldEqual, 7072)
+		So(character.SkillpointsForLevel(5, 5), ShouldEqual, 1280000)
+	})
+}
+
+func TestTrainingTime(t *testing.T) {
+	Convey("Given a character with implants", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		sheet := &evego.CharacterSheet{
+			Skills: []evego.Skill{
+				{TypeID: 3327, NumSkillpoints: 45255, Level: 4},
+				{TypeID: 3436, NumSkillpoints: 100000, Level: 4},
+			},
+			Attributes: evego.Attributes{
+				Intelligence: 17,
+				Memory:       23,
+				Charisma:     17,
+				Perception:   25,
+				Willpower:    17,
+			},
+			// Memory Augmentation and Ocular Filter - Standard
+			Implants: []int{10208, 10216},
+		}
+		trainer, err := character.NewTrainer(db, sheet)
+		So(err, ShouldBeNil)
+		item := func(name string) *evego.Item {
+			i, err := db.ItemForName(name)
+			So(err, ShouldBeNil)
+			return i
+		}
+		gunnery := item("Gunnery")
+		cruiser := item("Gallente Cruiser")
+		command := item("Spaceship Command")
+		drones := item("Drones")
+
+		Convey("Implants are included in the training rate.", func() {
+			rate, err := trainer.SkillpointsPerMinute(gunnery)
+			So(err, ShouldBeNil)
+			So(rate, ShouldEqual, 36.5)
+			rate, err = trainer.SkillpointsPerMinute(drones)
+			So(err, ShouldBeNil)
+			So(rate, ShouldEqual, 40.0)
+			rank, err := trainer.Rank(cruiser)
+			So(err, ShouldBeNil)
+			So(rank, ShouldEqual, 5)
+		})
+
+		Convey("Each level of an untrained skill is timed.", func() {
+			levels, err := trainer.SkillTraining(cruiser)
+			So(err, ShouldBeNil)
+			So(levels, ShouldHaveLength, 5)
+			So(levels[0], ShouldResemble, character.LevelTraining{
+				Level: 1, Skillpoints: 1250, Required: 1250, Duration: 2055 * time.Second,
+			})
+			So(levels[1].Required, ShouldEqual, 5822)
+			So(levels[1].Duration, ShouldEqual, 9571*time.Second)
+		})
+
+		Convey("Trained and partially trained levels are accounted for.", func() {
+			lt, err := trainer.LevelTraining(command, 4)
+			So(err, ShouldBeNil)
+			So(lt.Required, ShouldEqual, 0)
+			So(lt.Duration, ShouldEqual, 0)
+			lt, err = trainer.LevelTraining(command, 5)
+			So(err, ShouldBeNil)
+			So(lt.Required, ShouldEqual, 210745)
+			So(lt.Duration, ShouldEqual, 346431*time.Second)
+			lt, err = trainer.LevelTraining(drones, 5)
+			So(err, ShouldBeNil)
+			So(lt.Required, ShouldEqual, 156000)
+			So(lt.Duration, ShouldEqual, 65*time.Hour)
+		})
+
+		Convey("A plan's duration is the sum of its levels.", func() {
+			plan := evego.SkillPlan{
+				{Skill: cruiser, Level: 1},
+				{Skill: cruiser, Level: 2},
+				{Skill: cruiser, Level: 1},
+				{Skill: command, Level: 4},
+			}
+			training, err := trainer.PlanTraining(plan)
+			So(err, ShouldBeNil)
+			So(training, ShouldHaveLength, 4)
+			So(training[2].Duration, ShouldEqual, 0)
+			total, err := trainer.PlanDuration(plan)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, (2055+9571)*time.Second)
+		})
+
+		Convey("Things that aren't skills or levels are rejected.", func() {
+			_, err := trainer.SkillTraining(item("Vexor"))
+			So(err, ShouldNotBeNil)
+			_, err = trainer.LevelTraining(gunnery, 6)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
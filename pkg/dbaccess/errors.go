@@ -0,0 +1,74 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrBackend matches any error caused by the failure of the database backend,
+// as opposed to the requested data not being there. Lookups that find nothing
+// return sql.ErrNoRows instead.
+//
+//	if errors.Is(err, dbaccess.ErrBackend) {
+//	    // Something's wrong with the database; try again later.
+//	}
+var ErrBackend = errors.New("Database backend failure")
+
+// QueryError is an error returned by the database backend while performing an
+// operation. It matches ErrBackend.
+type QueryError struct {
+	Op  string // what we were doing, e.g. "get item composition"
+	Err error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("Unable to %s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrBackend.
+func (e *QueryError) Is(target error) bool {
+	return target == ErrBackend
+}
+
+// dbError classifies an error returned while performing an operation. A nil
+// error, sql.ErrNoRows, the error of a context that's done, or an error that's
+// already been classified is returned as is; anything else is a backend
+// failure.
+func dbError(op string, err error) error {
+	var qe *QueryError
+	if err == nil || err == sql.ErrNoRows || errors.As(err, &qe) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return &QueryError{Op: op, Err: err}
+}
+
+// inconsistent returns the error for a database whose tables don't agree
+// with one another, e.g. a blueprint whose product isn't in invTypes.
+func inconsistent(format string, args ...interface{}) error {
+	return &QueryError{Op: "read consistent data", Err: fmt.Errorf(format, args...)}
+}
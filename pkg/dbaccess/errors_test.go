@@ -0,0 +1,174 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/backerman/evego/pkg/dbaccess"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestErrors(t *testing.T) {
+	Convey("Opening a database with an unknown driver returns an error.", t, func() {
+		db, err := dbaccess.SQLDatabase("nonexistent", testDbPath)
+		So(db, ShouldBeNil)
+		So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+	})
+
+	Convey("Given a test database", t, func() {
+		db := openTestDatabase()
+		defer db.Close()
+
+		Convey("Lookups of nonexistent objects return sql.ErrNoRows.", func() {
+			item, err := db.ItemForID(-1)
+			So(item, ShouldBeNil)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeFalse)
+
+			items, err := db.ItemsForIDs([]int{})
+			So(err, ShouldBeNil)
+			So(items, ShouldBeEmpty)
+
+			system, err := db.SolarSystemForName("Nonexistent")
+			So(system, ShouldBeNil)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			station, err := db.StationForID(-1)
+			So(station, ShouldBeNil)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.ItemAttributeValue(-1, -1)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+
+	Convey("Given a canceled context", t, func() {
+		db := openTestDatabase()
+		defer db.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Convey("Lookups return the context's error.", func() {
+			_, err := db.ItemForIDContext(ctx, 34)
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeFalse)
+
+			_, err = db.SolarSystemsForPatternContext(ctx, "Dodixie")
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+
+			_, err = db.BlueprintOutputsContext(ctx, "Vexor Blueprint")
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+		})
+	})
+
+	if testBackend == "memory" {
+		return
+	}
+
+	Convey("Given a closed SQL database", t, func() {
+		db := openTestDatabase()
+		db.Close()
+
+		Convey("Lookups return backend failures.", func() {
+			_, err := db.ItemForID(34)
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+			So(errors.Is(err, sql.ErrNoRows), ShouldBeFalse)
+
+			_, err = db.ItemComposition(34)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+
+			_, err = db.BlueprintOutputs("Vexor Blueprint")
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+
+			var qe *dbaccess.QueryError
+			So(errors.As(err, &qe), ShouldBeTrue)
+			So(qe.Err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given an SDE whose reaction uses a nonexistent material", t, func() {
+		dir, err := ioutil.TempDir("", "evego-sde")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		files, err := filepath.Glob("../../testdata/sde/*.jsonl")
+		So(err, ShouldBeNil)
+		for _, f := range files {
+			contents, err := ioutil.ReadFile(f)
+			So(err, ShouldBeNil)
+			switch filepath.Base(f) {
+			case "types.jsonl":
+				contents = append(contents, `{"_key": 99001, "groupID": 18, "name": "Test Reaction Formula", "published": true}`+"\n"...)
+			case "blueprints.jsonl":
+				contents = append(contents, `{"_key": 99001, "activities": {"reaction": {"materials": [{"quantity": 100, "typeID": 99999}], "products": [{"quantity": 1, "typeID": 34}], "time": 3600}}}`+"\n"...)
+			}
+			So(ioutil.WriteFile(filepath.Join(dir, filepath.Base(f)), contents, 0644), ShouldBeNil)
+		}
+		snap, err := dbaccess.SnapshotFromSDE(dir)
+		So(err, ShouldBeNil)
+		db := dbaccess.MemoryDatabase(snap)
+
+		Convey("Its reaction formula returns a backend failure.", func() {
+			_, err := db.ReactionFormulas("Test Reaction Formula")
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+			So(errors.Is(err, sql.ErrNoRows), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a SQLite database whose item names can't be read at first", t, func() {
+		if testDbDriver != "sqlite3" {
+			SkipSo("requires SQLite")
+			return
+		}
+		dir, err := ioutil.TempDir("", "evego-db")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		contents, err := ioutil.ReadFile(testDbPath)
+		So(err, ShouldBeNil)
+		path := filepath.Join(dir, "test.sqlite")
+		So(ioutil.WriteFile(path, contents, 0644), ShouldBeNil)
+		db, err := dbaccess.SQLDatabase("sqlite3", path)
+		So(err, ShouldBeNil)
+		defer db.Close()
+		raw, err := sql.Open("sqlite3", path)
+		So(err, ShouldBeNil)
+		defer raw.Close()
+		_, err = raw.Exec(`ALTER TABLE "invTypes" RENAME TO "invTypesHidden"`)
+		So(err, ShouldBeNil)
+
+		Convey("Searching fails, and succeeds once they can be read.", func() {
+			_, err := db.SearchItems("Vexor", 1)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+
+			_, err = raw.Exec(`ALTER TABLE "invTypesHidden" RENAME TO "invTypes"`)
+			So(err, ShouldBeNil)
+			matches, err := db.SearchItems("Vexor", 1)
+			So(err, ShouldBeNil)
+			So(matches, ShouldHaveLength, 1)
+		})
+	})
+}
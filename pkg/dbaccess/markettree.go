@@ -0,0 +1,115 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"sort"
+
+	"github.com/backerman/evego"
+)
+
+// marketGroup converts a market group row; its Parent is not set.
+func (g snapMarketGroup) marketGroup() evego.MarketGroup {
+	return evego.MarketGroup{
+		ID:          g.ID,
+		Name:        g.Name,
+		Description: g.Description,
+	}
+}
+
+type marketGroupsByName []snapMarketGroup
+
+func (m marketGroupsByName) Len() int      { return len(m) }
+func (m marketGroupsByName) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m marketGroupsByName) Less(i, j int) bool {
+	if m[i].Name != m[j].Name {
+		return m[i].Name < m[j].Name
+	}
+	return m[i].ID < m[j].ID
+}
+
+type itemPtrsByName []*evego.Item
+
+func (m itemPtrsByName) Len() int      { return len(m) }
+func (m itemPtrsByName) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m itemPtrsByName) Less(i, j int) bool {
+	if m[i].Name != m[j].Name {
+		return m[i].Name < m[j].Name
+	}
+	return m[i].ID < m[j].ID
+}
+
+// marketItemType returns the type of an item in the market group groupID, as
+// required for reprocessing yield calculation, using a map of every market
+// group. Items under the Ore or Ice Ore groups are ore or ice; items that
+// aren't on the market, or are directly in a top-level group, are of unknown
+// type.
+func marketItemType(groups map[int]*snapMarketGroup, groupID int) evego.ItemType {
+	group := groups[groupID]
+	if group == nil || groups[group.ParentID] == nil {
+		return evego.UnknownItemType
+	}
+	// Stop after visiting every group, in case the hierarchy has a loop.
+	for i := 0; group != nil && i < len(groups); i++ {
+		switch group.Name {
+		case "Ore":
+			return evego.Ore
+		case "Ice Ore":
+			return evego.Ice
+		}
+		group = groups[group.ParentID]
+	}
+	return evego.Other
+}
+
+// marketTree assembles the market hierarchy from every market group, sorted by
+// name, and the items in each group. Groups that can't be reached from a
+// top-level group are left out.
+func marketTree(groups []snapMarketGroup, items map[int][]*evego.Item) []*evego.MarketGroupNode {
+	nodes := make(map[int]*evego.MarketGroupNode, len(groups))
+	for _, g := range groups {
+		groupItems := items[g.ID]
+		sort.Sort(itemPtrsByName(groupItems))
+		nodes[g.ID] = &evego.MarketGroupNode{
+			MarketGroup: g.marketGroup(),
+			Items:       groupItems,
+		}
+	}
+	var roots []*evego.MarketGroupNode
+	for _, g := range groups {
+		node := nodes[g.ID]
+		if g.ParentID == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[g.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	// Set each group's parent. Groups in a loop aren't reachable from the top
+	// level, so we won't go around in circles.
+	var setParents func(n *evego.MarketGroupNode)
+	setParents = func(n *evego.MarketGroupNode) {
+		for _, c := range n.Children {
+			c.Parent = &n.MarketGroup
+			setParents(c)
+		}
+	}
+	for _, r := range roots {
+		setParents(r)
+	}
+	return roots
+}
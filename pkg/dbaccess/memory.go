@@ -0,0 +1,1186 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/backerman/evego"
+)
+
+// Reaction formulas are activity 11 in ramActivities.
+const reactionActivityID = 11
+
+type memoryDb struct {
+	types          map[int]*snapType
+	typesByName    map[string]*snapType
+	groups         map[int]*snapGroup
+	categories     map[int]*snapCategory
+	marketGroups   map[int]*snapMarketGroup
+	marketChildren map[int][]snapMarketGroup // sorted by name
+	typeMaterials  map[int][]snapMaterial
+	regions        map[int]*evego.Region
+	regionsByName  map[string]*evego.Region
+	constellations map[int]*snapConstellation
+	systems        []snapSolarSystem // sorted by name
+	systemsByID    map[int]*snapSolarSystem
+	systemJumps    map[int][]int
+	celestials     map[int][]snapCelestial // by system, in item ID order
+	stations       []evego.Station         // sorted by name
+	stationsByID   map[int]*evego.Station
+	factions       []evego.Faction // sorted by name
+	factionsByID   map[int]*evego.Faction
+	npcCorps       []evego.NPCCorp // sorted by name
+	npcCorpsByID   map[int]*evego.NPCCorp
+	agents         []evego.Agent // sorted by name
+	agentsByID     map[int]*evego.Agent
+	activities     map[int]string
+	industry       []snapIndustryActivity // sorted by type ID
+	industryMats   map[int][]snapIndustryMaterial
+	industryProds  map[int][]snapIndustryMaterial
+	products       []snapIndustryMaterial
+	attributes     map[int]*evego.DogmaAttribute
+	attrsByName    map[string]*evego.DogmaAttribute
+	typeAttributes map[int][]evego.ItemAttribute
+	typeEffects    map[int][]evego.ItemEffect
+	searchIndex    itemIndex
+	postgres       bool // match patterns as PostgreSQL does instead of SQLite
+}
+
+// MemoryDatabase returns a Database that answers queries from a snapshot
+// held in memory. It doesn't need a SQL driver, and gives the same results as
+// SQLDatabase does with the database that the snapshot was taken from,
+// including whether name patterns ignore case. Snapshots of the official
+// export match patterns as SQLite does: blueprint and reaction names ignore the
+// case of ASCII letters only.
+func MemoryDatabase(snap *Snapshot) evego.Database {
+	data := &snap.data
+	db := &memoryDb{
+		postgres:       data.Driver == "postgres",
+		types:          make(map[int]*snapType),
+		typesByName:    make(map[string]*snapType),
+		groups:         make(map[int]*snapGroup),
+		categories:     make(map[int]*snapCategory),
+		marketGroups:   make(map[int]*snapMarketGroup),
+		marketChildren: make(map[int][]snapMarketGroup),
+		typeMaterials:  make(map[int][]snapMaterial),
+		regions:        make(map[int]*evego.Region),
+		regionsByName:  make(map[string]*evego.Region),
+		constellations: make(map[int]*snapConstellation),
+		systemsByID:    make(map[int]*snapSolarSystem),
+		systemJumps:    make(map[int][]int),
+		celestials:     make(map[int][]snapCelestial),
+		stationsByID:   make(map[int]*evego.Station),
+		factionsByID:   make(map[int]*evego.Faction),
+		npcCorpsByID:   make(map[int]*evego.NPCCorp),
+		agentsByID:     make(map[int]*evego.Agent),
+		activities:     make(map[int]string),
+		industryMats:   make(map[int][]snapIndustryMaterial),
+		industryProds:  make(map[int][]snapIndustryMaterial),
+		attributes:     make(map[int]*evego.DogmaAttribute),
+		attrsByName:    make(map[string]*evego.DogmaAttribute),
+		typeAttributes: make(map[int][]evego.ItemAttribute),
+		typeEffects:    make(map[int][]evego.ItemEffect),
+	}
+
+	var names []itemName
+	for i := range data.Types {
+		t := &data.Types[i]
+		db.types[t.ID] = t
+		if _, ok := db.typesByName[t.Name]; !ok {
+			db.typesByName[t.Name] = t
+		}
+		if t.Name != "" {
+			names = append(names, itemName{
+				ID:        t.ID,
+				Name:      t.Name,
+				Published: t.Published,
+				OnMarket:  t.MarketGroupID != 0,
+			})
+		}
+	}
+	db.searchIndex = newItemIndex(names)
+	for i := range data.Groups {
+		db.groups[data.Groups[i].ID] = &data.Groups[i]
+	}
+	for i := range data.Categories {
+		db.categories[data.Categories[i].ID] = &data.Categories[i]
+	}
+	for i := range data.MarketGroups {
+		g := &data.MarketGroups[i]
+		db.marketGroups[g.ID] = g
+		db.marketChildren[g.ParentID] = append(db.marketChildren[g.ParentID], *g)
+	}
+	for _, children := range db.marketChildren {
+		sort.Sort(marketGroupsByName(children))
+	}
+	for _, m := range data.TypeMaterials {
+		db.typeMaterials[m.TypeID] = append(db.typeMaterials[m.TypeID], m)
+	}
+	for i := range data.Regions {
+		r := &data.Regions[i]
+		db.regions[r.ID] = r
+		db.regionsByName[r.Name] = r
+	}
+	for i := range data.Constellations {
+		db.constellations[data.Constellations[i].ID] = &data.Constellations[i]
+	}
+	db.systems = append(db.systems, data.SolarSystems...)
+	sort.Sort(systemsByName(db.systems))
+	for i := range db.systems {
+		db.systemsByID[db.systems[i].ID] = &db.systems[i]
+	}
+	for _, j := range data.SystemJumps {
+		db.systemJumps[j.FromSystemID] = append(db.systemJumps[j.FromSystemID], j.ToSystemID)
+	}
+	for _, c := range data.Celestials {
+		db.celestials[c.SystemID] = append(db.celestials[c.SystemID], c)
+	}
+	for _, c := range db.celestials {
+		sort.Sort(celestialsByID(c))
+	}
+	db.stations = append(db.stations, data.Stations...)
+	sort.Sort(stationsByName(db.stations))
+	for i := range db.stations {
+		db.stationsByID[db.stations[i].ID] = &db.stations[i]
+	}
+	// Factions, corporations, and agents are already sorted by name.
+	db.factions = append(db.factions, data.Factions...)
+	for i := range db.factions {
+		db.factionsByID[db.factions[i].ID] = &db.factions[i]
+	}
+	db.npcCorps = append(db.npcCorps, data.NPCCorporations...)
+	for i := range db.npcCorps {
+		db.npcCorpsByID[db.npcCorps[i].ID] = &db.npcCorps[i]
+	}
+	db.agents = append(db.agents, data.Agents...)
+	for i := range db.agents {
+		db.agentsByID[db.agents[i].ID] = &db.agents[i]
+	}
+	for _, a := range data.Activities {
+		db.activities[a.ID] = a.Name
+	}
+	db.industry = append(db.industry, data.IndustryActivities...)
+	sort.Sort(industryByType(db.industry))
+	for _, m := range data.IndustryMaterials {
+		db.industryMats[m.TypeID] = append(db.industryMats[m.TypeID], m)
+	}
+	for _, p := range data.IndustryProducts {
+		db.industryProds[p.TypeID] = append(db.industryProds[p.TypeID], p)
+	}
+	db.products = data.IndustryProducts
+	for i := range data.Attributes {
+		a := &data.Attributes[i]
+		db.attributes[a.ID] = a
+		db.attrsByName[a.Name] = a
+	}
+	for _, a := range data.TypeAttributes {
+		db.typeAttributes[a.TypeID] = append(db.typeAttributes[a.TypeID], a)
+	}
+	for _, e := range data.TypeEffects {
+		db.typeEffects[e.TypeID] = append(db.typeEffects[e.TypeID], e)
+	}
+	return db
+}
+
+type systemsByName []snapSolarSystem
+
+func (s systemsByName) Len() int           { return len(s) }
+func (s systemsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s systemsByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+type stationsByName []evego.Station
+
+func (s stationsByName) Len() int           { return len(s) }
+func (s stationsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s stationsByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+type constellationsByName []evego.Constellation
+
+func (s constellationsByName) Len() int           { return len(s) }
+func (s constellationsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s constellationsByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+type celestialsByID []snapCelestial
+
+func (s celestialsByID) Len() int           { return len(s) }
+func (s celestialsByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s celestialsByID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+
+type industryByType []snapIndustryActivity
+
+func (a industryByType) Len() int      { return len(a) }
+func (a industryByType) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a industryByType) Less(i, j int) bool {
+	if a[i].TypeID != a[j].TypeID {
+		return a[i].TypeID < a[j].TypeID
+	}
+	return a[i].ActivityID < a[j].ActivityID
+}
+
+// likeMatcher returns a function that matches strings against a SQL LIKE
+// pattern, after passing both through fold if it isn't nil.
+func likeMatcher(pattern string, fold func(string) string) func(string) bool {
+	if fold != nil {
+		pattern = fold(pattern)
+	}
+	var expr []string
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr = append(expr, ".*")
+		case '_':
+			expr = append(expr, ".")
+		default:
+			expr = append(expr, regexp.QuoteMeta(string(r)))
+		}
+	}
+	re := regexp.MustCompile("(?s)^" + strings.Join(expr, "") + "$")
+	if fold == nil {
+		return re.MatchString
+	}
+	return func(s string) bool {
+		return re.MatchString(fold(s))
+	}
+}
+
+// asciiLower converts only the ASCII letters in s to lower case, as SQLite's
+// LIKE and LOWER do.
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, s)
+}
+
+// like returns a function that matches strings as "column LIKE pattern" does
+// in the snapshot's database. PostgreSQL's LIKE is case-sensitive; SQLite's
+// ignores the case of ASCII letters.
+func (db *memoryDb) like(pattern string) func(string) bool {
+	if db.postgres {
+		return likeMatcher(pattern, nil)
+	}
+	return likeMatcher(pattern, asciiLower)
+}
+
+// lowerLike returns a function that matches strings as
+// "LOWER(column) LIKE LOWER(pattern)" does in the snapshot's database.
+// PostgreSQL's LOWER converts all letters; SQLite's only ASCII ones.
+func (db *memoryDb) lowerLike(pattern string) func(string) bool {
+	if db.postgres {
+		return likeMatcher(pattern, strings.ToLower)
+	}
+	return likeMatcher(pattern, asciiLower)
+}
+
+func (db *memoryDb) Close() error {
+	return nil
+}
+
+// item returns the Item for a type, or nil if the type doesn't exist or isn't
+// in a valid group and category.
+func (db *memoryDb) item(t *snapType) *evego.Item {
+	if t == nil {
+		return nil
+	}
+	group, ok := db.groups[t.GroupID]
+	if !ok {
+		return nil
+	}
+	category, ok := db.categories[group.CategoryID]
+	if !ok {
+		return nil
+	}
+	item := &evego.Item{
+		Name:           t.Name,
+		ID:             t.ID,
+		Category:       category.Name,
+		Group:          group.Name,
+		GroupID:        group.ID,
+		BatchSize:      t.PortionSize,
+		Volume:         t.Volume,
+		PackagedVolume: t.PackagedVolume,
+		Capacity:       t.Capacity,
+		Mass:           t.Mass,
+		BasePrice:      t.BasePrice,
+	}
+	item.Type = marketItemType(db.marketGroups, t.MarketGroupID)
+	return item
+}
+
+func (db *memoryDb) ItemForName(itemName string) (*evego.Item, error) {
+	item := db.item(db.typesByName[itemName])
+	if item == nil {
+		return nil, sql.ErrNoRows
+	}
+	return item, nil
+}
+
+func (db *memoryDb) ItemForID(itemID int) (*evego.Item, error) {
+	item := db.item(db.types[itemID])
+	if item == nil {
+		return nil, sql.ErrNoRows
+	}
+	return item, nil
+}
+
+func (db *memoryDb) ItemsForIDs(itemIDs []int) ([]*evego.Item, error) {
+	items := make([]*evego.Item, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		if item := db.item(db.types[id]); item != nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (db *memoryDb) ItemIDs() ([]int, error) {
+	ids := make([]int, 0, len(db.types))
+	for id := range db.types {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (db *memoryDb) SearchItems(query string, limit int) ([]evego.ItemMatch, error) {
+	hits := db.searchIndex.search(query, limit)
+	if len(hits) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return itemMatches(context.Background(), db, hits)
+}
+
+func (db *memoryDb) ItemComposition(itemID int) ([]evego.InventoryLine, error) {
+	var results []evego.InventoryLine
+	for _, m := range db.typeMaterials[itemID] {
+		item, err := db.ItemForID(m.MaterialTypeID)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("item %d has nonexistent component %d", itemID, m.MaterialTypeID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, evego.InventoryLine{Quantity: m.Quantity, Item: item})
+	}
+	return results, nil
+}
+
+func (db *memoryDb) ItemsComposition(itemIDs []int) (map[int][]evego.InventoryLine, error) {
+	results := make(map[int][]evego.InventoryLine)
+	for _, id := range itemIDs {
+		if _, ok := db.typeMaterials[id]; !ok {
+			continue
+		}
+		comp, err := db.ItemComposition(id)
+		if err != nil {
+			return nil, err
+		}
+		results[id] = comp
+	}
+	return results, nil
+}
+
+func (db *memoryDb) MarketGroupForItem(item *evego.Item) (*evego.MarketGroup, error) {
+	t, ok := db.types[item.ID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	group := db.marketGroups[t.MarketGroupID]
+	// As with the SQL query, an item directly in a top-level group has no
+	// market group hierarchy.
+	if group == nil || db.marketGroups[group.ParentID] == nil {
+		return nil, sql.ErrNoRows
+	}
+	var itemGroup, curLevel *evego.MarketGroup
+	// Stop after visiting every group, in case the hierarchy has a loop.
+	for i := 0; group != nil && i < len(db.marketGroups); i++ {
+		nextLevel := &evego.MarketGroup{
+			ID:          group.ID,
+			Name:        group.Name,
+			Description: group.Description,
+		}
+		if itemGroup == nil {
+			itemGroup = nextLevel
+		} else {
+			curLevel.Parent = nextLevel
+		}
+		curLevel = nextLevel
+		group = db.marketGroups[group.ParentID]
+	}
+	return itemGroup, nil
+}
+
+func (db *memoryDb) MarketGroupChildren(groupID int) ([]evego.MarketGroup, error) {
+	if _, ok := db.marketGroups[groupID]; !ok && groupID != 0 {
+		return nil, sql.ErrNoRows
+	}
+	groups := make([]evego.MarketGroup, 0, len(db.marketChildren[groupID]))
+	for _, g := range db.marketChildren[groupID] {
+		groups = append(groups, g.marketGroup())
+	}
+	return groups, nil
+}
+
+func (db *memoryDb) ItemsInMarketGroup(groupID int, recursive bool) ([]*evego.Item, error) {
+	if _, ok := db.marketGroups[groupID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	wanted := map[int]bool{groupID: true}
+	if recursive {
+		queue := []int{groupID}
+		for len(queue) > 0 {
+			for _, g := range db.marketChildren[queue[0]] {
+				if !wanted[g.ID] {
+					wanted[g.ID] = true
+					queue = append(queue, g.ID)
+				}
+			}
+			queue = queue[1:]
+		}
+	}
+	items := []*evego.Item{}
+	for _, t := range db.types {
+		if !wanted[t.MarketGroupID] {
+			continue
+		}
+		if item := db.item(t); item != nil {
+			items = append(items, item)
+		}
+	}
+	sort.Sort(itemPtrsByName(items))
+	return items, nil
+}
+
+func (db *memoryDb) MarketTree() ([]*evego.MarketGroupNode, error) {
+	groups := make([]snapMarketGroup, 0, len(db.marketGroups))
+	for _, g := range db.marketGroups {
+		groups = append(groups, *g)
+	}
+	sort.Sort(marketGroupsByName(groups))
+	items := make(map[int][]*evego.Item)
+	for _, t := range db.types {
+		if t.MarketGroupID == 0 {
+			continue
+		}
+		if item := db.item(t); item != nil {
+			items[t.MarketGroupID] = append(items[t.MarketGroupID], item)
+		}
+	}
+	return marketTree(groups, items), nil
+}
+
+// solarSystem returns the SolarSystem for a system in the snapshot, or nil if
+// its constellation or region is missing.
+func (db *memoryDb) solarSystem(s *snapSolarSystem) *evego.SolarSystem {
+	constellation, ok := db.constellations[s.ConstellationID]
+	if !ok {
+		return nil
+	}
+	region, ok := db.regions[constellation.RegionID]
+	if !ok {
+		return nil
+	}
+	return &evego.SolarSystem{
+		Name:            s.Name,
+		ID:              s.ID,
+		Constellation:   constellation.Name,
+		ConstellationID: constellation.ID,
+		Region:          region.Name,
+		RegionID:        region.ID,
+		Security:        s.Security,
+	}
+}
+
+func (db *memoryDb) SolarSystemForID(systemID int) (*evego.SolarSystem, error) {
+	s, ok := db.systemsByID[systemID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	system := db.solarSystem(s)
+	if system == nil {
+		return nil, sql.ErrNoRows
+	}
+	return system, nil
+}
+
+func (db *memoryDb) SolarSystemForName(systemName string) (*evego.SolarSystem, error) {
+	systems, err := db.SolarSystemsForPattern(systemName)
+	if err != nil {
+		return nil, err
+	}
+	return &systems[0], nil
+}
+
+func (db *memoryDb) SolarSystemsForPattern(systemName string) ([]evego.SolarSystem, error) {
+	match := db.lowerLike(systemName)
+	var systems []evego.SolarSystem
+	for i := range db.systems {
+		if !match(db.systems[i].Name) {
+			continue
+		}
+		if system := db.solarSystem(&db.systems[i]); system != nil {
+			systems = append(systems, *system)
+		}
+	}
+	if len(systems) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return systems, nil
+}
+
+func (db *memoryDb) RegionForName(regionName string) (*evego.Region, error) {
+	region, ok := db.regionsByName[regionName]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *region
+	return &result, nil
+}
+
+func (db *memoryDb) StationForID(stationID int) (*evego.Station, error) {
+	station, ok := db.stationsByID[stationID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *station
+	return &result, nil
+}
+
+func (db *memoryDb) StationsForName(stationName string) ([]evego.Station, error) {
+	match := db.lowerLike(stationName)
+	var stations []evego.Station
+	for _, s := range db.stations {
+		if match(s.Name) {
+			stations = append(stations, s)
+		}
+	}
+	if len(stations) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return stations, nil
+}
+
+func (db *memoryDb) FactionForID(factionID int) (*evego.Faction, error) {
+	faction, ok := db.factionsByID[factionID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *faction
+	return &result, nil
+}
+
+func (db *memoryDb) Factions() ([]evego.Faction, error) {
+	return append([]evego.Faction(nil), db.factions...), nil
+}
+
+func (db *memoryDb) NPCCorporationForID(corporationID int) (*evego.NPCCorp, error) {
+	corp, ok := db.npcCorpsByID[corporationID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *corp
+	return &result, nil
+}
+
+func (db *memoryDb) NPCCorporationsForFaction(factionID int) ([]evego.NPCCorp, error) {
+	if _, ok := db.factionsByID[factionID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	var corps []evego.NPCCorp
+	for _, c := range db.npcCorps {
+		if c.FactionID == factionID {
+			corps = append(corps, c)
+		}
+	}
+	return corps, nil
+}
+
+func (db *memoryDb) AgentForID(agentID int) (*evego.Agent, error) {
+	agent, ok := db.agentsByID[agentID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *agent
+	return &result, nil
+}
+
+// agentsWhere returns the agents, sorted by name, for which keep returns true.
+func (db *memoryDb) agentsWhere(keep func(a *evego.Agent) bool) []evego.Agent {
+	var agents []evego.Agent
+	for i := range db.agents {
+		if keep(&db.agents[i]) {
+			agents = append(agents, db.agents[i])
+		}
+	}
+	return agents
+}
+
+func (db *memoryDb) AgentsForCorporation(corporationID int) ([]evego.Agent, error) {
+	if _, ok := db.npcCorpsByID[corporationID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	return db.agentsWhere(func(a *evego.Agent) bool {
+		return a.CorporationID == corporationID
+	}), nil
+}
+
+func (db *memoryDb) AgentsForStation(stationID int) ([]evego.Agent, error) {
+	if _, ok := db.stationsByID[stationID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	return db.agentsWhere(func(a *evego.Agent) bool {
+		return a.StationID == stationID
+	}), nil
+}
+
+// constellation returns the Constellation for a constellation in the
+// snapshot, or nil if its region is missing.
+func (db *memoryDb) constellation(c *snapConstellation) *evego.Constellation {
+	region, ok := db.regions[c.RegionID]
+	if !ok {
+		return nil
+	}
+	return &evego.Constellation{
+		Name:     c.Name,
+		ID:       c.ID,
+		Region:   region.Name,
+		RegionID: region.ID,
+		Position: c.Position,
+	}
+}
+
+func (db *memoryDb) ConstellationForID(constellationID int) (*evego.Constellation, error) {
+	c, ok := db.constellations[constellationID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	constellation := db.constellation(c)
+	if constellation == nil {
+		return nil, sql.ErrNoRows
+	}
+	return constellation, nil
+}
+
+func (db *memoryDb) ConstellationsForRegion(regionID int) ([]evego.Constellation, error) {
+	if _, ok := db.regions[regionID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	var constellations []evego.Constellation
+	for _, c := range db.constellations {
+		if c.RegionID == regionID {
+			constellations = append(constellations, *db.constellation(c))
+		}
+	}
+	sort.Sort(constellationsByName(constellations))
+	return constellations, nil
+}
+
+// systemsWhere returns the solar systems, sorted by name, for which keep
+// returns true.
+func (db *memoryDb) systemsWhere(keep func(s *snapSolarSystem) bool) []evego.SolarSystem {
+	var systems []evego.SolarSystem
+	for i := range db.systems {
+		if !keep(&db.systems[i]) {
+			continue
+		}
+		if system := db.solarSystem(&db.systems[i]); system != nil {
+			systems = append(systems, *system)
+		}
+	}
+	return systems
+}
+
+func (db *memoryDb) SolarSystemsForConstellation(constellationID int) ([]evego.SolarSystem, error) {
+	if _, err := db.ConstellationForID(constellationID); err != nil {
+		return nil, err
+	}
+	return db.systemsWhere(func(s *snapSolarSystem) bool {
+		return s.ConstellationID == constellationID
+	}), nil
+}
+
+func (db *memoryDb) SolarSystemsForRegion(regionID int) ([]evego.SolarSystem, error) {
+	if _, ok := db.regions[regionID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	// As with the SQL query, a system's region is that of its constellation.
+	return db.systemsWhere(func(s *snapSolarSystem) bool {
+		c, ok := db.constellations[s.ConstellationID]
+		return ok && c.RegionID == regionID
+	}), nil
+}
+
+func (db *memoryDb) SolarSystemNeighbors(systemID int) ([]evego.SolarSystem, error) {
+	if _, err := db.SolarSystemForID(systemID); err != nil {
+		return nil, err
+	}
+	neighbors := make(map[int]bool)
+	for _, id := range db.systemJumps[systemID] {
+		neighbors[id] = true
+	}
+	return db.systemsWhere(func(s *snapSolarSystem) bool {
+		return neighbors[s.ID]
+	}), nil
+}
+
+// celestialsInGroup returns the objects of one inventory group in a solar
+// system.
+func (db *memoryDb) celestialsInGroup(systemID, groupID int) ([]snapCelestial, error) {
+	if _, err := db.SolarSystemForID(systemID); err != nil {
+		return nil, err
+	}
+	var rows []snapCelestial
+	for _, c := range db.celestials[systemID] {
+		if c.GroupID == groupID {
+			rows = append(rows, c)
+		}
+	}
+	return rows, nil
+}
+
+func (db *memoryDb) StargatesForSystem(systemID int) ([]evego.Stargate, error) {
+	rows, err := db.celestialsInGroup(systemID, groupStargate)
+	if err != nil {
+		return nil, err
+	}
+	return stargates(rows), nil
+}
+
+func (db *memoryDb) PlanetsForSystem(systemID int) ([]evego.Planet, error) {
+	rows, err := db.celestialsInGroup(systemID, groupPlanet)
+	if err != nil {
+		return nil, err
+	}
+	return planets(rows), nil
+}
+
+func (db *memoryDb) MoonsForSystem(systemID int) ([]evego.Moon, error) {
+	rows, err := db.celestialsInGroup(systemID, groupMoon)
+	if err != nil {
+		return nil, err
+	}
+	return moons(rows), nil
+}
+
+func (db *memoryDb) AsteroidBeltsForSystem(systemID int) ([]evego.AsteroidBelt, error) {
+	rows, err := db.celestialsInGroup(systemID, groupAsteroidBelt)
+	if err != nil {
+		return nil, err
+	}
+	return asteroidBelts(rows), nil
+}
+
+// regionCelestials returns the objects of one inventory group in a region,
+// in item ID order.
+func (db *memoryDb) regionCelestials(regionID, groupID int) ([]snapCelestial, error) {
+	if _, ok := db.regions[regionID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	var rows []snapCelestial
+	for systemID, celestials := range db.celestials {
+		s, ok := db.systemsByID[systemID]
+		if !ok {
+			continue
+		}
+		if c, ok := db.constellations[s.ConstellationID]; !ok || c.RegionID != regionID {
+			continue
+		}
+		for _, c := range celestials {
+			if c.GroupID == groupID {
+				rows = append(rows, c)
+			}
+		}
+	}
+	sort.Sort(celestialsByID(rows))
+	return rows, nil
+}
+
+func (db *memoryDb) StargatesForRegion(regionID int) ([]evego.Stargate, error) {
+	rows, err := db.regionCelestials(regionID, groupStargate)
+	if err != nil {
+		return nil, err
+	}
+	return stargates(rows), nil
+}
+
+func (db *memoryDb) PlanetsForRegion(regionID int) ([]evego.Planet, error) {
+	rows, err := db.regionCelestials(regionID, groupPlanet)
+	if err != nil {
+		return nil, err
+	}
+	return planets(rows), nil
+}
+
+func (db *memoryDb) MoonsForRegion(regionID int) ([]evego.Moon, error) {
+	rows, err := db.regionCelestials(regionID, groupMoon)
+	if err != nil {
+		return nil, err
+	}
+	return moons(rows), nil
+}
+
+func (db *memoryDb) AsteroidBeltsForRegion(regionID int) ([]evego.AsteroidBelt, error) {
+	rows, err := db.regionCelestials(regionID, groupAsteroidBelt)
+	if err != nil {
+		return nil, err
+	}
+	return asteroidBelts(rows), nil
+}
+
+// typeName returns the name of a type, or "" if it doesn't exist.
+func (db *memoryDb) typeName(typeID int) string {
+	if t, ok := db.types[typeID]; ok {
+		return t.Name
+	}
+	return ""
+}
+
+// productRows is a list of rows from industryActivityProducts, which sorts by
+// input and output name as in blueprintBase.
+type productRows struct {
+	db   *memoryDb
+	rows []snapIndustryMaterial
+}
+
+func (p productRows) Len() int      { return len(p.rows) }
+func (p productRows) Swap(i, j int) { p.rows[i], p.rows[j] = p.rows[j], p.rows[i] }
+func (p productRows) Less(i, j int) bool {
+	inI, inJ := p.db.typeName(p.rows[i].TypeID), p.db.typeName(p.rows[j].TypeID)
+	if inI != inJ {
+		return inI < inJ
+	}
+	return p.db.typeName(p.rows[i].MaterialTypeID) < p.db.typeName(p.rows[j].MaterialTypeID)
+}
+
+// blueprintQuery returns the industry activities for the given rows of
+// industryActivityProducts.
+func (db *memoryDb) blueprintQuery(rows []snapIndustryMaterial) ([]evego.IndustryActivity, error) {
+	sort.Stable(productRows{db: db, rows: rows})
+	var results []evego.IndustryActivity
+	for _, row := range rows {
+		activityName, ok := db.activities[row.ActivityID]
+		if !ok {
+			continue
+		}
+		input, err := db.ItemForID(row.TypeID)
+		if err != nil {
+			return nil, err
+		}
+		output, err := db.ItemForID(row.MaterialTypeID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, evego.IndustryActivity{
+			InputItem:      input,
+			ActivityType:   activityToTypeCode(activityName),
+			OutputItem:     output,
+			OutputQuantity: row.Quantity,
+		})
+	}
+	return results, nil
+}
+
+func (db *memoryDb) BlueprintOutputs(typeName string) ([]evego.IndustryActivity, error) {
+	match := db.like(typeName)
+	var rows []snapIndustryMaterial
+	for _, p := range db.products {
+		if match(db.typeName(p.TypeID)) {
+			rows = append(rows, p)
+		}
+	}
+	return db.blueprintQuery(rows)
+}
+
+func (db *memoryDb) BlueprintForProduct(typeName string) ([]evego.IndustryActivity, error) {
+	match := db.like(typeName)
+	var rows []snapIndustryMaterial
+	for _, p := range db.products {
+		if match(db.typeName(p.MaterialTypeID)) {
+			rows = append(rows, p)
+		}
+	}
+	return db.blueprintQuery(rows)
+}
+
+func (db *memoryDb) BlueprintsUsingMaterial(typeName string) ([]evego.IndustryActivity, error) {
+	match := db.like(typeName)
+	var rows []snapIndustryMaterial
+	for _, p := range db.products {
+		// As in the SQL query, each product is listed once for every matching
+		// material used by any of the blueprint's activities.
+		for _, m := range db.industryMats[p.TypeID] {
+			if match(db.typeName(m.MaterialTypeID)) {
+				rows = append(rows, p)
+			}
+		}
+	}
+	return db.blueprintQuery(rows)
+}
+
+func (db *memoryDb) BlueprintsInputs(typeIDs []int) (map[evego.BlueprintActivity][]evego.InventoryLine, error) {
+	results := make(map[evego.BlueprintActivity][]evego.InventoryLine)
+	for _, id := range typeIDs {
+		// Sort by material name, as in blueprintsInputs.
+		mats := append([]snapIndustryMaterial(nil), db.industryMats[id]...)
+		sort.Stable(productRows{db: db, rows: mats})
+		for _, m := range mats {
+			activityName, ok := db.activities[m.ActivityID]
+			if !ok {
+				continue
+			}
+			item, err := db.ItemForID(m.MaterialTypeID)
+			if err != nil {
+				return nil, inconsistent("input material %v of %v not available",
+					m.MaterialTypeID, id)
+			}
+			key := evego.BlueprintActivity{BlueprintID: id, Activity: activityToTypeCode(activityName)}
+			results[key] = append(results[key], evego.InventoryLine{Quantity: m.Quantity, Item: item})
+		}
+	}
+	return results, nil
+}
+
+func (db *memoryDb) BlueprintProductionInputs(
+	typeName string, outputTypeName string) ([]evego.InventoryLine, error) {
+	bp, ok := db.typesByName[typeName]
+	if !ok {
+		return nil, nil
+	}
+	var mats []snapIndustryMaterial
+	for _, p := range db.industryProds[bp.ID] {
+		if db.typeName(p.MaterialTypeID) != outputTypeName {
+			continue
+		}
+		for _, m := range db.industryMats[bp.ID] {
+			if m.ActivityID == p.ActivityID {
+				mats = append(mats, m)
+			}
+		}
+	}
+	// Sort by material name, as in materialsForBlueprintProduction.
+	sort.Stable(productRows{db: db, rows: mats})
+	var results []evego.InventoryLine
+	for _, m := range mats {
+		item, err := db.ItemForID(m.MaterialTypeID)
+		if err != nil {
+			return nil, inconsistent("input material %v of %v not available",
+				m.MaterialTypeID, typeName)
+		}
+		results = append(results, evego.InventoryLine{Quantity: m.Quantity, Item: item})
+	}
+	return results, nil
+}
+
+// reactionMaterials returns the items and quantities of the given rows that
+// are part of a reaction.
+func (db *memoryDb) reactionMaterials(rows []snapIndustryMaterial) ([]evego.InventoryLine, error) {
+	var results []evego.InventoryLine
+	for _, row := range rows {
+		if row.ActivityID != reactionActivityID {
+			continue
+		}
+		item, err := db.ItemForID(row.MaterialTypeID)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("reaction formula %d has nonexistent material %d",
+				row.TypeID, row.MaterialTypeID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, evego.InventoryLine{Quantity: row.Quantity, Item: item})
+	}
+	sort.Sort(inventoryByID(results))
+	return results, nil
+}
+
+type inventoryByID []evego.InventoryLine
+
+func (l inventoryByID) Len() int           { return len(l) }
+func (l inventoryByID) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l inventoryByID) Less(i, j int) bool { return l[i].Item.ID < l[j].Item.ID }
+
+// reactionQuery returns the reactions whose formula or one of whose products
+// (depending on byProduct) matches the pattern.
+func (db *memoryDb) reactionQuery(pattern string, byProduct bool) ([]evego.Reaction, error) {
+	match := db.like(pattern)
+	var results []evego.Reaction
+	for _, a := range db.industry {
+		if a.ActivityID != reactionActivityID {
+			continue
+		}
+		matched := false
+		for _, p := range db.industryProds[a.TypeID] {
+			if p.ActivityID != reactionActivityID {
+				continue
+			}
+			if byProduct {
+				matched = matched || match(db.typeName(p.MaterialTypeID))
+			} else {
+				matched = match(db.typeName(a.TypeID))
+			}
+		}
+		if !matched {
+			continue
+		}
+		formula, err := db.ItemForID(a.TypeID)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("reaction formula %d doesn't exist", a.TypeID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		inputs, err := db.reactionMaterials(db.industryMats[a.TypeID])
+		if err != nil {
+			return nil, err
+		}
+		outputs, err := db.reactionMaterials(db.industryProds[a.TypeID])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, evego.Reaction{
+			Formula: formula,
+			Inputs:  inputs,
+			Outputs: outputs,
+			Time:    time.Duration(a.Seconds) * time.Second,
+		})
+	}
+	if len(results) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return results, nil
+}
+
+func (db *memoryDb) ReactionFormulas(typeName string) ([]evego.Reaction, error) {
+	return db.reactionQuery(typeName, false)
+}
+
+func (db *memoryDb) ReactionsForProduct(typeName string) ([]evego.Reaction, error) {
+	return db.reactionQuery(typeName, true)
+}
+
+func (db *memoryDb) ReprocessOutputMaterials() ([]evego.Item, error) {
+	seen := make(map[int]bool)
+	var items []evego.Item
+	for _, m := range db.typeMaterials {
+		for _, mat := range m {
+			product, ok := db.types[mat.TypeID]
+			if !ok || product.MarketGroupID == 0 || seen[mat.MaterialTypeID] {
+				continue
+			}
+			seen[mat.MaterialTypeID] = true
+			item, err := db.ItemForID(mat.MaterialTypeID)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, *item)
+		}
+	}
+	sort.Sort(itemsByName(items))
+	return items, nil
+}
+
+type itemsByName []evego.Item
+
+func (l itemsByName) Len() int      { return len(l) }
+func (l itemsByName) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l itemsByName) Less(i, j int) bool {
+	if l[i].Name != l[j].Name {
+		return l[i].Name < l[j].Name
+	}
+	return l[i].ID < l[j].ID
+}
+
+func (db *memoryDb) AttributeForID(attributeID int) (*evego.DogmaAttribute, error) {
+	attr, ok := db.attributes[attributeID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *attr
+	return &result, nil
+}
+
+func (db *memoryDb) AttributeForName(attributeName string) (*evego.DogmaAttribute, error) {
+	attr, ok := db.attrsByName[attributeName]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *attr
+	return &result, nil
+}
+
+func (db *memoryDb) ItemAttributes(typeID int) ([]evego.ItemAttribute, error) {
+	attrs, err := db.ItemsAttributes([]int{typeID})
+	if err != nil {
+		return nil, err
+	}
+	return attrs[typeID], nil
+}
+
+func (db *memoryDb) ItemsAttributes(typeIDs []int) (map[int][]evego.ItemAttribute, error) {
+	results := make(map[int][]evego.ItemAttribute)
+	for _, id := range typeIDs {
+		if attrs, ok := db.typeAttributes[id]; ok {
+			results[id] = append([]evego.ItemAttribute(nil), attrs...)
+		}
+	}
+	return results, nil
+}
+
+func (db *memoryDb) ItemAttributeValue(typeID, attributeID int) (float64, error) {
+	for _, a := range db.typeAttributes[typeID] {
+		if a.AttributeID == attributeID {
+			return a.Value, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (db *memoryDb) ItemAttributeValueForName(typeID int, attributeName string) (float64, error) {
+	for _, a := range db.typeAttributes[typeID] {
+		if a.Name == attributeName {
+			return a.Value, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (db *memoryDb) ItemEffects(typeID int) ([]evego.ItemEffect, error) {
+	effects, err := db.ItemsEffects([]int{typeID})
+	if err != nil {
+		return nil, err
+	}
+	return effects[typeID], nil
+}
+
+func (db *memoryDb) ItemsEffects(typeIDs []int) (map[int][]evego.ItemEffect, error) {
+	results := make(map[int][]evego.ItemEffect)
+	for _, id := range typeIDs {
+		if effects, ok := db.typeEffects[id]; ok {
+			results[id] = append([]evego.ItemEffect(nil), effects...)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,399 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"context"
+
+	"github.com/backerman/evego"
+)
+
+// Nothing a memoryDb does blocks, so its Context methods only check that the
+// context hasn't already been canceled before doing the work.
+
+func (db *memoryDb) ItemForNameContext(ctx context.Context, itemName string) (*evego.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemForName(itemName)
+}
+
+func (db *memoryDb) ItemForIDContext(ctx context.Context, itemID int) (*evego.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemForID(itemID)
+}
+
+func (db *memoryDb) ItemsForIDsContext(ctx context.Context, itemIDs []int) ([]*evego.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemsForIDs(itemIDs)
+}
+
+func (db *memoryDb) ItemCompositionContext(ctx context.Context, itemID int) ([]evego.InventoryLine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemComposition(itemID)
+}
+
+func (db *memoryDb) ItemsCompositionContext(ctx context.Context, itemIDs []int) (map[int][]evego.InventoryLine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemsComposition(itemIDs)
+}
+
+func (db *memoryDb) MarketGroupForItemContext(ctx context.Context, item *evego.Item) (*evego.MarketGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.MarketGroupForItem(item)
+}
+
+func (db *memoryDb) MarketGroupChildrenContext(ctx context.Context, groupID int) ([]evego.MarketGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.MarketGroupChildren(groupID)
+}
+
+func (db *memoryDb) ItemsInMarketGroupContext(ctx context.Context, groupID int, recursive bool) ([]*evego.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemsInMarketGroup(groupID, recursive)
+}
+
+func (db *memoryDb) MarketTreeContext(ctx context.Context) ([]*evego.MarketGroupNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.MarketTree()
+}
+
+func (db *memoryDb) ItemIDsContext(ctx context.Context) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemIDs()
+}
+
+func (db *memoryDb) SearchItemsContext(ctx context.Context, query string, limit int) ([]evego.ItemMatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SearchItems(query, limit)
+}
+
+func (db *memoryDb) SolarSystemForIDContext(ctx context.Context, systemID int) (*evego.SolarSystem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SolarSystemForID(systemID)
+}
+
+func (db *memoryDb) SolarSystemForNameContext(ctx context.Context, systemName string) (*evego.SolarSystem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SolarSystemForName(systemName)
+}
+
+func (db *memoryDb) SolarSystemsForPatternContext(ctx context.Context, systemName string) ([]evego.SolarSystem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SolarSystemsForPattern(systemName)
+}
+
+func (db *memoryDb) RegionForNameContext(ctx context.Context, regionName string) (*evego.Region, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.RegionForName(regionName)
+}
+
+func (db *memoryDb) StationForIDContext(ctx context.Context, stationID int) (*evego.Station, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.StationForID(stationID)
+}
+
+func (db *memoryDb) StationsForNameContext(ctx context.Context, stationName string) ([]evego.Station, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.StationsForName(stationName)
+}
+
+func (db *memoryDb) ConstellationForIDContext(ctx context.Context, constellationID int) (*evego.Constellation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ConstellationForID(constellationID)
+}
+
+func (db *memoryDb) ConstellationsForRegionContext(ctx context.Context, regionID int) ([]evego.Constellation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ConstellationsForRegion(regionID)
+}
+
+func (db *memoryDb) SolarSystemsForConstellationContext(ctx context.Context, constellationID int) ([]evego.SolarSystem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SolarSystemsForConstellation(constellationID)
+}
+
+func (db *memoryDb) SolarSystemsForRegionContext(ctx context.Context, regionID int) ([]evego.SolarSystem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SolarSystemsForRegion(regionID)
+}
+
+func (db *memoryDb) SolarSystemNeighborsContext(ctx context.Context, systemID int) ([]evego.SolarSystem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.SolarSystemNeighbors(systemID)
+}
+
+func (db *memoryDb) StargatesForSystemContext(ctx context.Context, systemID int) ([]evego.Stargate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.StargatesForSystem(systemID)
+}
+
+func (db *memoryDb) PlanetsForSystemContext(ctx context.Context, systemID int) ([]evego.Planet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.PlanetsForSystem(systemID)
+}
+
+func (db *memoryDb) MoonsForSystemContext(ctx context.Context, systemID int) ([]evego.Moon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.MoonsForSystem(systemID)
+}
+
+func (db *memoryDb) AsteroidBeltsForSystemContext(ctx context.Context, systemID int) ([]evego.AsteroidBelt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AsteroidBeltsForSystem(systemID)
+}
+
+func (db *memoryDb) StargatesForRegionContext(ctx context.Context, regionID int) ([]evego.Stargate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.StargatesForRegion(regionID)
+}
+
+func (db *memoryDb) PlanetsForRegionContext(ctx context.Context, regionID int) ([]evego.Planet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.PlanetsForRegion(regionID)
+}
+
+func (db *memoryDb) MoonsForRegionContext(ctx context.Context, regionID int) ([]evego.Moon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.MoonsForRegion(regionID)
+}
+
+func (db *memoryDb) AsteroidBeltsForRegionContext(ctx context.Context, regionID int) ([]evego.AsteroidBelt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AsteroidBeltsForRegion(regionID)
+}
+
+func (db *memoryDb) FactionForIDContext(ctx context.Context, factionID int) (*evego.Faction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.FactionForID(factionID)
+}
+
+func (db *memoryDb) FactionsContext(ctx context.Context) ([]evego.Faction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.Factions()
+}
+
+func (db *memoryDb) NPCCorporationForIDContext(ctx context.Context, corporationID int) (*evego.NPCCorp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.NPCCorporationForID(corporationID)
+}
+
+func (db *memoryDb) NPCCorporationsForFactionContext(ctx context.Context, factionID int) ([]evego.NPCCorp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.NPCCorporationsForFaction(factionID)
+}
+
+func (db *memoryDb) AgentForIDContext(ctx context.Context, agentID int) (*evego.Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AgentForID(agentID)
+}
+
+func (db *memoryDb) AgentsForCorporationContext(ctx context.Context, corporationID int) ([]evego.Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AgentsForCorporation(corporationID)
+}
+
+func (db *memoryDb) AgentsForStationContext(ctx context.Context, stationID int) ([]evego.Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AgentsForStation(stationID)
+}
+
+func (db *memoryDb) BlueprintOutputsContext(ctx context.Context, typeName string) ([]evego.IndustryActivity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.BlueprintOutputs(typeName)
+}
+
+func (db *memoryDb) BlueprintForProductContext(ctx context.Context, typeName string) ([]evego.IndustryActivity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.BlueprintForProduct(typeName)
+}
+
+func (db *memoryDb) BlueprintsUsingMaterialContext(ctx context.Context, typeName string) ([]evego.IndustryActivity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.BlueprintsUsingMaterial(typeName)
+}
+
+func (db *memoryDb) BlueprintsInputsContext(ctx context.Context, typeIDs []int) (map[evego.BlueprintActivity][]evego.InventoryLine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.BlueprintsInputs(typeIDs)
+}
+
+func (db *memoryDb) BlueprintProductionInputsContext(ctx context.Context,
+	typeName string, outputTypeName string) ([]evego.InventoryLine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.BlueprintProductionInputs(typeName, outputTypeName)
+}
+
+func (db *memoryDb) ReactionFormulasContext(ctx context.Context, typeName string) ([]evego.Reaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ReactionFormulas(typeName)
+}
+
+func (db *memoryDb) ReactionsForProductContext(ctx context.Context, typeName string) ([]evego.Reaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ReactionsForProduct(typeName)
+}
+
+func (db *memoryDb) AttributeForIDContext(ctx context.Context, attributeID int) (*evego.DogmaAttribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AttributeForID(attributeID)
+}
+
+func (db *memoryDb) AttributeForNameContext(ctx context.Context, attributeName string) (*evego.DogmaAttribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.AttributeForName(attributeName)
+}
+
+func (db *memoryDb) ItemAttributesContext(ctx context.Context, typeID int) ([]evego.ItemAttribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemAttributes(typeID)
+}
+
+func (db *memoryDb) ItemsAttributesContext(ctx context.Context, typeIDs []int) (map[int][]evego.ItemAttribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemsAttributes(typeIDs)
+}
+
+func (db *memoryDb) ItemAttributeValueContext(ctx context.Context, typeID, attributeID int) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return db.ItemAttributeValue(typeID, attributeID)
+}
+
+func (db *memoryDb) ItemAttributeValueForNameContext(ctx context.Context, typeID int, attributeName string) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return db.ItemAttributeValueForName(typeID, attributeName)
+}
+
+func (db *memoryDb) ItemEffectsContext(ctx context.Context, typeID int) ([]evego.ItemEffect, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemEffects(typeID)
+}
+
+func (db *memoryDb) ItemsEffectsContext(ctx context.Context, typeIDs []int) (map[int][]evego.ItemEffect, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ItemsEffects(typeIDs)
+}
+
+func (db *memoryDb) ReprocessOutputMaterialsContext(ctx context.Context) ([]evego.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.ReprocessOutputMaterials()
+}
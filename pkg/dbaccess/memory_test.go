@@ -0,0 +1,153 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess_test
+
+import (
+	"testing"
+
+	"github.com/backerman/evego/pkg/dbaccess"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMemoryDatabase(t *testing.T) {
+	Convey("Given a snapshot of the test database", t, func() {
+		sqlDb, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer sqlDb.Close()
+		snap, err := testSnapshot()
+		So(err, ShouldBeNil)
+		memDb := dbaccess.MemoryDatabase(snap)
+		defer memDb.Close()
+
+		Convey("Items are the same in memory.", func() {
+			for _, name := range []string{"Vexor", "Tritanium", "Scordite", "Gallente Frigate"} {
+				expected, err := sqlDb.ItemForName(name)
+				So(err, ShouldBeNil)
+				actual, err := memDb.ItemForName(name)
+				So(err, ShouldBeNil)
+				So(actual, ShouldResemble, expected)
+
+				expectedComp, err := sqlDb.ItemComposition(expected.ID)
+				So(err, ShouldBeNil)
+				actualComp, err := memDb.ItemComposition(expected.ID)
+				So(err, ShouldBeNil)
+				So(actualComp, ShouldResemble, expectedComp)
+			}
+		})
+
+		Convey("Wildcard patterns match the same systems and stations.", func() {
+			expectedSystems, err := sqlDb.SolarSystemsForPattern("pol%")
+			So(err, ShouldBeNil)
+			actualSystems, err := memDb.SolarSystemsForPattern("pol%")
+			So(err, ShouldBeNil)
+			So(actualSystems, shouldMatchSystems, expectedSystems)
+
+			expectedSystems, err = sqlDb.SolarSystemsForPattern("POL%")
+			So(err, ShouldBeNil)
+			actualSystems, err = memDb.SolarSystemsForPattern("POL%")
+			So(err, ShouldBeNil)
+			So(actualSystems, shouldMatchSystems, expectedSystems)
+
+			expectedStations, err := sqlDb.StationsForName("%Moon 5%")
+			So(err, ShouldBeNil)
+			actualStations, err := memDb.StationsForName("%Moon 5%")
+			So(err, ShouldBeNil)
+			So(actualStations, ShouldResemble, expectedStations)
+		})
+
+		Convey("Blueprints produce the same results.", func() {
+			expected, err := sqlDb.BlueprintOutputs("Vexor%")
+			So(err, ShouldBeNil)
+			actual, err := memDb.BlueprintOutputs("Vexor%")
+			So(err, ShouldBeNil)
+			So(actual, shouldMatchActivities, expected)
+
+			// Whether the pattern ignores case depends on the SQL database.
+			expected, err = sqlDb.BlueprintOutputs("vEXOR%")
+			So(err, ShouldBeNil)
+			actual, err = memDb.BlueprintOutputs("vEXOR%")
+			So(err, ShouldBeNil)
+			So(actual, shouldMatchActivities, expected)
+
+			expectedInputs, err := sqlDb.BlueprintProductionInputs("Vexor Blueprint", "Vexor")
+			So(err, ShouldBeNil)
+			actualInputs, err := memDb.BlueprintProductionInputs("Vexor Blueprint", "Vexor")
+			So(err, ShouldBeNil)
+			So(actualInputs, ShouldResemble, expectedInputs)
+
+			vexor, err := sqlDb.ItemForName("Vexor Blueprint")
+			So(err, ShouldBeNil)
+			expectedBulk, err := sqlDb.BlueprintsInputs([]int{vexor.ID})
+			So(err, ShouldBeNil)
+			actualBulk, err := memDb.BlueprintsInputs([]int{vexor.ID})
+			So(err, ShouldBeNil)
+			So(actualBulk, ShouldResemble, expectedBulk)
+		})
+
+		Convey("The market tree is the same.", func() {
+			expected, err := sqlDb.MarketTree()
+			So(err, ShouldBeNil)
+			actual, err := memDb.MarketTree()
+			So(err, ShouldBeNil)
+			So(actual, ShouldResemble, expected)
+		})
+
+		Convey("Solar systems have the same neighbours and objects.", func() {
+			system, err := sqlDb.SolarSystemForName("Dodixie")
+			So(err, ShouldBeNil)
+			expectedNeighbors, err := sqlDb.SolarSystemNeighbors(system.ID)
+			So(err, ShouldBeNil)
+			actualNeighbors, err := memDb.SolarSystemNeighbors(system.ID)
+			So(err, ShouldBeNil)
+			So(actualNeighbors, ShouldResemble, expectedNeighbors)
+
+			expectedGates, err := sqlDb.StargatesForSystem(system.ID)
+			So(err, ShouldBeNil)
+			actualGates, err := memDb.StargatesForSystem(system.ID)
+			So(err, ShouldBeNil)
+			So(actualGates, ShouldResemble, expectedGates)
+
+			expectedPlanets, err := sqlDb.PlanetsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			actualPlanets, err := memDb.PlanetsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			So(actualPlanets, ShouldResemble, expectedPlanets)
+
+			expectedMoons, err := sqlDb.MoonsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			actualMoons, err := memDb.MoonsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			So(actualMoons, ShouldResemble, expectedMoons)
+		})
+
+		Convey("Factions, corporations, and agents are the same.", func() {
+			expectedFactions, err := sqlDb.Factions()
+			So(err, ShouldBeNil)
+			actualFactions, err := memDb.Factions()
+			So(err, ShouldBeNil)
+			So(actualFactions, ShouldResemble, expectedFactions)
+
+			expectedAgents, err := sqlDb.AgentsForCorporation(1000102)
+			So(err, ShouldBeNil)
+			actualAgents, err := memDb.AgentsForCorporation(1000102)
+			So(err, ShouldBeNil)
+			So(actualAgents, ShouldResemble, expectedAgents)
+		})
+	})
+}
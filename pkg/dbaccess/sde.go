@@ -0,0 +1,882 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+// sdeActivities maps the activities in the official SDE's blueprints to their
+// IDs and names in the SQL conversions' ramActivities table.
+var sdeActivities = []struct {
+	key  string
+	id   int
+	name string
+}{
+	{"manufacturing", 1, "Manufacturing"},
+	{"research_time", 3, "Researching Time Efficiency"},
+	{"research_material", 4, "Researching Material Efficiency"},
+	{"copying", 5, "Copying"},
+	{"invention", 8, "Invention"},
+	{"reaction", 11, "Reactions"},
+}
+
+// sdeText is a localized string in the official SDE, which is an object keyed
+// by language. Older dumps have a plain string instead. Only the English text
+// is kept.
+type sdeText string
+
+func (t *sdeText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = sdeText(s)
+		return nil
+	}
+	var localized map[string]string
+	if err := json.Unmarshal(data, &localized); err != nil {
+		return err
+	}
+	*t = sdeText(localized["en"])
+	return nil
+}
+
+type sdeType struct {
+	ID            int     `json:"_key"`
+	Name          sdeText `json:"name"`
+	GroupID       int     `json:"groupID"`
+	PortionSize   *int    `json:"portionSize"`
+	Volume        float64 `json:"volume"`
+	Capacity      float64 `json:"capacity"`
+	Mass          float64 `json:"mass"`
+	BasePrice     float64 `json:"basePrice"`
+	MarketGroupID int     `json:"marketGroupID"`
+	Published     bool    `json:"published"`
+}
+
+type sdeGroup struct {
+	ID         int     `json:"_key"`
+	Name       sdeText `json:"name"`
+	CategoryID int     `json:"categoryID"`
+}
+
+type sdeCategory struct {
+	ID   int     `json:"_key"`
+	Name sdeText `json:"name"`
+}
+
+type sdeMarketGroup struct {
+	ID            int     `json:"_key"`
+	ParentGroupID int     `json:"parentGroupID"`
+	Name          sdeText `json:"name"`
+	Description   sdeText `json:"description"`
+}
+
+type sdeQuantity struct {
+	TypeID         int `json:"typeID"`
+	MaterialTypeID int `json:"materialTypeID"`
+	Quantity       int `json:"quantity"`
+}
+
+type sdeTypeMaterials struct {
+	ID        int           `json:"_key"`
+	Materials []sdeQuantity `json:"materials"`
+}
+
+type sdeRegion struct {
+	ID   int     `json:"_key"`
+	Name sdeText `json:"name"`
+}
+
+type sdeConstellation struct {
+	ID       int            `json:"_key"`
+	Name     sdeText        `json:"name"`
+	RegionID int            `json:"regionID"`
+	Position evego.Position `json:"position"`
+}
+
+type sdeSolarSystem struct {
+	ID              int     `json:"_key"`
+	Name            sdeText `json:"name"`
+	ConstellationID int     `json:"constellationID"`
+	RegionID        int     `json:"regionID"`
+	SecurityStatus  float64 `json:"securityStatus"`
+}
+
+// sdeCelestial is a planet, moon, asteroid belt, or stargate.
+type sdeCelestial struct {
+	ID             int            `json:"_key"`
+	SolarSystemID  int            `json:"solarSystemID"`
+	TypeID         int            `json:"typeID"`
+	OrbitID        int            `json:"orbitID"`
+	CelestialIndex int            `json:"celestialIndex"`
+	OrbitIndex     int            `json:"orbitIndex"`
+	Position       evego.Position `json:"position"`
+	Destination    struct {
+		SolarSystemID int `json:"solarSystemID"`
+		StargateID    int `json:"stargateID"`
+	} `json:"destination"`
+}
+
+type sdeStation struct {
+	ID                     int     `json:"_key"`
+	SolarSystemID          int     `json:"solarSystemID"`
+	OrbitID                int     `json:"orbitID"`
+	OwnerID                int     `json:"ownerID"`
+	OperationID            int     `json:"operationID"`
+	UseOperationName       bool    `json:"useOperationName"`
+	ReprocessingEfficiency float64 `json:"reprocessingEfficiency"`
+}
+
+type sdeOperation struct {
+	ID   int     `json:"_key"`
+	Name sdeText `json:"operationName"`
+}
+
+type sdeFaction struct {
+	ID                   int     `json:"_key"`
+	Name                 sdeText `json:"name"`
+	Description          sdeText `json:"description"`
+	SolarSystemID        int     `json:"solarSystemID"`
+	CorporationID        int     `json:"corporationID"`
+	MilitiaCorporationID int     `json:"militiaCorporationID"`
+}
+
+type sdeCorporation struct {
+	ID            int     `json:"_key"`
+	Name          sdeText `json:"name"`
+	FactionID     int     `json:"factionID"`
+	SolarSystemID int     `json:"solarSystemID"`
+}
+
+type sdeDivision struct {
+	ID   int     `json:"_key"`
+	Name sdeText `json:"name"`
+}
+
+type sdeCharacter struct {
+	ID            int     `json:"_key"`
+	Name          sdeText `json:"name"`
+	CorporationID int     `json:"corporationID"`
+	LocationID    int     `json:"locationID"`
+	Agent         *struct {
+		AgentTypeID int `json:"agentTypeID"`
+		DivisionID  int `json:"divisionID"`
+		Level       int `json:"level"`
+	} `json:"agent"`
+}
+
+type sdeAgentInSpace struct {
+	ID            int `json:"_key"`
+	SolarSystemID int `json:"solarSystemID"`
+}
+
+type sdeBlueprint struct {
+	ID         int `json:"_key"`
+	Activities map[string]struct {
+		Time      int           `json:"time"`
+		Materials []sdeQuantity `json:"materials"`
+		Products  []sdeQuantity `json:"products"`
+	} `json:"activities"`
+}
+
+type sdeAttribute struct {
+	ID           int     `json:"_key"`
+	Name         string  `json:"name"`
+	DisplayName  sdeText `json:"displayName"`
+	Description  sdeText `json:"description"`
+	DefaultValue float64 `json:"defaultValue"`
+	UnitID       int     `json:"unitID"`
+	Published    bool    `json:"published"`
+	Stackable    bool    `json:"stackable"`
+	HighIsGood   bool    `json:"highIsGood"`
+}
+
+// sdeEffect is a dogma effect. The official SDE and the older YAML dumps
+// name its fields differently.
+type sdeEffect struct {
+	ID               int    `json:"_key"`
+	Name             string `json:"name"`
+	EffectName       string `json:"effectName"`
+	EffectCategoryID int    `json:"effectCategoryID"`
+	EffectCategory   int    `json:"effectCategory"`
+}
+
+type sdeTypeDogma struct {
+	ID              int `json:"_key"`
+	DogmaAttributes []struct {
+		AttributeID int     `json:"attributeID"`
+		Value       float64 `json:"value"`
+	} `json:"dogmaAttributes"`
+	DogmaEffects []struct {
+		EffectID  int  `json:"effectID"`
+		IsDefault bool `json:"isDefault"`
+	} `json:"dogmaEffects"`
+}
+
+// readSDEFile decodes each row of one of the dump's JSONL files, calling
+// each to decode and store it. Files that are optional may be missing.
+func readSDEFile(dir, name string, optional bool, each func(dec *json.Decoder) error) error {
+	f, err := os.Open(filepath.Join(dir, name+".jsonl"))
+	if os.IsNotExist(err) && optional {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to read SDE: %v", err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for row := 1; dec.More(); row++ {
+		if err := each(dec); err != nil {
+			return fmt.Errorf("Unable to read row %d of %s: %v", row, name, err)
+		}
+	}
+	return nil
+}
+
+// romanNumeral returns n in Roman numerals, as used in the names of planets.
+func romanNumeral(n int) string {
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"},
+		{50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+	var b strings.Builder
+	for _, r := range numerals {
+		for ; n >= r.value; n -= r.value {
+			b.WriteString(r.symbol)
+		}
+	}
+	return b.String()
+}
+
+// sdeReader holds the tables of the dump that are needed to fill in others.
+type sdeReader struct {
+	dir        string
+	data       *snapshotData
+	typeNames  map[int]string
+	systems    map[int]*snapSolarSystem
+	celestials map[int]string // names, by item ID
+	corpNames  map[int]string
+}
+
+// SnapshotFromSDE loads a snapshot from the JSONL files of the official
+// static data export, extracted into dir, without needing a SQL conversion.
+//
+// The official export doesn't include the names of stations and celestials;
+// they're put together in the same way as the game and the SQL conversions
+// do. It also has no packaged volumes, so items' packaged volume is the same
+// as their volume.
+func SnapshotFromSDE(dir string) (*Snapshot, error) {
+	snap := &Snapshot{}
+	r := &sdeReader{
+		dir:        dir,
+		data:       &snap.data,
+		typeNames:  make(map[int]string),
+		systems:    make(map[int]*snapSolarSystem),
+		celestials: make(map[int]string),
+		corpNames:  make(map[int]string),
+	}
+	steps := []func() error{
+		r.readTypes,
+		r.readMap,
+		r.readCelestials,
+		r.readNPCs,
+		r.readStations,
+		r.readIndustry,
+		r.readDogma,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// readTypes reads item types, their groups and categories, the market groups,
+// and reprocessing materials.
+func (r *sdeReader) readTypes() error {
+	data := r.data
+	err := readSDEFile(r.dir, "types", false, func(dec *json.Decoder) error {
+		var row sdeType
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		portionSize := 1
+		if row.PortionSize != nil {
+			portionSize = *row.PortionSize
+		}
+		data.Types = append(data.Types, snapType{
+			ID:             row.ID,
+			Name:           string(row.Name),
+			GroupID:        row.GroupID,
+			PortionSize:    portionSize,
+			Volume:         row.Volume,
+			PackagedVolume: row.Volume,
+			Capacity:       row.Capacity,
+			Mass:           row.Mass,
+			BasePrice:      row.BasePrice,
+			MarketGroupID:  row.MarketGroupID,
+			Published:      row.Published,
+		})
+		r.typeNames[row.ID] = string(row.Name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.Types, func(i, j int) bool { return data.Types[i].ID < data.Types[j].ID })
+
+	err = readSDEFile(r.dir, "groups", false, func(dec *json.Decoder) error {
+		var row sdeGroup
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.Groups = append(data.Groups, snapGroup{ID: row.ID, Name: string(row.Name), CategoryID: row.CategoryID})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "categories", false, func(dec *json.Decoder) error {
+		var row sdeCategory
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.Categories = append(data.Categories, snapCategory{ID: row.ID, Name: string(row.Name)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "marketGroups", false, func(dec *json.Decoder) error {
+		var row sdeMarketGroup
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.MarketGroups = append(data.MarketGroups, snapMarketGroup{
+			ID:          row.ID,
+			ParentID:    row.ParentGroupID,
+			Name:        string(row.Name),
+			Description: string(row.Description),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "typeMaterials", false, func(dec *json.Decoder) error {
+		var row sdeTypeMaterials
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		for _, m := range row.Materials {
+			data.TypeMaterials = append(data.TypeMaterials, snapMaterial{
+				TypeID:         row.ID,
+				MaterialTypeID: m.MaterialTypeID,
+				Quantity:       m.Quantity,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.TypeMaterials, func(i, j int) bool {
+		a, b := data.TypeMaterials[i], data.TypeMaterials[j]
+		if a.TypeID != b.TypeID {
+			return a.TypeID < b.TypeID
+		}
+		return a.MaterialTypeID < b.MaterialTypeID
+	})
+	return nil
+}
+
+// readMap reads regions, constellations, and solar systems.
+func (r *sdeReader) readMap() error {
+	data := r.data
+	err := readSDEFile(r.dir, "mapRegions", false, func(dec *json.Decoder) error {
+		var row sdeRegion
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.Regions = append(data.Regions, evego.Region{ID: row.ID, Name: string(row.Name)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "mapConstellations", false, func(dec *json.Decoder) error {
+		var row sdeConstellation
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.Constellations = append(data.Constellations, snapConstellation{
+			ID:       row.ID,
+			Name:     string(row.Name),
+			RegionID: row.RegionID,
+			Position: row.Position,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "mapSolarSystems", false, func(dec *json.Decoder) error {
+		var row sdeSolarSystem
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.SolarSystems = append(data.SolarSystems, snapSolarSystem{
+			ID:              row.ID,
+			Name:            string(row.Name),
+			ConstellationID: row.ConstellationID,
+			RegionID:        row.RegionID,
+			Security:        row.SecurityStatus,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range data.SolarSystems {
+		r.systems[data.SolarSystems[i].ID] = &data.SolarSystems[i]
+	}
+	return nil
+}
+
+// readCelestials reads planets, moons, asteroid belts, and stargates, and the
+// jumps between systems that the stargates provide.
+func (r *sdeReader) readCelestials() error {
+	data := r.data
+	celestials := make(map[int]*sdeCelestial)
+	groups := make(map[int]int)
+	for _, c := range []struct {
+		file  string
+		group int
+	}{
+		{"mapPlanets", groupPlanet},
+		{"mapMoons", groupMoon},
+		{"mapAsteroidBelts", groupAsteroidBelt},
+		{"mapStargates", groupStargate},
+	} {
+		group := c.group
+		err := readSDEFile(r.dir, c.file, false, func(dec *json.Decoder) error {
+			row := &sdeCelestial{}
+			if err := dec.Decode(row); err != nil {
+				return err
+			}
+			celestials[row.ID] = row
+			groups[row.ID] = group
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Name each celestial after the one it orbits, so planets come first.
+	var name func(id int) string
+	name = func(id int) string {
+		if n, ok := r.celestials[id]; ok {
+			return n
+		}
+		c, ok := celestials[id]
+		if !ok {
+			return ""
+		}
+		var n string
+		switch groups[id] {
+		case groupPlanet:
+			if sys, ok := r.systems[c.SolarSystemID]; ok {
+				n = sys.Name + " " + romanNumeral(c.CelestialIndex)
+			}
+		case groupMoon:
+			n = name(c.OrbitID) + " - Moon " + strconv.Itoa(c.OrbitIndex)
+		case groupAsteroidBelt:
+			n = name(c.OrbitID) + " - Asteroid Belt " + strconv.Itoa(c.OrbitIndex)
+		case groupStargate:
+			if sys, ok := r.systems[c.Destination.SolarSystemID]; ok {
+				n = "Stargate (" + sys.Name + ")"
+			}
+		}
+		r.celestials[id] = n
+		return n
+	}
+
+	jumps := make(map[snapSystemJump]bool)
+	for id, c := range celestials {
+		cel := snapCelestial{
+			ID:       id,
+			Name:     name(id),
+			GroupID:  groups[id],
+			SystemID: c.SolarSystemID,
+			TypeID:   c.TypeID,
+			TypeName: r.typeNames[c.TypeID],
+			OrbitID:  c.OrbitID,
+			Position: c.Position,
+		}
+		if cel.GroupID == groupStargate {
+			cel.DestinationID = c.Destination.StargateID
+			cel.DestinationSystemID = c.Destination.SolarSystemID
+			jumps[snapSystemJump{FromSystemID: c.SolarSystemID, ToSystemID: c.Destination.SolarSystemID}] = true
+		}
+		data.Celestials = append(data.Celestials, cel)
+	}
+	sort.Slice(data.Celestials, func(i, j int) bool { return data.Celestials[i].ID < data.Celestials[j].ID })
+	for j := range jumps {
+		data.SystemJumps = append(data.SystemJumps, j)
+	}
+	sort.Slice(data.SystemJumps, func(i, j int) bool {
+		a, b := data.SystemJumps[i], data.SystemJumps[j]
+		if a.FromSystemID != b.FromSystemID {
+			return a.FromSystemID < b.FromSystemID
+		}
+		return a.ToSystemID < b.ToSystemID
+	})
+	return nil
+}
+
+// readNPCs reads factions, NPC corporations, and agents.
+func (r *sdeReader) readNPCs() error {
+	data := r.data
+	factionNames := make(map[int]string)
+	err := readSDEFile(r.dir, "factions", false, func(dec *json.Decoder) error {
+		var row sdeFaction
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.Factions = append(data.Factions, evego.Faction{
+			Name:                 string(row.Name),
+			ID:                   row.ID,
+			Description:          string(row.Description),
+			SolarSystemID:        row.SolarSystemID,
+			CorporationID:        row.CorporationID,
+			MilitiaCorporationID: row.MilitiaCorporationID,
+		})
+		factionNames[row.ID] = string(row.Name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.Factions, func(i, j int) bool { return data.Factions[i].Name < data.Factions[j].Name })
+
+	err = readSDEFile(r.dir, "npcCorporations", false, func(dec *json.Decoder) error {
+		var row sdeCorporation
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.NPCCorporations = append(data.NPCCorporations, evego.NPCCorp{
+			Name:          string(row.Name),
+			ID:            row.ID,
+			FactionID:     row.FactionID,
+			Faction:       factionNames[row.FactionID],
+			SolarSystemID: row.SolarSystemID,
+		})
+		r.corpNames[row.ID] = string(row.Name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.NPCCorporations, func(i, j int) bool {
+		return data.NPCCorporations[i].Name < data.NPCCorporations[j].Name
+	})
+
+	divisions := make(map[int]string)
+	err = readSDEFile(r.dir, "npcCorporationDivisions", false, func(dec *json.Decoder) error {
+		var row sdeDivision
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		divisions[row.ID] = string(row.Name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Agents in space are located in a solar system rather than a station.
+	inSpace := make(map[int]int)
+	err = readSDEFile(r.dir, "agentsInSpace", true, func(dec *json.Decoder) error {
+		var row sdeAgentInSpace
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		inSpace[row.ID] = row.SolarSystemID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "npcCharacters", false, func(dec *json.Decoder) error {
+		var row sdeCharacter
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		if row.Agent == nil {
+			return nil
+		}
+		location := row.LocationID
+		if location == 0 {
+			location = inSpace[row.ID]
+		}
+		data.Agents = append(data.Agents, evego.Agent{
+			Name:          string(row.Name),
+			ID:            row.ID,
+			Type:          evego.AgentType(row.Agent.AgentTypeID),
+			CorporationID: row.CorporationID,
+			DivisionID:    row.Agent.DivisionID,
+			Division:      divisions[row.Agent.DivisionID],
+			Level:         row.Agent.Level,
+			StationID:     location,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.Agents, func(i, j int) bool { return data.Agents[i].Name < data.Agents[j].Name })
+	return nil
+}
+
+// readStations reads NPC stations, whose names are made up of the name of
+// the celestial they orbit, their owner's name, and usually the name of the
+// station's operation.
+func (r *sdeReader) readStations() error {
+	data := r.data
+	operations := make(map[int]string)
+	err := readSDEFile(r.dir, "stationOperations", false, func(dec *json.Decoder) error {
+		var row sdeOperation
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		operations[row.ID] = string(row.Name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = readSDEFile(r.dir, "npcStations", false, func(dec *json.Decoder) error {
+		var row sdeStation
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		name := r.celestials[row.OrbitID] + " - " + r.corpNames[row.OwnerID]
+		if row.UseOperationName && operations[row.OperationID] != "" {
+			name += " " + operations[row.OperationID]
+		}
+		stn := evego.Station{
+			Name:                   name,
+			ID:                     row.ID,
+			SystemID:               row.SolarSystemID,
+			CorporationID:          row.OwnerID,
+			Corporation:            r.corpNames[row.OwnerID],
+			ReprocessingEfficiency: row.ReprocessingEfficiency,
+		}
+		if sys, ok := r.systems[row.SolarSystemID]; ok {
+			stn.ConstellationID = sys.ConstellationID
+			stn.RegionID = sys.RegionID
+		}
+		data.Stations = append(data.Stations, stn)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.Stations, func(i, j int) bool { return data.Stations[i].ID < data.Stations[j].ID })
+	return nil
+}
+
+// readIndustry reads blueprints' activities, materials, and products.
+func (r *sdeReader) readIndustry() error {
+	data := r.data
+	for _, a := range sdeActivities {
+		data.Activities = append(data.Activities, snapActivity{ID: a.id, Name: a.name})
+	}
+	err := readSDEFile(r.dir, "blueprints", false, func(dec *json.Decoder) error {
+		var row sdeBlueprint
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		for _, a := range sdeActivities {
+			activity, ok := row.Activities[a.key]
+			if !ok {
+				continue
+			}
+			data.IndustryActivities = append(data.IndustryActivities, snapIndustryActivity{
+				TypeID:     row.ID,
+				ActivityID: a.id,
+				Seconds:    activity.Time,
+			})
+			for _, m := range activity.Materials {
+				data.IndustryMaterials = append(data.IndustryMaterials, snapIndustryMaterial{
+					TypeID:         row.ID,
+					ActivityID:     a.id,
+					MaterialTypeID: m.TypeID,
+					Quantity:       m.Quantity,
+				})
+			}
+			for _, p := range activity.Products {
+				data.IndustryProducts = append(data.IndustryProducts, snapIndustryMaterial{
+					TypeID:         row.ID,
+					ActivityID:     a.id,
+					MaterialTypeID: p.TypeID,
+					Quantity:       p.Quantity,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.IndustryActivities, func(i, j int) bool {
+		a, b := data.IndustryActivities[i], data.IndustryActivities[j]
+		if a.TypeID != b.TypeID {
+			return a.TypeID < b.TypeID
+		}
+		return a.ActivityID < b.ActivityID
+	})
+	for _, rows := range [][]snapIndustryMaterial{data.IndustryMaterials, data.IndustryProducts} {
+		rows := rows
+		sort.Slice(rows, func(i, j int) bool {
+			a, b := rows[i], rows[j]
+			if a.TypeID != b.TypeID {
+				return a.TypeID < b.TypeID
+			}
+			if a.ActivityID != b.ActivityID {
+				return a.ActivityID < b.ActivityID
+			}
+			return a.MaterialTypeID < b.MaterialTypeID
+		})
+	}
+	return nil
+}
+
+// readDogma reads the definitions of dogma attributes and effects, and their
+// values for each item type.
+func (r *sdeReader) readDogma() error {
+	data := r.data
+	attrNames := make(map[int]string)
+	err := readSDEFile(r.dir, "dogmaAttributes", false, func(dec *json.Decoder) error {
+		var row sdeAttribute
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		data.Attributes = append(data.Attributes, evego.DogmaAttribute{
+			ID:           row.ID,
+			Name:         row.Name,
+			DisplayName:  string(row.DisplayName),
+			Description:  string(row.Description),
+			DefaultValue: row.DefaultValue,
+			UnitID:       row.UnitID,
+			Published:    row.Published,
+			Stackable:    row.Stackable,
+			HighIsGood:   row.HighIsGood,
+		})
+		attrNames[row.ID] = row.Name
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.Attributes, func(i, j int) bool { return data.Attributes[i].ID < data.Attributes[j].ID })
+
+	effects := make(map[int]*sdeEffect)
+	err = readSDEFile(r.dir, "dogmaEffects", false, func(dec *json.Decoder) error {
+		row := &sdeEffect{}
+		if err := dec.Decode(row); err != nil {
+			return err
+		}
+		if row.Name == "" {
+			row.Name = row.EffectName
+		}
+		if row.EffectCategoryID == 0 {
+			row.EffectCategoryID = row.EffectCategory
+		}
+		effects[row.ID] = row
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = readSDEFile(r.dir, "typeDogma", false, func(dec *json.Decoder) error {
+		var row sdeTypeDogma
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		for _, a := range row.DogmaAttributes {
+			name, ok := attrNames[a.AttributeID]
+			if !ok {
+				// The SQL backend only returns attributes that are defined.
+				continue
+			}
+			data.TypeAttributes = append(data.TypeAttributes, evego.ItemAttribute{
+				TypeID:      row.ID,
+				AttributeID: a.AttributeID,
+				Name:        name,
+				Value:       a.Value,
+			})
+		}
+		for _, e := range row.DogmaEffects {
+			effect, ok := effects[e.EffectID]
+			if !ok {
+				continue
+			}
+			data.TypeEffects = append(data.TypeEffects, evego.ItemEffect{
+				TypeID:    row.ID,
+				EffectID:  e.EffectID,
+				Name:      effect.Name,
+				Category:  effect.EffectCategoryID,
+				IsDefault: e.IsDefault,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(data.TypeAttributes, func(i, j int) bool {
+		a, b := data.TypeAttributes[i], data.TypeAttributes[j]
+		if a.TypeID != b.TypeID {
+			return a.TypeID < b.TypeID
+		}
+		return a.AttributeID < b.AttributeID
+	})
+	sort.Slice(data.TypeEffects, func(i, j int) bool {
+		a, b := data.TypeEffects[i], data.TypeEffects[j]
+		if a.TypeID != b.TypeID {
+			return a.TypeID < b.TypeID
+		}
+		return a.EffectID < b.EffectID
+	})
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSnapshotFromSDE(t *testing.T) {
+	Convey("Given a snapshot of the official SDE", t, func() {
+		snap, err := dbaccess.SnapshotFromSDE("../../testdata/sde")
+		So(err, ShouldBeNil)
+		db := dbaccess.MemoryDatabase(snap)
+		defer db.Close()
+
+		Convey("Items and their compositions are loaded.", func() {
+			vexor, err := db.ItemForName("Vexor")
+			So(err, ShouldBeNil)
+			So(vexor.ID, ShouldEqual, 626)
+			So(vexor.Group, ShouldEqual, "Cruiser")
+			So(vexor.Category, ShouldEqual, "Ship")
+			So(vexor.Volume, ShouldEqual, 115000.0)
+			So(vexor.PackagedVolume, ShouldEqual, vexor.Volume)
+
+			comp, err := db.ItemComposition(1228)
+			So(err, ShouldBeNil)
+			So(comp, ShouldHaveLength, 2)
+			So(comp[0].Item.Name, ShouldEqual, "Tritanium")
+			So(comp[0].Quantity, ShouldEqual, 346)
+			So(comp[1].Item.Name, ShouldEqual, "Pyerite")
+			So(comp[1].Quantity, ShouldEqual, 173)
+		})
+
+		Convey("Celestials and stations are named as in the game.", func() {
+			planets, err := db.PlanetsForSystem(30000142)
+			So(err, ShouldBeNil)
+			So(planets, ShouldHaveLength, 1)
+			So(planets[0].Name, ShouldEqual, "Jita IV")
+			So(planets[0].Type, ShouldEqual, "Planet (Temperate)")
+
+			moons, err := db.MoonsForSystem(30000142)
+			So(err, ShouldBeNil)
+			So(moons, ShouldHaveLength, 1)
+			So(moons[0].Name, ShouldEqual, "Jita IV - Moon 4")
+			So(moons[0].PlanetID, ShouldEqual, 40009081)
+
+			belts, err := db.AsteroidBeltsForSystem(30000142)
+			So(err, ShouldBeNil)
+			So(belts, ShouldHaveLength, 1)
+			So(belts[0].Name, ShouldEqual, "Jita IV - Asteroid Belt 1")
+
+			gates, err := db.StargatesForSystem(30000142)
+			So(err, ShouldBeNil)
+			So(gates, ShouldHaveLength, 1)
+			So(gates[0].Name, ShouldEqual, "Stargate (Perimeter)")
+			So(gates[0].DestinationID, ShouldEqual, 50001249)
+
+			neighbors, err := db.SolarSystemNeighbors(30000144)
+			So(err, ShouldBeNil)
+			So(neighbors, ShouldHaveLength, 1)
+			So(neighbors[0].Name, ShouldEqual, "Jita")
+
+			stn, err := db.StationForID(60003760)
+			So(err, ShouldBeNil)
+			So(stn.Name, ShouldEqual, "Jita IV - Moon 4 - Caldari Navy Assembly Plant")
+			So(stn.RegionID, ShouldEqual, 10000002)
+			stn, err = db.StationForID(60003763)
+			So(err, ShouldBeNil)
+			So(stn.Name, ShouldEqual, "Jita IV - Caldari Navy")
+		})
+
+		Convey("NPCs are loaded.", func() {
+			corps, err := db.NPCCorporationsForFaction(500001)
+			So(err, ShouldBeNil)
+			So(corps, ShouldHaveLength, 2)
+			So(corps[0].Faction, ShouldEqual, "Caldari State")
+
+			agents, err := db.AgentsForCorporation(1000035)
+			So(err, ShouldBeNil)
+			So(agents, ShouldHaveLength, 2)
+			So(agents[0].Name, ShouldEqual, "Aakari Ikonen")
+			So(agents[0].Division, ShouldEqual, "Security")
+			So(agents[0].StationID, ShouldEqual, 60003760)
+			So(agents[1].StationID, ShouldEqual, 30000144)
+		})
+
+		Convey("Blueprints and dogma are loaded.", func() {
+			outputs, err := db.BlueprintOutputs("Vexor Blueprint")
+			So(err, ShouldBeNil)
+			So(outputs, ShouldHaveLength, 1)
+			So(outputs[0].ActivityType, ShouldEqual, evego.Manufacturing)
+			So(outputs[0].OutputItem.Name, ShouldEqual, "Vexor")
+
+			// Patterns ignore the case of ASCII letters, as in SQLite.
+			outputs, err = db.BlueprintOutputs("vexor BLUEPRINT")
+			So(err, ShouldBeNil)
+			So(outputs, ShouldHaveLength, 1)
+
+			inputs, err := db.BlueprintProductionInputs("Vexor Blueprint", "Vexor")
+			So(err, ShouldBeNil)
+			So(inputs, ShouldHaveLength, 2)
+
+			attrs, err := db.ItemAttributes(626)
+			So(err, ShouldBeNil)
+			So(attrs, ShouldHaveLength, 2)
+			So(attrs[0].Name, ShouldEqual, "mass")
+			So(attrs[1].Value, ShouldEqual, 3335.0)
+
+			effects, err := db.ItemEffects(626)
+			So(err, ShouldBeNil)
+			So(effects, ShouldHaveLength, 2)
+			So(effects[0].Name, ShouldEqual, "targetAttack")
+			So(effects[0].IsDefault, ShouldBeTrue)
+			So(effects[1].Name, ShouldEqual, "online")
+			So(effects[1].Category, ShouldEqual, 4)
+		})
+
+		Convey("The snapshot can be saved.", func() {
+			var buf bytes.Buffer
+			So(snap.Write(&buf), ShouldBeNil)
+			read, err := dbaccess.ReadSnapshot(&buf)
+			So(err, ShouldBeNil)
+			item, err := dbaccess.MemoryDatabase(read).ItemForID(34)
+			So(err, ShouldBeNil)
+			So(item.Name, ShouldEqual, "Tritanium")
+		})
+	})
+
+	Convey("A missing SDE is an error.", t, func() {
+		_, err := dbaccess.SnapshotFromSDE("../../testdata/nonexistent")
+		So(err, ShouldNotBeNil)
+	})
+}
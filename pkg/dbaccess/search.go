@@ -0,0 +1,204 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/backerman/evego"
+)
+
+// itemName is an entry in the item search index.
+type itemName struct {
+	ID        int
+	Name      string
+	Published bool
+	OnMarket  bool
+	lower     string
+}
+
+// itemIndex is the list of all item names, used for searching.
+type itemIndex []itemName
+
+// itemHit is an item that matched a search; the caller is responsible for
+// looking up the item itself.
+type itemHit struct {
+	entry    *itemName
+	kind     evego.MatchKind
+	distance int
+}
+
+// newItemIndex builds a search index from a list of item names.
+func newItemIndex(names []itemName) itemIndex {
+	for i := range names {
+		names[i].lower = strings.ToLower(names[i].Name)
+	}
+	return itemIndex(names)
+}
+
+// maxDistance returns the largest edit distance from the query that we'll
+// consider a fuzzy match: one typo per four characters, and none at all for
+// very short queries.
+func maxDistance(query string) int {
+	return utf8.RuneCountInString(query) / 4
+}
+
+// editDistance returns the edit distance between two strings, counting
+// insertions, deletions, substitutions, and transpositions of adjacent
+// characters, or limit+1 if it's greater than limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	// We only need to keep the last two rows of the matrix.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] &&
+				prev2[j-2]+1 < cur[j] {
+				cur[j] = prev2[j-2] + 1
+			}
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// search returns the index entries that match the query, best first.
+func (idx itemIndex) search(query string, limit int) []itemHit {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	maxDist := maxDistance(query)
+	var hits []itemHit
+	for i := range idx {
+		entry := &idx[i]
+		if entry.lower == query {
+			hits = append(hits, itemHit{entry: entry, kind: evego.ExactMatch})
+			continue
+		}
+		hit := itemHit{
+			entry:    entry,
+			distance: editDistance(query, entry.lower, maxDist),
+		}
+		switch {
+		case strings.HasPrefix(entry.lower, query):
+			hit.kind = evego.PrefixMatch
+		case strings.Contains(entry.lower, query):
+			hit.kind = evego.SubstringMatch
+		case hit.distance <= maxDist:
+			hit.kind = evego.FuzzyMatch
+		default:
+			continue
+		}
+		if hit.distance > maxDist {
+			// Too different from the query to be a misspelling.
+			hit.distance = -1
+		}
+		hits = append(hits, hit)
+	}
+	sort.Sort(byRank(hits))
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// byRank sorts search hits from best to worst.
+type byRank []itemHit
+
+func (h byRank) Len() int      { return len(h) }
+func (h byRank) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h byRank) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	switch {
+	case a.kind != b.kind:
+		return a.kind < b.kind
+	case a.entry.Published != b.entry.Published:
+		return a.entry.Published
+	case a.entry.OnMarket != b.entry.OnMarket:
+		return a.entry.OnMarket
+	case a.distance != b.distance:
+		// A misspelling is closer than a name that's merely similar.
+		return b.distance < 0 || (a.distance >= 0 && a.distance < b.distance)
+	case len(a.entry.Name) != len(b.entry.Name):
+		// Prefer the shortest (i.e. closest) name.
+		return len(a.entry.Name) < len(b.entry.Name)
+	}
+	return a.entry.Name < b.entry.Name
+}
+
+// itemMatches looks up the items for a list of search hits.
+func itemMatches(ctx context.Context, db evego.Database, hits []itemHit) ([]evego.ItemMatch, error) {
+	ids := make([]int, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.entry.ID
+	}
+	items, err := db.ItemsForIDsContext(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[int]*evego.Item, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+	matches := make([]evego.ItemMatch, 0, len(hits))
+	for _, hit := range hits {
+		item, ok := itemsByID[hit.entry.ID]
+		if !ok {
+			continue
+		}
+		matches = append(matches, evego.ItemMatch{
+			Item:      item,
+			Kind:      hit.kind,
+			Distance:  hit.distance,
+			Published: hit.entry.Published,
+			OnMarket:  hit.entry.OnMarket,
+		})
+	}
+	return matches, nil
+}
@@ -0,0 +1,270 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/backerman/evego"
+	"github.com/jmoiron/sqlx"
+)
+
+// Snapshot is a copy of the parts of the static data export that evego uses.
+// It can be generated from a SQL conversion of the SDE or from the official
+// export's JSONL files, saved in a compact binary form, and loaded into a
+// MemoryDatabase on systems that can't use a SQL driver.
+type Snapshot struct {
+	data snapshotData
+}
+
+// snapshotData holds the rows of each table in a snapshot. Its fields are
+// exported for the benefit of encoding/gob.
+type snapshotData struct {
+	// Driver is the SQL driver that the snapshot was taken with, which
+	// decides how LIKE patterns treat case. It's empty for snapshots of the
+	// official export, which follow SQLite's rules.
+	Driver             string
+	Types              []snapType
+	Groups             []snapGroup
+	Categories         []snapCategory
+	MarketGroups       []snapMarketGroup
+	TypeMaterials      []snapMaterial
+	Regions            []evego.Region
+	Constellations     []snapConstellation
+	SolarSystems       []snapSolarSystem
+	SystemJumps        []snapSystemJump
+	Celestials         []snapCelestial
+	Stations           []evego.Station
+	Factions           []evego.Faction
+	NPCCorporations    []evego.NPCCorp
+	Agents             []evego.Agent
+	Activities         []snapActivity
+	IndustryActivities []snapIndustryActivity
+	IndustryMaterials  []snapIndustryMaterial
+	IndustryProducts   []snapIndustryMaterial
+	Attributes         []evego.DogmaAttribute
+	TypeAttributes     []evego.ItemAttribute
+	TypeEffects        []evego.ItemEffect
+}
+
+type snapType struct {
+	ID             int     `db:"typeID"`
+	Name           string  `db:"typeName"`
+	GroupID        int     `db:"groupID"`
+	PortionSize    int     `db:"portionSize"`
+	Volume         float64 `db:"volume"`
+	PackagedVolume float64 `db:"packagedVolume"`
+	Capacity       float64 `db:"capacity"`
+	Mass           float64 `db:"mass"`
+	BasePrice      float64 `db:"basePrice"`
+	MarketGroupID  int     `db:"marketGroupID"` // 0 if not on the market
+	Published      bool    `db:"-"`
+}
+
+type snapGroup struct {
+	ID         int    `db:"groupID"`
+	Name       string `db:"groupName"`
+	CategoryID int    `db:"categoryID"`
+}
+
+type snapCategory struct {
+	ID   int    `db:"categoryID"`
+	Name string `db:"categoryName"`
+}
+
+type snapMarketGroup struct {
+	ID          int    `db:"marketGroupID"`
+	ParentID    int    `db:"parentGroupID"` // 0 for top-level groups
+	Name        string `db:"marketGroupName"`
+	Description string `db:"description"`
+}
+
+type snapMaterial struct {
+	TypeID         int `db:"typeID"`
+	MaterialTypeID int `db:"materialTypeID"`
+	Quantity       int `db:"quantity"`
+}
+
+type snapConstellation struct {
+	ID       int    `db:"constellationID"`
+	Name     string `db:"constellationName"`
+	RegionID int    `db:"regionID"`
+	evego.Position
+}
+
+type snapSolarSystem struct {
+	ID              int     `db:"solarSystemID"`
+	Name            string  `db:"solarSystemName"`
+	ConstellationID int     `db:"constellationID"`
+	RegionID        int     `db:"regionID"`
+	Security        float64 `db:"security"`
+}
+
+type snapSystemJump struct {
+	FromSystemID int `db:"fromSolarSystemID"`
+	ToSystemID   int `db:"toSolarSystemID"`
+}
+
+type snapActivity struct {
+	ID   int    `db:"activityID"`
+	Name string `db:"activityName"`
+}
+
+type snapIndustryActivity struct {
+	TypeID     int `db:"typeID"`
+	ActivityID int `db:"activityID"`
+	Seconds    int `db:"time"`
+}
+
+// snapIndustryMaterial is a row of either industryActivityMaterials or
+// industryActivityProducts.
+type snapIndustryMaterial struct {
+	TypeID         int `db:"typeID"`
+	ActivityID     int `db:"activityID"`
+	MaterialTypeID int `db:"materialTypeID"`
+	Quantity       int `db:"quantity"`
+}
+
+// SnapshotFromSQL copies the data that evego uses from a SQL database
+// containing the static data export.
+func SnapshotFromSQL(driver, dataSource string) (*Snapshot, error) {
+	db, err := sqlx.Connect(driver, dataSource)
+	if err != nil {
+		return nil, &QueryError{
+			Op:  fmt.Sprintf("open item database (driver: %s, datasource: %s)", driver, dataSource),
+			Err: err,
+		}
+	}
+	defer db.Close()
+
+	snap := &Snapshot{}
+	data := &snap.data
+	data.Driver = driver
+	tables := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&data.Groups, snapshotGroups},
+		{&data.Categories, snapshotCategories},
+		{&data.MarketGroups, snapshotMarketGroups},
+		{&data.TypeMaterials, snapshotTypeMaterials},
+		{&data.Regions, snapshotRegions},
+		{&data.Constellations, snapshotConstellations},
+		{&data.SolarSystems, snapshotSolarSystems},
+		{&data.SystemJumps, snapshotSystemJumps},
+		{&data.Celestials, snapshotCelestials},
+		{&data.Stations, snapshotStations},
+		{&data.Factions, allFactions},
+		{&data.NPCCorporations, allNPCCorps},
+		{&data.Agents, allAgents},
+		{&data.Activities, snapshotActivities},
+		{&data.IndustryActivities, snapshotIndustryActivities},
+		{&data.IndustryMaterials, snapshotIndustryMaterials},
+		{&data.IndustryProducts, snapshotIndustryProducts},
+		{&data.TypeAttributes, snapshotTypeAttributes},
+	}
+	for _, t := range tables {
+		err = db.Select(t.dest, db.Rebind(t.query))
+		if err != nil {
+			return nil, dbError("read table:\n"+t.query, err)
+		}
+	}
+
+	// Tables with boolean columns need to be scanned row by row.
+	rows, err := db.Queryx(snapshotTypes)
+	if err != nil {
+		return nil, dbError("read item types", err)
+	}
+	for rows.Next() {
+		row := struct {
+			snapType
+			Published sql.NullBool `db:"published"`
+		}{}
+		err = rows.StructScan(&row)
+		if err != nil {
+			rows.Close()
+			return nil, dbError("scan item type", err)
+		}
+		row.snapType.Published = row.Published.Bool
+		data.Types = append(data.Types, row.snapType)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, dbError("read item types", err)
+	}
+
+	rows, err = db.Queryx(snapshotAttributes)
+	if err != nil {
+		return nil, dbError("read attributes", err)
+	}
+	for rows.Next() {
+		row := attributeRow{}
+		err = rows.StructScan(&row)
+		if err != nil {
+			rows.Close()
+			return nil, dbError("scan attribute", err)
+		}
+		data.Attributes = append(data.Attributes, *row.attribute())
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, dbError("read attributes", err)
+	}
+
+	rows, err = db.Queryx(snapshotTypeEffects)
+	if err != nil {
+		return nil, dbError("read item effects", err)
+	}
+	for rows.Next() {
+		row := effectRow{}
+		err = rows.StructScan(&row)
+		if err != nil {
+			rows.Close()
+			return nil, dbError("scan item effect", err)
+		}
+		data.TypeEffects = append(data.TypeEffects, row.effect())
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, dbError("read item effects", err)
+	}
+
+	return snap, nil
+}
+
+// ReadSnapshot reads a snapshot written by Write.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	snap := &Snapshot{}
+	err := gob.NewDecoder(r).Decode(&snap.data)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read snapshot: %v", err)
+	}
+	return snap, nil
+}
+
+// Write writes the snapshot in a compact binary form that can be read by
+// ReadSnapshot.
+func (s *Snapshot) Write(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(&s.data)
+}
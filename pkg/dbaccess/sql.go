@@ -18,9 +18,11 @@ limitations under the License.
 package dbaccess
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"sync"
+	"time"
 
 	"github.com/backerman/evego"
 	"github.com/jmoiron/sqlx"
@@ -32,9 +34,27 @@ type sqlDb struct {
 	itemInfoStatement             *sqlx.Stmt
 	itemIDInfoStatement           *sqlx.Stmt
 	catTreeFromItemStatement      *sqlx.Stmt
+	marketGroupInfoStmt           *sqlx.Stmt
+	marketGroupChildrenStmt       *sqlx.Stmt
+	itemsInMarketGroupStmt        *sqlx.Stmt
+	itemsInMarketGroupTreeStmt    *sqlx.Stmt
 	systemInfoStatement           *sqlx.Stmt
 	systemIDInfoStatement         *sqlx.Stmt
 	regionInfoStatement           *sqlx.Stmt
+	regionIDInfoStmt              *sqlx.Stmt
+	constellationIDInfoStmt       *sqlx.Stmt
+	regionConstellationsStmt      *sqlx.Stmt
+	constellationSystemsStmt      *sqlx.Stmt
+	regionSystemsStmt             *sqlx.Stmt
+	systemNeighborsStmt           *sqlx.Stmt
+	systemCelestialsStmt          *sqlx.Stmt
+	regionCelestialsStmt          *sqlx.Stmt
+	factionIDInfoStmt             *sqlx.Stmt
+	npcCorpIDInfoStmt             *sqlx.Stmt
+	factionNPCCorpsStmt           *sqlx.Stmt
+	agentIDInfoStmt               *sqlx.Stmt
+	corporationAgentsStmt         *sqlx.Stmt
+	stationAgentsStmt             *sqlx.Stmt
 	stationIDInfoStatement        *sqlx.Stmt
 	stationNameInfoStatement      *sqlx.Stmt
 	blueprintProducesStmt         *sqlx.Stmt
@@ -42,17 +62,35 @@ type sqlDb struct {
 	blueprintProducedByStmt       *sqlx.Stmt
 	matsForBPProductionStmt       *sqlx.Stmt
 	reprocessOutputsStmt          *sqlx.Stmt
+	reactionFormulasStmt          *sqlx.Stmt
+	reactionsForProductStmt       *sqlx.Stmt
+	reactionInputsStmt            *sqlx.Stmt
+	reactionOutputsStmt           *sqlx.Stmt
+	attributeForIDStmt            *sqlx.Stmt
+	attributeForNameStmt          *sqlx.Stmt
+	itemAttributeValueStmt        *sqlx.Stmt
+	itemAttributeValueForNameStmt *sqlx.Stmt
+
+	// The item search index is loaded on first use. A load that fails is
+	// retried by the next search.
+	searchIndexMu     sync.Mutex
+	searchIndex       itemIndex
+	searchIndexLoaded bool
 }
 
-// SQLDatabase returns an EveDatabase object that can be used to access an SQL backend.
-func SQLDatabase(driver, dataSource string) evego.Database {
+// SQLDatabase returns an EveDatabase object that can be used to access an SQL
+// backend. An error is returned if the database can't be opened or doesn't
+// have the tables we need.
+func SQLDatabase(driver, dataSource string) (evego.Database, error) {
 	evedb := new(sqlDb)
 	var err error
 	evedb.db, err = sqlx.Connect(driver, dataSource)
 	db := evedb.db // shortcut
 	if err != nil {
-		log.Fatalf("Unable to open item database (driver: %s, datasource: %s): %v",
-			driver, dataSource, err)
+		return nil, &QueryError{
+			Op:  fmt.Sprintf("open item database (driver: %s, datasource: %s)", driver, dataSource),
+			Err: err,
+		}
 	}
 
 	// Prepare statements
@@ -65,9 +103,27 @@ func SQLDatabase(driver, dataSource string) evego.Database {
 		{&evedb.itemInfoStatement, itemInfo},
 		{&evedb.itemIDInfoStatement, itemIDInfo},
 		{&evedb.catTreeFromItemStatement, catTree},
+		{&evedb.marketGroupInfoStmt, marketGroupInfo},
+		{&evedb.marketGroupChildrenStmt, marketGroupChildren},
+		{&evedb.itemsInMarketGroupStmt, itemsInMarketGroup},
+		{&evedb.itemsInMarketGroupTreeStmt, itemsInMarketGroupTree},
 		{&evedb.systemInfoStatement, systemInfo},
 		{&evedb.systemIDInfoStatement, systemIDInfo},
 		{&evedb.regionInfoStatement, regionInfo},
+		{&evedb.regionIDInfoStmt, regionIDInfo},
+		{&evedb.constellationIDInfoStmt, constellationIDInfo},
+		{&evedb.regionConstellationsStmt, regionConstellations},
+		{&evedb.constellationSystemsStmt, constellationSystems},
+		{&evedb.regionSystemsStmt, regionSystems},
+		{&evedb.systemNeighborsStmt, systemNeighbors},
+		{&evedb.systemCelestialsStmt, systemCelestials},
+		{&evedb.regionCelestialsStmt, regionCelestials},
+		{&evedb.factionIDInfoStmt, factionIDInfo},
+		{&evedb.npcCorpIDInfoStmt, npcCorpIDInfo},
+		{&evedb.factionNPCCorpsStmt, factionNPCCorps},
+		{&evedb.agentIDInfoStmt, agentIDInfo},
+		{&evedb.corporationAgentsStmt, corporationAgents},
+		{&evedb.stationAgentsStmt, stationAgents},
 		{&evedb.stationIDInfoStatement, stationIDInfo},
 		{&evedb.stationNameInfoStatement, stationNameInfo},
 		{&evedb.blueprintProducesStmt, blueprintProduces},
@@ -75,105 +131,317 @@ func SQLDatabase(driver, dataSource string) evego.Database {
 		{&evedb.blueprintProducedByStmt, blueprintProducedBy},
 		{&evedb.matsForBPProductionStmt, materialsForBlueprintProduction},
 		{&evedb.reprocessOutputsStmt, reprocessOutputsStmt},
+		{&evedb.reactionFormulasStmt, reactionFormulas},
+		{&evedb.reactionsForProductStmt, reactionsForProduct},
+		{&evedb.reactionInputsStmt, reactionInputs},
+		{&evedb.reactionOutputsStmt, reactionOutputs},
+		{&evedb.attributeForIDStmt, attributeForID},
+		{&evedb.attributeForNameStmt, attributeForName},
+		{&evedb.itemAttributeValueStmt, itemAttributeValue},
+		{&evedb.itemAttributeValueForNameStmt, itemAttributeValueForName},
 	}
 
 	for _, s := range stmts {
 		prepared, err := db.Preparex(db.Rebind(s.statementText))
 		if err != nil {
-			log.Fatalf("Unable to prepare statement: %v\n%v", err, s.statementText)
+			db.Close()
+			return nil, &QueryError{Op: "prepare statement:\n" + s.statementText, Err: err}
 		}
 		// Pointer magic, stage 2: Dereference the pointer to the pointer
 		// and set it to point to the statement we just prepared.
 		*s.preparedStatement = prepared
 	}
 
-	return evedb
+	return evedb, nil
 }
 
-// ItemForName returns a populated Item object for a given item title.
-func (db *sqlDb) ItemForName(itemName string) (*evego.Item, error) {
+// ItemForNameContext returns a populated Item object for a given item title.
+func (db *sqlDb) ItemForNameContext(ctx context.Context, itemName string) (*evego.Item, error) {
 	var err error
 	object := evego.Item{}
-	row := db.itemInfoStatement.QueryRowx(itemName)
+	row := db.itemInfoStatement.QueryRowxContext(ctx, itemName)
 	err = row.StructScan(&object)
-	if err == sql.ErrNoRows {
+	if err != nil {
+		return nil, dbError("get item", err)
+	}
+	object.Type, err = db.itemType(ctx, &object)
+	if err != nil {
 		return nil, err
 	}
-	object.Type = db.itemType(&object)
-
-	return &object, err
+	return &object, nil
 }
 
-func (db *sqlDb) ItemForID(itemID int) (*evego.Item, error) {
+func (db *sqlDb) ItemForIDContext(ctx context.Context, itemID int) (*evego.Item, error) {
 	// This is now a convenience function for ItemsForIDs.
-	itemArray, err := db.ItemsForIDs([]int{itemID})
+	itemArray, err := db.ItemsForIDsContext(ctx, []int{itemID})
 	if err != nil {
 		return nil, err
 	}
 	if len(itemArray) == 0 {
-		return nil, fmt.Errorf("Unable to get item for ID %v", itemID)
+		return nil, sql.ErrNoRows
 	}
 	return itemArray[0], nil
 }
 
-func (db *sqlDb) ItemsForIDs(itemIDs []int) ([]*evego.Item, error) {
-	query, args, err := sqlx.In(itemIDsInfo, itemIDs)
+// maxQueryIDs is the most IDs we pass to a single IN query, which keeps us
+// under SQLite's limit on the number of parameters in a query.
+const maxQueryIDs = 500
+
+// inBatches calls query on successive slices of ids that are no longer than
+// maxQueryIDs, stopping at the first error.
+func inBatches(ids []int, query func(batch []int) error) error {
+	for start := 0; start < len(ids); start += maxQueryIDs {
+		end := start + maxQueryIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := query(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *sqlDb) ItemsForIDsContext(ctx context.Context, itemIDs []int) ([]*evego.Item, error) {
+	items, err := db.untypedItemsForIDs(ctx, itemIDs)
 	if err != nil {
 		return nil, err
 	}
-	query = db.db.Rebind(query)
-	rows, err := db.db.Queryx(query, args...)
+	for _, item := range items {
+		item.Type, err = db.itemType(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// untypedItemsForIDs returns the items with the given IDs without looking up
+// their types.
+func (db *sqlDb) untypedItemsForIDs(ctx context.Context, itemIDs []int) ([]*evego.Item, error) {
+	items := []*evego.Item{}
+	err := inBatches(itemIDs, func(batch []int) error {
+		query, args, err := sqlx.In(itemIDsInfo, batch)
+		if err != nil {
+			return dbError("build item query", err)
+		}
+		rows, err := db.db.QueryxContext(ctx, db.db.Rebind(query), args...)
+		if err != nil {
+			return dbError("get items", err)
+		}
+		found, err := scanUntypedItems(rows)
+		if err != nil {
+			return err
+		}
+		items = append(items, found...)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	// Put the items into an array and return.
-	items := make([]*evego.Item, 0, len(itemIDs))
+	return items, nil
+}
+
+// scanUntypedItems returns the items from the rows of an item query without
+// their types. Looking up an item's type takes another query, so callers that
+// handle many items work the types out from the market tree instead.
+func scanUntypedItems(rows *sqlx.Rows) ([]*evego.Item, error) {
+	defer rows.Close()
+	items := []*evego.Item{}
 	for rows.Next() {
 		item := new(evego.Item)
-		err = rows.StructScan(item)
+		err := rows.StructScan(item)
 		if err != nil {
-			return nil, err
+			return nil, dbError("scan item", err)
 		}
-		item.Type = db.itemType(item)
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("get items", err)
+	}
 	return items, nil
 }
 
-// itemComposition returns the composition of a named Eve item.
-func (db *sqlDb) ItemComposition(itemID int) ([]evego.InventoryLine, error) {
-	rows, err := db.compStatement.Query(itemID)
+// loadSearchIndex reads the names of all items for searching. The index is
+// shared by every caller, so it isn't loaded under any one caller's context.
+func (db *sqlDb) loadSearchIndex() (itemIndex, error) {
+	rows, err := db.db.Queryx(db.db.Rebind(allItemNames))
 	if err != nil {
-		log.Fatalf("Unable to execute composition query for item %d: %v", itemID, err)
+		return nil, dbError("get item names", err)
 	}
 	defer rows.Close()
+	var names []itemName
+	for rows.Next() {
+		row := struct {
+			ID            int           `db:"typeID"`
+			Name          string        `db:"typeName"`
+			Published     sql.NullBool  `db:"published"`
+			MarketGroupID sql.NullInt64 `db:"marketGroupID"`
+		}{}
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, dbError("scan item name", err)
+		}
+		names = append(names, itemName{
+			ID:        row.ID,
+			Name:      row.Name,
+			Published: row.Published.Bool,
+			OnMarket:  row.MarketGroupID.Valid,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get item names", err)
+	}
+	return newItemIndex(names), nil
+}
 
-	var results []evego.InventoryLine
+// itemIndex returns the item search index, loading it if a previous search
+// hasn't already done so.
+func (db *sqlDb) itemIndex() (itemIndex, error) {
+	db.searchIndexMu.Lock()
+	defer db.searchIndexMu.Unlock()
+	if !db.searchIndexLoaded {
+		index, err := db.loadSearchIndex()
+		if err != nil {
+			return nil, err
+		}
+		db.searchIndex = index
+		db.searchIndexLoaded = true
+	}
+	return db.searchIndex, nil
+}
+
+func (db *sqlDb) SearchItemsContext(ctx context.Context, query string, limit int) ([]evego.ItemMatch, error) {
+	index, err := db.itemIndex()
+	if err != nil {
+		return nil, err
+	}
+	hits := index.search(query, limit)
+	if len(hits) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return itemMatches(ctx, db, hits)
+}
+
+func (db *sqlDb) ItemIDsContext(ctx context.Context) ([]int, error) {
+	var ids []int
+	err := db.db.SelectContext(ctx, &ids, allItemIDs)
+	if err != nil {
+		return nil, dbError("get item IDs", err)
+	}
+	return ids, nil
+}
+
+// ItemCompositionContext returns the composition of a named Eve item.
+func (db *sqlDb) ItemCompositionContext(ctx context.Context, itemID int) ([]evego.InventoryLine, error) {
+	rows, err := db.compStatement.QueryContext(ctx, itemID)
+	if err != nil {
+		return nil, dbError(fmt.Sprintf("get composition of item %d", itemID), err)
+	}
+	defer rows.Close()
+
+	type component struct {
+		id       int
+		quantity int
+	}
+	var components []component
 	for rows.Next() {
-		var (
-			id       int
-			quantity int
-		)
-		err = rows.Scan(&id, &quantity)
+		var c component
+		err = rows.Scan(&c.id, &c.quantity)
 		if err != nil {
-			log.Fatalf("Unable to execute query for item %d: %v", itemID, err)
+			return nil, dbError(fmt.Sprintf("scan composition of item %d", itemID), err)
+		}
+		components = append(components, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(fmt.Sprintf("get composition of item %d", itemID), err)
+	}
+	rows.Close()
+
+	var results []evego.InventoryLine
+	for _, c := range components {
+		item, err := db.ItemForIDContext(ctx, c.id)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("item %d has nonexistent component %d", itemID, c.id)
 		}
-		item, err := db.ItemForID(id)
 		if err != nil {
-			log.Fatalf("Unable to execute query for item %d component %d: %v", itemID, id, err)
+			return nil, err
 		}
-		results = append(results, evego.InventoryLine{Quantity: quantity, Item: item})
+		results = append(results, evego.InventoryLine{Quantity: c.quantity, Item: item})
 	}
 	return results, nil
 }
 
-// MarketGroupForItem returns the parent groups of the market item.
-func (db *sqlDb) MarketGroupForItem(item *evego.Item) (*evego.MarketGroup, error) {
-	rows, err := db.catTreeFromItemStatement.Query(item.ID)
+func (db *sqlDb) ItemsCompositionContext(ctx context.Context, itemIDs []int) (map[int][]evego.InventoryLine, error) {
+	type material struct {
+		typeID   int
+		id       int
+		quantity int
+	}
+	var materials []material
+	err := inBatches(itemIDs, func(batch []int) error {
+		query, args, err := sqlx.In(itemsComposition, batch)
+		if err != nil {
+			return dbError("build item composition query", err)
+		}
+		rows, err := db.db.QueryContext(ctx, db.db.Rebind(query), args...)
+		if err != nil {
+			return dbError("get item compositions", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m material
+			err = rows.Scan(&m.typeID, &m.id, &m.quantity)
+			if err != nil {
+				return dbError("scan item composition", err)
+			}
+			materials = append(materials, m)
+		}
+		if err = rows.Err(); err != nil {
+			return dbError("get item compositions", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Look up each material once, after we're done with the rows.
+	seen := make(map[int]bool)
+	var materialIDs []int
+	for _, m := range materials {
+		if !seen[m.id] {
+			seen[m.id] = true
+			materialIDs = append(materialIDs, m.id)
+		}
+	}
+	found, err := db.ItemsForIDsContext(ctx, materialIDs)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[int]*evego.Item, len(found))
+	for _, item := range found {
+		items[item.ID] = item
+	}
+
+	results := make(map[int][]evego.InventoryLine)
+	for _, m := range materials {
+		item, ok := items[m.id]
+		if !ok {
+			return nil, inconsistent("item %d has nonexistent component %d", m.typeID, m.id)
+		}
+		results[m.typeID] = append(results[m.typeID], evego.InventoryLine{Quantity: m.quantity, Item: item})
+	}
+	return results, nil
+}
+
+// MarketGroupForItemContext returns the parent groups of the market item.
+func (db *sqlDb) MarketGroupForItemContext(ctx context.Context, item *evego.Item) (*evego.MarketGroup, error) {
+	rows, err := db.catTreeFromItemStatement.QueryContext(ctx, item.ID)
 	// The query doesn't return ErrNoRows, so we'll check for that case
 	// below.
 	if err != nil {
-		log.Fatalf("Unable to execute query: %v", err)
+		return nil, dbError("get market group for item", err)
 	}
 	defer rows.Close()
 	var itemGroup *evego.MarketGroup
@@ -193,7 +461,10 @@ func (db *sqlDb) MarketGroupForItem(item *evego.Item) (*evego.MarketGroup, error
 	hasRows := false
 	for rows.Next() {
 		hasRows = true
-		rows.Scan(&groupID, &groupName, &description, &parentID, &parentName, &parentDescription)
+		err = rows.Scan(&groupID, &groupName, &description, &parentID, &parentName, &parentDescription)
+		if err != nil {
+			return nil, dbError("scan market group", err)
+		}
 		nextLevel := &evego.MarketGroup{
 			ID:          groupID,
 			Name:        groupName,
@@ -211,6 +482,9 @@ func (db *sqlDb) MarketGroupForItem(item *evego.Item) (*evego.MarketGroup, error
 		}
 		curLevel = nextLevel
 	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get market group for item", err)
+	}
 	if !hasRows {
 		// No rows when expected, so return an error.
 		return nil, sql.ErrNoRows
@@ -227,91 +501,483 @@ func (db *sqlDb) MarketGroupForItem(item *evego.Item) (*evego.MarketGroup, error
 	return itemGroup, nil
 }
 
+// marketGroupExists returns sql.ErrNoRows if there is no market group with
+// the given ID.
+func (db *sqlDb) marketGroupExists(ctx context.Context, groupID int) error {
+	var group snapMarketGroup
+	err := db.marketGroupInfoStmt.QueryRowxContext(ctx, groupID).StructScan(&group)
+	return dbError("get market group", err)
+}
+
+func (db *sqlDb) MarketGroupChildrenContext(ctx context.Context, groupID int) ([]evego.MarketGroup, error) {
+	if groupID != 0 {
+		if err := db.marketGroupExists(ctx, groupID); err != nil {
+			return nil, err
+		}
+	}
+	var rows []snapMarketGroup
+	err := db.marketGroupChildrenStmt.SelectContext(ctx, &rows, groupID)
+	if err != nil {
+		return nil, dbError("get market group children", err)
+	}
+	groups := make([]evego.MarketGroup, 0, len(rows))
+	for _, r := range rows {
+		groups = append(groups, r.marketGroup())
+	}
+	return groups, nil
+}
+
+func (db *sqlDb) ItemsInMarketGroupContext(ctx context.Context, groupID int, recursive bool) ([]*evego.Item, error) {
+	if err := db.marketGroupExists(ctx, groupID); err != nil {
+		return nil, err
+	}
+	stmt := db.itemsInMarketGroupStmt
+	if recursive {
+		stmt = db.itemsInMarketGroupTreeStmt
+	}
+	rows, err := stmt.QueryxContext(ctx, groupID)
+	if err != nil {
+		return nil, dbError("get items in market group", err)
+	}
+	items, err := scanUntypedItems(rows)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := db.allMarketGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := marketGroupMap(groups)
+	if !recursive {
+		for _, item := range items {
+			item.Type = marketItemType(byID, groupID)
+		}
+		return items, nil
+	}
+
+	// The items may be in any of the group's subgroups.
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	groupOf := make(map[int]int, len(items))
+	err = inBatches(ids, func(batch []int) error {
+		query, args, err := sqlx.In(marketGroupsOfTypes, batch)
+		if err != nil {
+			return dbError("build market group query", err)
+		}
+		var typeGroups []typeMarketGroup
+		err = db.db.SelectContext(ctx, &typeGroups, db.db.Rebind(query), args...)
+		if err != nil {
+			return dbError("get market groups of items", err)
+		}
+		for _, t := range typeGroups {
+			groupOf[t.TypeID] = t.MarketGroupID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		item.Type = marketItemType(byID, groupOf[item.ID])
+	}
+	return items, nil
+}
+
+// typeMarketGroup is an item type's market group.
+type typeMarketGroup struct {
+	TypeID        int `db:"typeID"`
+	MarketGroupID int `db:"marketGroupID"`
+}
+
+// allMarketGroups returns every market group.
+func (db *sqlDb) allMarketGroups(ctx context.Context) ([]snapMarketGroup, error) {
+	var groups []snapMarketGroup
+	err := db.db.SelectContext(ctx, &groups, db.db.Rebind(allMarketGroups))
+	if err != nil {
+		return nil, dbError("get market groups", err)
+	}
+	return groups, nil
+}
+
+// marketGroupMap returns market groups keyed by ID.
+func marketGroupMap(groups []snapMarketGroup) map[int]*snapMarketGroup {
+	byID := make(map[int]*snapMarketGroup, len(groups))
+	for i := range groups {
+		byID[groups[i].ID] = &groups[i]
+	}
+	return byID
+}
+
+func (db *sqlDb) MarketTreeContext(ctx context.Context) ([]*evego.MarketGroupNode, error) {
+	groups, err := db.allMarketGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var typeGroups []typeMarketGroup
+	err = db.db.SelectContext(ctx, &typeGroups, marketGroupTypes)
+	if err != nil {
+		return nil, dbError("get market items", err)
+	}
+	groupOf := make(map[int]int, len(typeGroups))
+	ids := make([]int, 0, len(typeGroups))
+	for _, t := range typeGroups {
+		groupOf[t.TypeID] = t.MarketGroupID
+		ids = append(ids, t.TypeID)
+	}
+	// Every market group is at hand, so work out the items' types from them
+	// rather than querying each item's groups.
+	found, err := db.untypedItemsForIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := marketGroupMap(groups)
+	items := make(map[int][]*evego.Item)
+	for _, item := range found {
+		group := groupOf[item.ID]
+		item.Type = marketItemType(byID, group)
+		items[group] = append(items[group], item)
+	}
+	return marketTree(groups, items), nil
+}
+
 // itemType returns the type of this item, as required for reprocessing yield
-// calculation. It's either ore, ice, or other.
-func (db *sqlDb) itemType(item *evego.Item) evego.ItemType {
-	catTree, err := db.MarketGroupForItem(item)
+// calculation. It's either ore, ice, or other; items not on the market are of
+// unknown type.
+func (db *sqlDb) itemType(ctx context.Context, item *evego.Item) (evego.ItemType, error) {
+	catTree, err := db.MarketGroupForItemContext(ctx, item)
+	if err == sql.ErrNoRows {
+		return evego.UnknownItemType, nil
+	}
 	if err != nil {
-		return evego.UnknownItemType
+		return evego.UnknownItemType, err
 	}
 	for cur := catTree; cur != nil; cur = cur.Parent {
 		if cur.Name == "Ore" {
-			return evego.Ore
+			return evego.Ore, nil
 		}
 		if cur.Name == "Ice Ore" {
-			return evego.Ice
+			return evego.Ice, nil
 		}
 	}
-	// Ret
-	return evego.Other
+	return evego.Other, nil
 }
 
 func (db *sqlDb) Close() error {
 	return db.db.Close()
 }
 
-func (db *sqlDb) SolarSystemForName(systemName string) (*evego.SolarSystem, error) {
-	row := db.systemInfoStatement.QueryRowx(systemName)
+func (db *sqlDb) SolarSystemForNameContext(ctx context.Context, systemName string) (*evego.SolarSystem, error) {
 	system := &evego.SolarSystem{}
-	err := row.StructScan(system)
-	return system, err
+	err := db.systemInfoStatement.QueryRowxContext(ctx, systemName).StructScan(system)
+	if err != nil {
+		return nil, dbError("get solar system", err)
+	}
+	return system, nil
 }
 
-func (db *sqlDb) SolarSystemsForPattern(systemName string) ([]evego.SolarSystem, error) {
-	rows, err := db.systemInfoStatement.Queryx(systemName)
+func (db *sqlDb) SolarSystemsForPatternContext(ctx context.Context, systemName string) ([]evego.SolarSystem, error) {
+	rows, err := db.systemInfoStatement.QueryxContext(ctx, systemName)
 	if err != nil {
-		return nil, err
+		return nil, dbError("get solar systems", err)
 	}
 	defer rows.Close()
 	var systems []evego.SolarSystem
 	for rows.Next() {
 		system := evego.SolarSystem{}
-		rows.StructScan(&system)
+		err = rows.StructScan(&system)
+		if err != nil {
+			return nil, dbError("scan solar system", err)
+		}
 		systems = append(systems, system)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get solar systems", err)
+	}
 	if len(systems) == 0 {
-		err = sql.ErrNoRows
+		return nil, sql.ErrNoRows
 	}
-	return systems, err
+	return systems, nil
 }
 
-func (db *sqlDb) SolarSystemForID(systemID int) (*evego.SolarSystem, error) {
-	row := db.systemIDInfoStatement.QueryRowx(systemID)
+func (db *sqlDb) SolarSystemForIDContext(ctx context.Context, systemID int) (*evego.SolarSystem, error) {
 	system := &evego.SolarSystem{}
-	err := row.StructScan(system)
-	return system, err
+	err := db.systemIDInfoStatement.QueryRowxContext(ctx, systemID).StructScan(system)
+	if err != nil {
+		return nil, dbError("get solar system", err)
+	}
+	return system, nil
 }
 
-func (db *sqlDb) RegionForName(regionName string) (*evego.Region, error) {
-	row := db.regionInfoStatement.QueryRowx(regionName)
+func (db *sqlDb) RegionForNameContext(ctx context.Context, regionName string) (*evego.Region, error) {
 	region := &evego.Region{}
-	err := row.StructScan(region)
-	return region, err
+	err := db.regionInfoStatement.QueryRowxContext(ctx, regionName).StructScan(region)
+	if err != nil {
+		return nil, dbError("get region", err)
+	}
+	return region, nil
 }
 
-func (db *sqlDb) StationForID(stationID int) (*evego.Station, error) {
-	row := db.stationIDInfoStatement.QueryRowx(stationID)
+func (db *sqlDb) StationForIDContext(ctx context.Context, stationID int) (*evego.Station, error) {
 	station := &evego.Station{}
-	err := row.StructScan(station)
-	return station, err
+	err := db.stationIDInfoStatement.QueryRowxContext(ctx, stationID).StructScan(station)
+	if err != nil {
+		return nil, dbError("get station", err)
+	}
+	return station, nil
 }
 
-func (db *sqlDb) StationsForName(stationName string) ([]evego.Station, error) {
-	rows, err := db.stationNameInfoStatement.Queryx(stationName)
+func (db *sqlDb) StationsForNameContext(ctx context.Context, stationName string) ([]evego.Station, error) {
+	rows, err := db.stationNameInfoStatement.QueryxContext(ctx, stationName)
 	if err != nil {
-		return nil, err
+		return nil, dbError("get stations", err)
 	}
 	defer rows.Close()
 	var stations []evego.Station
 	for rows.Next() {
 		station := evego.Station{}
-		rows.StructScan(&station)
+		err = rows.StructScan(&station)
+		if err != nil {
+			return nil, dbError("scan station", err)
+		}
 		stations = append(stations, station)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get stations", err)
+	}
 	if len(stations) == 0 {
-		err = sql.ErrNoRows
+		return nil, sql.ErrNoRows
 	}
-	return stations, err
+	return stations, nil
+}
+
+func (db *sqlDb) regionForID(ctx context.Context, regionID int) (*evego.Region, error) {
+	region := &evego.Region{}
+	err := db.regionIDInfoStmt.QueryRowxContext(ctx, regionID).StructScan(region)
+	if err != nil {
+		return nil, dbError("get region", err)
+	}
+	return region, nil
+}
+
+func (db *sqlDb) ConstellationForIDContext(ctx context.Context, constellationID int) (*evego.Constellation, error) {
+	constellation := &evego.Constellation{}
+	err := db.constellationIDInfoStmt.QueryRowxContext(ctx, constellationID).StructScan(constellation)
+	if err != nil {
+		return nil, dbError("get constellation", err)
+	}
+	return constellation, nil
+}
+
+func (db *sqlDb) ConstellationsForRegionContext(ctx context.Context, regionID int) ([]evego.Constellation, error) {
+	if _, err := db.regionForID(ctx, regionID); err != nil {
+		return nil, err
+	}
+	var constellations []evego.Constellation
+	err := db.regionConstellationsStmt.SelectContext(ctx, &constellations, regionID)
+	if err != nil {
+		return nil, dbError("get constellations", err)
+	}
+	return constellations, nil
+}
+
+func (db *sqlDb) SolarSystemsForConstellationContext(ctx context.Context, constellationID int) ([]evego.SolarSystem, error) {
+	if _, err := db.ConstellationForIDContext(ctx, constellationID); err != nil {
+		return nil, err
+	}
+	var systems []evego.SolarSystem
+	err := db.constellationSystemsStmt.SelectContext(ctx, &systems, constellationID)
+	if err != nil {
+		return nil, dbError("get solar systems", err)
+	}
+	return systems, nil
+}
+
+func (db *sqlDb) SolarSystemsForRegionContext(ctx context.Context, regionID int) ([]evego.SolarSystem, error) {
+	if _, err := db.regionForID(ctx, regionID); err != nil {
+		return nil, err
+	}
+	var systems []evego.SolarSystem
+	err := db.regionSystemsStmt.SelectContext(ctx, &systems, regionID)
+	if err != nil {
+		return nil, dbError("get solar systems", err)
+	}
+	return systems, nil
+}
+
+func (db *sqlDb) SolarSystemNeighborsContext(ctx context.Context, systemID int) ([]evego.SolarSystem, error) {
+	if _, err := db.SolarSystemForIDContext(ctx, systemID); err != nil {
+		return nil, err
+	}
+	var systems []evego.SolarSystem
+	err := db.systemNeighborsStmt.SelectContext(ctx, &systems, systemID)
+	if err != nil {
+		return nil, dbError("get neighboring solar systems", err)
+	}
+	return systems, nil
+}
+
+// celestials returns the objects of one inventory group in a solar system.
+func (db *sqlDb) celestials(ctx context.Context, systemID, groupID int) ([]snapCelestial, error) {
+	if _, err := db.SolarSystemForIDContext(ctx, systemID); err != nil {
+		return nil, err
+	}
+	var rows []snapCelestial
+	err := db.systemCelestialsStmt.SelectContext(ctx, &rows, systemID, groupID)
+	if err != nil {
+		return nil, dbError("get celestials", err)
+	}
+	return rows, nil
+}
+
+func (db *sqlDb) StargatesForSystemContext(ctx context.Context, systemID int) ([]evego.Stargate, error) {
+	rows, err := db.celestials(ctx, systemID, groupStargate)
+	if err != nil {
+		return nil, err
+	}
+	return stargates(rows), nil
+}
+
+func (db *sqlDb) PlanetsForSystemContext(ctx context.Context, systemID int) ([]evego.Planet, error) {
+	rows, err := db.celestials(ctx, systemID, groupPlanet)
+	if err != nil {
+		return nil, err
+	}
+	return planets(rows), nil
+}
+
+func (db *sqlDb) MoonsForSystemContext(ctx context.Context, systemID int) ([]evego.Moon, error) {
+	rows, err := db.celestials(ctx, systemID, groupMoon)
+	if err != nil {
+		return nil, err
+	}
+	return moons(rows), nil
+}
+
+func (db *sqlDb) AsteroidBeltsForSystemContext(ctx context.Context, systemID int) ([]evego.AsteroidBelt, error) {
+	rows, err := db.celestials(ctx, systemID, groupAsteroidBelt)
+	if err != nil {
+		return nil, err
+	}
+	return asteroidBelts(rows), nil
+}
+
+// regionCelestials returns the objects of one inventory group in a region.
+func (db *sqlDb) regionCelestials(ctx context.Context, regionID, groupID int) ([]snapCelestial, error) {
+	if _, err := db.regionForID(ctx, regionID); err != nil {
+		return nil, err
+	}
+	var rows []snapCelestial
+	err := db.regionCelestialsStmt.SelectContext(ctx, &rows, regionID, groupID)
+	if err != nil {
+		return nil, dbError("get celestials", err)
+	}
+	return rows, nil
+}
+
+func (db *sqlDb) StargatesForRegionContext(ctx context.Context, regionID int) ([]evego.Stargate, error) {
+	rows, err := db.regionCelestials(ctx, regionID, groupStargate)
+	if err != nil {
+		return nil, err
+	}
+	return stargates(rows), nil
+}
+
+func (db *sqlDb) PlanetsForRegionContext(ctx context.Context, regionID int) ([]evego.Planet, error) {
+	rows, err := db.regionCelestials(ctx, regionID, groupPlanet)
+	if err != nil {
+		return nil, err
+	}
+	return planets(rows), nil
+}
+
+func (db *sqlDb) MoonsForRegionContext(ctx context.Context, regionID int) ([]evego.Moon, error) {
+	rows, err := db.regionCelestials(ctx, regionID, groupMoon)
+	if err != nil {
+		return nil, err
+	}
+	return moons(rows), nil
+}
+
+func (db *sqlDb) AsteroidBeltsForRegionContext(ctx context.Context, regionID int) ([]evego.AsteroidBelt, error) {
+	rows, err := db.regionCelestials(ctx, regionID, groupAsteroidBelt)
+	if err != nil {
+		return nil, err
+	}
+	return asteroidBelts(rows), nil
+}
+
+func (db *sqlDb) FactionForIDContext(ctx context.Context, factionID int) (*evego.Faction, error) {
+	faction := &evego.Faction{}
+	err := db.factionIDInfoStmt.QueryRowxContext(ctx, factionID).StructScan(faction)
+	if err != nil {
+		return nil, dbError("get faction", err)
+	}
+	return faction, nil
+}
+
+func (db *sqlDb) FactionsContext(ctx context.Context) ([]evego.Faction, error) {
+	var factions []evego.Faction
+	err := db.db.SelectContext(ctx, &factions, db.db.Rebind(allFactions))
+	if err != nil {
+		return nil, dbError("get factions", err)
+	}
+	return factions, nil
+}
+
+func (db *sqlDb) NPCCorporationForIDContext(ctx context.Context, corporationID int) (*evego.NPCCorp, error) {
+	corp := &evego.NPCCorp{}
+	err := db.npcCorpIDInfoStmt.QueryRowxContext(ctx, corporationID).StructScan(corp)
+	if err != nil {
+		return nil, dbError("get NPC corporation", err)
+	}
+	return corp, nil
+}
+
+func (db *sqlDb) NPCCorporationsForFactionContext(ctx context.Context, factionID int) ([]evego.NPCCorp, error) {
+	if _, err := db.FactionForIDContext(ctx, factionID); err != nil {
+		return nil, err
+	}
+	var corps []evego.NPCCorp
+	err := db.factionNPCCorpsStmt.SelectContext(ctx, &corps, factionID)
+	if err != nil {
+		return nil, dbError("get NPC corporations", err)
+	}
+	return corps, nil
+}
+
+func (db *sqlDb) AgentForIDContext(ctx context.Context, agentID int) (*evego.Agent, error) {
+	agent := &evego.Agent{}
+	err := db.agentIDInfoStmt.QueryRowxContext(ctx, agentID).StructScan(agent)
+	if err != nil {
+		return nil, dbError("get agent", err)
+	}
+	return agent, nil
+}
+
+func (db *sqlDb) AgentsForCorporationContext(ctx context.Context, corporationID int) ([]evego.Agent, error) {
+	if _, err := db.NPCCorporationForIDContext(ctx, corporationID); err != nil {
+		return nil, err
+	}
+	var agents []evego.Agent
+	err := db.corporationAgentsStmt.SelectContext(ctx, &agents, corporationID)
+	if err != nil {
+		return nil, dbError("get agents", err)
+	}
+	return agents, nil
+}
+
+func (db *sqlDb) AgentsForStationContext(ctx context.Context, stationID int) ([]evego.Agent, error) {
+	if _, err := db.StationForIDContext(ctx, stationID); err != nil {
+		return nil, err
+	}
+	var agents []evego.Agent
+	err := db.stationAgentsStmt.SelectContext(ctx, &agents, stationID)
+	if err != nil {
+		return nil, dbError("get agents", err)
+	}
+	return agents, nil
 }
 
 func activityToTypeCode(activityStr string) evego.ActivityType {
@@ -332,38 +998,55 @@ func activityToTypeCode(activityStr string) evego.ActivityType {
 		return evego.ReverseEngineering
 	case "Invention":
 		return evego.Invention
+	case "Reactions", "Reaction":
+		return evego.Reactions
 	}
 	// Unknown
 	return evego.None
 }
 
-func (db *sqlDb) blueprintQuery(stmt *sqlx.Stmt, query string) ([]evego.IndustryActivity, error) {
-	rows, err := stmt.Queryx(query)
+func (db *sqlDb) blueprintQuery(ctx context.Context, stmt *sqlx.Stmt, query string) ([]evego.IndustryActivity, error) {
+	rows, err := stmt.QueryxContext(ctx, query)
 	if err != nil {
-		log.Fatalf("Unable to execute query: %v", err)
+		return nil, dbError("get industry activities", err)
 	}
 	defer rows.Close()
-	var results []evego.IndustryActivity
+	type activityRow struct {
+		InputItem        string `db:"inputItem"`
+		ActivityName     string `db:"activityName"`
+		OutputProduct    string `db:"outputProduct"`
+		OutputProductQty int    `db:"outputProductQty"`
+	}
+	var activities []activityRow
 	for rows.Next() {
-		row := struct {
-			InputItem        string `db:"inputItem"`
-			ActivityName     string `db:"activityName"`
-			OutputProduct    string `db:"outputProduct"`
-			OutputProductQty int    `db:"outputProductQty"`
-		}{}
+		row := activityRow{}
 		err = rows.StructScan(&row)
 		if err != nil {
-			return nil, fmt.Errorf("Error parsing returned industry query: %v", err)
+			return nil, dbError("scan industry activity", err)
 		}
-		input, err := db.ItemForName(row.InputItem)
-		if err != nil {
+		activities = append(activities, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get industry activities", err)
+	}
+	rows.Close()
+
+	var results []evego.IndustryActivity
+	for _, row := range activities {
+		input, err := db.ItemForNameContext(ctx, row.InputItem)
+		if err == sql.ErrNoRows {
 			// This would indicate some major error in the database, but we like
 			// checking for such errors even if it puts a dent in our unit-test
 			// coverage numbers.
-			return nil, fmt.Errorf("Couldn't find item %v with row: %#v (%d results so far)",
-				row.InputItem, row, len(results))
+			return nil, inconsistent("couldn't find item %v with row: %#v", row.InputItem, row)
+		}
+		if err != nil {
+			return nil, err
+		}
+		output, err := db.ItemForNameContext(ctx, row.OutputProduct)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("couldn't find item %v with row: %#v", row.OutputProduct, row)
 		}
-		output, err := db.ItemForName(row.OutputProduct)
 		if err != nil {
 			return nil, err
 		}
@@ -379,46 +1062,58 @@ func (db *sqlDb) blueprintQuery(stmt *sqlx.Stmt, query string) ([]evego.Industry
 	return results, nil
 }
 
-func (db *sqlDb) BlueprintOutputs(typeName string) ([]evego.IndustryActivity, error) {
-	return db.blueprintQuery(db.blueprintProducesStmt, typeName)
+func (db *sqlDb) BlueprintOutputsContext(ctx context.Context, typeName string) ([]evego.IndustryActivity, error) {
+	return db.blueprintQuery(ctx, db.blueprintProducesStmt, typeName)
 }
 
-func (db *sqlDb) BlueprintForProduct(typeName string) ([]evego.IndustryActivity, error) {
-	return db.blueprintQuery(db.blueprintProducedByStmt, typeName)
+func (db *sqlDb) BlueprintForProductContext(ctx context.Context, typeName string) ([]evego.IndustryActivity, error) {
+	return db.blueprintQuery(ctx, db.blueprintProducedByStmt, typeName)
 }
 
-func (db *sqlDb) BlueprintsUsingMaterial(typeName string) ([]evego.IndustryActivity, error) {
+func (db *sqlDb) BlueprintsUsingMaterialContext(ctx context.Context, typeName string) ([]evego.IndustryActivity, error) {
 
-	return db.blueprintQuery(db.inputMaterialsToBlueprintStmt, typeName)
+	return db.blueprintQuery(ctx, db.inputMaterialsToBlueprintStmt, typeName)
 }
 
-func (db *sqlDb) BlueprintProductionInputs(
+func (db *sqlDb) BlueprintProductionInputsContext(ctx context.Context,
 	typeName string, outputTypeName string) ([]evego.InventoryLine, error) {
-	rows, err := db.matsForBPProductionStmt.Queryx(typeName, outputTypeName)
+	rows, err := db.matsForBPProductionStmt.QueryxContext(ctx, typeName, outputTypeName)
 	if err != nil {
-		log.Fatalf("Unable to execute query: %v", err)
+		return nil, dbError("get blueprint inputs", err)
 	}
 	defer rows.Close()
-	var results []evego.InventoryLine
+	type materialRow struct {
+		InputItem        string `db:"inputItem"`
+		ActivityName     string `db:"activityName"`
+		InputMaterial    string `db:"inputMaterial"`
+		OutputProduct    string `db:"outputProduct"`
+		InputMaterialQty int    `db:"inputMaterialQty"`
+		OutputProductQty int    `db:"outputProductQty"`
+	}
+	var materials []materialRow
 	for rows.Next() {
-		row := struct {
-			InputItem        string `db:"inputItem"`
-			ActivityName     string `db:"activityName"`
-			InputMaterial    string `db:"inputMaterial"`
-			OutputProduct    string `db:"outputProduct"`
-			InputMaterialQty int    `db:"inputMaterialQty"`
-			OutputProductQty int    `db:"outputProductQty"`
-		}{}
+		row := materialRow{}
 		err = rows.StructScan(&row)
 		if err != nil {
-			log.Fatalf("Unable to scan struct: %v", err)
+			return nil, dbError("scan blueprint input", err)
 		}
+		materials = append(materials, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get blueprint inputs", err)
+	}
+	rows.Close()
 
+	var results []evego.InventoryLine
+	for _, row := range materials {
 		// Process into an InventoryLine.
-		inputMat, err := db.ItemForName(row.InputMaterial)
+		inputMat, err := db.ItemForNameContext(ctx, row.InputMaterial)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("input material %v of %v not available",
+				row.InputMaterial, row.InputItem)
+		}
 		if err != nil {
-			log.Fatalf("Database inconsistency error: item %#v not available; %v",
-				row.InputItem, err)
+			return nil, err
 		}
 		result := evego.InventoryLine{
 			Quantity: row.InputMaterialQty,
@@ -430,19 +1125,28 @@ func (db *sqlDb) BlueprintProductionInputs(
 	return results, nil
 }
 
-func (db *sqlDb) ReprocessOutputMaterials() ([]evego.Item, error) {
-	rows, err := db.reprocessOutputsStmt.Query()
+func (db *sqlDb) ReprocessOutputMaterialsContext(ctx context.Context) ([]evego.Item, error) {
+	rows, err := db.reprocessOutputsStmt.QueryContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to execute query: %v", err)
+		return nil, dbError("get reprocessing outputs", err)
 	}
-	items := make([]evego.Item, 0, 410)
+	defer rows.Close()
+	var typeIDs []int
 	for rows.Next() {
 		var typeID int
 		err = rows.Scan(&typeID)
 		if err != nil {
-			return nil, fmt.Errorf("Unable to execute query: %v", err)
+			return nil, dbError("scan reprocessing output", err)
 		}
-		item, err := db.ItemForID(typeID)
+		typeIDs = append(typeIDs, typeID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get reprocessing outputs", err)
+	}
+	rows.Close()
+	items := make([]evego.Item, 0, 410)
+	for _, typeID := range typeIDs {
+		item, err := db.ItemForIDContext(ctx, typeID)
 		if err != nil {
 			return nil, err
 		}
@@ -450,3 +1154,326 @@ func (db *sqlDb) ReprocessOutputMaterials() ([]evego.Item, error) {
 	}
 	return items, nil
 }
+
+func (db *sqlDb) BlueprintsInputsContext(ctx context.Context, typeIDs []int) (map[evego.BlueprintActivity][]evego.InventoryLine, error) {
+	type materialRow struct {
+		TypeID         int    `db:"typeID"`
+		ActivityName   string `db:"activityName"`
+		MaterialTypeID int    `db:"materialTypeID"`
+		Quantity       int    `db:"quantity"`
+	}
+	var materials []materialRow
+	err := inBatches(typeIDs, func(batch []int) error {
+		query, args, err := sqlx.In(blueprintsInputs, batch)
+		if err != nil {
+			return dbError("build blueprint input query", err)
+		}
+		var rows []materialRow
+		err = db.db.SelectContext(ctx, &rows, db.db.Rebind(query), args...)
+		if err != nil {
+			return dbError("get blueprint inputs", err)
+		}
+		materials = append(materials, rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Look up each material once.
+	seen := make(map[int]bool)
+	var materialIDs []int
+	for _, m := range materials {
+		if !seen[m.MaterialTypeID] {
+			seen[m.MaterialTypeID] = true
+			materialIDs = append(materialIDs, m.MaterialTypeID)
+		}
+	}
+	found, err := db.ItemsForIDsContext(ctx, materialIDs)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[int]*evego.Item, len(found))
+	for _, item := range found {
+		items[item.ID] = item
+	}
+
+	results := make(map[evego.BlueprintActivity][]evego.InventoryLine)
+	for _, m := range materials {
+		item, ok := items[m.MaterialTypeID]
+		if !ok {
+			return nil, inconsistent("input material %v of %v not available",
+				m.MaterialTypeID, m.TypeID)
+		}
+		key := evego.BlueprintActivity{BlueprintID: m.TypeID, Activity: activityToTypeCode(m.ActivityName)}
+		results[key] = append(results[key], evego.InventoryLine{Quantity: m.Quantity, Item: item})
+	}
+	return results, nil
+}
+
+// reactionMaterials returns the inputs or outputs (depending on the statement
+// passed) of one run of a reaction formula.
+func (db *sqlDb) reactionMaterials(ctx context.Context, stmt *sqlx.Stmt, formulaID int) ([]evego.InventoryLine, error) {
+	rows, err := stmt.QueryContext(ctx, formulaID)
+	if err != nil {
+		return nil, dbError("get reaction materials", err)
+	}
+	defer rows.Close()
+
+	type material struct {
+		id       int
+		quantity int
+	}
+	var materials []material
+	for rows.Next() {
+		var m material
+		err = rows.Scan(&m.id, &m.quantity)
+		if err != nil {
+			return nil, dbError("scan reaction material", err)
+		}
+		materials = append(materials, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get reaction materials", err)
+	}
+	rows.Close()
+
+	var results []evego.InventoryLine
+	for _, m := range materials {
+		item, err := db.ItemForIDContext(ctx, m.id)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("reaction formula %d has nonexistent material %d", formulaID, m.id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, evego.InventoryLine{Quantity: m.quantity, Item: item})
+	}
+	return results, nil
+}
+
+func (db *sqlDb) reactionQuery(ctx context.Context, stmt *sqlx.Stmt, query string) ([]evego.Reaction, error) {
+	rows, err := stmt.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("get reaction formulas", err)
+	}
+	defer rows.Close()
+	type formulaRow struct {
+		typeID  int
+		seconds int
+	}
+	// Read all the formulas before querying for their materials, so that we're
+	// not holding open a connection while making more queries.
+	var formulas []formulaRow
+	for rows.Next() {
+		var f formulaRow
+		err = rows.Scan(&f.typeID, &f.seconds)
+		if err != nil {
+			return nil, dbError("scan reaction formula", err)
+		}
+		formulas = append(formulas, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError("get reaction formulas", err)
+	}
+	rows.Close()
+	if len(formulas) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	results := make([]evego.Reaction, 0, len(formulas))
+	for _, f := range formulas {
+		formula, err := db.ItemForIDContext(ctx, f.typeID)
+		if err == sql.ErrNoRows {
+			return nil, inconsistent("reaction formula %d doesn't exist", f.typeID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		inputs, err := db.reactionMaterials(ctx, db.reactionInputsStmt, f.typeID)
+		if err != nil {
+			return nil, err
+		}
+		outputs, err := db.reactionMaterials(ctx, db.reactionOutputsStmt, f.typeID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, evego.Reaction{
+			Formula: formula,
+			Inputs:  inputs,
+			Outputs: outputs,
+			Time:    time.Duration(f.seconds) * time.Second,
+		})
+	}
+	return results, nil
+}
+
+func (db *sqlDb) ReactionFormulasContext(ctx context.Context, typeName string) ([]evego.Reaction, error) {
+	return db.reactionQuery(ctx, db.reactionFormulasStmt, typeName)
+}
+
+func (db *sqlDb) ReactionsForProductContext(ctx context.Context, typeName string) ([]evego.Reaction, error) {
+	return db.reactionQuery(ctx, db.reactionsForProductStmt, typeName)
+}
+
+// attributeRow is a row of dgmAttributeTypes; most of its columns are
+// nullable.
+type attributeRow struct {
+	ID           int             `db:"attributeID"`
+	Name         string          `db:"attributeName"`
+	DisplayName  sql.NullString  `db:"displayName"`
+	Description  sql.NullString  `db:"description"`
+	DefaultValue sql.NullFloat64 `db:"defaultValue"`
+	UnitID       sql.NullInt64   `db:"unitID"`
+	Published    sql.NullBool    `db:"published"`
+	Stackable    sql.NullBool    `db:"stackable"`
+	HighIsGood   sql.NullBool    `db:"highIsGood"`
+}
+
+func (row *attributeRow) attribute() *evego.DogmaAttribute {
+	return &evego.DogmaAttribute{
+		ID:           row.ID,
+		Name:         row.Name,
+		DisplayName:  row.DisplayName.String,
+		Description:  row.Description.String,
+		DefaultValue: row.DefaultValue.Float64,
+		UnitID:       int(row.UnitID.Int64),
+		Published:    row.Published.Bool,
+		Stackable:    row.Stackable.Bool,
+		HighIsGood:   row.HighIsGood.Bool,
+	}
+}
+
+func (db *sqlDb) attributeQuery(ctx context.Context, stmt *sqlx.Stmt, query interface{}) (*evego.DogmaAttribute, error) {
+	row := attributeRow{}
+	err := stmt.QueryRowxContext(ctx, query).StructScan(&row)
+	if err != nil {
+		return nil, dbError("get attribute", err)
+	}
+	return row.attribute(), nil
+}
+
+func (db *sqlDb) AttributeForIDContext(ctx context.Context, attributeID int) (*evego.DogmaAttribute, error) {
+	return db.attributeQuery(ctx, db.attributeForIDStmt, attributeID)
+}
+
+func (db *sqlDb) AttributeForNameContext(ctx context.Context, attributeName string) (*evego.DogmaAttribute, error) {
+	return db.attributeQuery(ctx, db.attributeForNameStmt, attributeName)
+}
+
+func (db *sqlDb) ItemAttributesContext(ctx context.Context, typeID int) ([]evego.ItemAttribute, error) {
+	// This is a convenience function for ItemsAttributes.
+	attrs, err := db.ItemsAttributesContext(ctx, []int{typeID})
+	if err != nil {
+		return nil, err
+	}
+	return attrs[typeID], nil
+}
+
+func (db *sqlDb) ItemsAttributesContext(ctx context.Context, typeIDs []int) (map[int][]evego.ItemAttribute, error) {
+	results := make(map[int][]evego.ItemAttribute)
+	err := inBatches(typeIDs, func(batch []int) error {
+		query, args, err := sqlx.In(itemsAttributes, batch)
+		if err != nil {
+			return dbError("build item attribute query", err)
+		}
+		rows, err := db.db.QueryxContext(ctx, db.db.Rebind(query), args...)
+		if err != nil {
+			return dbError("get item attributes", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			attr := evego.ItemAttribute{}
+			err = rows.StructScan(&attr)
+			if err != nil {
+				return dbError("scan item attribute", err)
+			}
+			results[attr.TypeID] = append(results[attr.TypeID], attr)
+		}
+		if err = rows.Err(); err != nil {
+			return dbError("get item attributes", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (db *sqlDb) ItemAttributeValueContext(ctx context.Context, typeID, attributeID int) (float64, error) {
+	attr := evego.ItemAttribute{}
+	err := db.itemAttributeValueStmt.QueryRowxContext(ctx, typeID, attributeID).StructScan(&attr)
+	if err != nil {
+		return 0, dbError("get item attribute", err)
+	}
+	return attr.Value, nil
+}
+
+func (db *sqlDb) ItemAttributeValueForNameContext(ctx context.Context, typeID int, attributeName string) (float64, error) {
+	attr := evego.ItemAttribute{}
+	err := db.itemAttributeValueForNameStmt.QueryRowxContext(ctx, typeID, attributeName).StructScan(&attr)
+	if err != nil {
+		return 0, dbError("get item attribute", err)
+	}
+	return attr.Value, nil
+}
+
+func (db *sqlDb) ItemEffectsContext(ctx context.Context, typeID int) ([]evego.ItemEffect, error) {
+	// This is a convenience function for ItemsEffects.
+	effects, err := db.ItemsEffectsContext(ctx, []int{typeID})
+	if err != nil {
+		return nil, err
+	}
+	return effects[typeID], nil
+}
+
+func (db *sqlDb) ItemsEffectsContext(ctx context.Context, typeIDs []int) (map[int][]evego.ItemEffect, error) {
+	results := make(map[int][]evego.ItemEffect)
+	err := inBatches(typeIDs, func(batch []int) error {
+		query, args, err := sqlx.In(itemsEffects, batch)
+		if err != nil {
+			return dbError("build item effect query", err)
+		}
+		rows, err := db.db.QueryxContext(ctx, db.db.Rebind(query), args...)
+		if err != nil {
+			return dbError("get item effects", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			row := effectRow{}
+			err = rows.StructScan(&row)
+			if err != nil {
+				return dbError("scan item effect", err)
+			}
+			results[row.TypeID] = append(results[row.TypeID], row.effect())
+		}
+		if err = rows.Err(); err != nil {
+			return dbError("get item effects", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// effectRow is a row of dgmTypeEffects joined with dgmEffects.
+type effectRow struct {
+	TypeID    int           `db:"typeID"`
+	EffectID  int           `db:"effectID"`
+	Name      string        `db:"effectName"`
+	Category  sql.NullInt64 `db:"effectCategory"`
+	IsDefault sql.NullBool  `db:"isDefault"`
+}
+
+func (row *effectRow) effect() evego.ItemEffect {
+	return evego.ItemEffect{
+		TypeID:    row.TypeID,
+		EffectID:  row.EffectID,
+		Name:      row.Name,
+		Category:  int(row.Category.Int64),
+		IsDefault: row.IsDefault.Bool,
+	}
+}
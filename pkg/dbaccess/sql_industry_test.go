@@ -22,9 +22,9 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/backerman/evego"
-	"github.com/backerman/evego/pkg/dbaccess"
 
 	. "github.com/backerman/evego/pkg/test"
 	. "github.com/smartystreets/goconvey/convey"
@@ -70,7 +70,7 @@ func shouldMatchActivities(actual interface{}, expected ...interface{}) string {
 func TestBlueprints(t *testing.T) {
 
 	Convey("Open a database connection", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db := openTestDatabase()
 
 		Convey("With a valid input blueprint", func() {
 			typeName := "Vexor Blueprint"
@@ -150,6 +150,16 @@ func TestBlueprints(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(actual, ShouldHaveComposition, expected)
 			})
+			Convey("Bulk lookup gives the same material requirements.", func() {
+				bp, err := db.ItemForName(inBP)
+				So(err, ShouldBeNil)
+				expected, err := db.BlueprintProductionInputs(inBP, outBP)
+				So(err, ShouldBeNil)
+				actual, err := db.BlueprintsInputs([]int{bp.ID})
+				So(err, ShouldBeNil)
+				key := evego.BlueprintActivity{BlueprintID: bp.ID, Activity: evego.Invention}
+				So(actual[key], ShouldResemble, expected)
+			})
 		})
 
 		Convey("With a valid output", func() {
@@ -176,6 +186,57 @@ func TestBlueprints(t *testing.T) {
 	})
 }
 
+func TestReactionFormulas(t *testing.T) {
+
+	Convey("Open a database connection", t, func() {
+		db := openTestDatabase()
+
+		Convey("With a valid reaction formula", func() {
+			formulaName := "Carbon Polymers Reaction Formula"
+
+			Convey("We get its inputs, outputs, and time.", func() {
+				actual, err := db.ReactionFormulas(formulaName)
+				So(err, ShouldBeNil)
+				So(actual, ShouldHaveLength, 1)
+				reaction := actual[0]
+				So(reaction.Formula.Name, ShouldEqual, formulaName)
+				So(reaction.Inputs, ShouldHaveComposition, []Component{
+					{Quantity: 100, Name: "Hydrocarbons"},
+					{Quantity: 100, Name: "Silicates"},
+					{Quantity: 5, Name: "Helium Fuel Block"},
+				})
+				So(reaction.Outputs, ShouldHaveComposition, []Component{
+					{Quantity: 200, Name: "Carbon Polymers"},
+				})
+				So(reaction.Time, ShouldEqual, 3*time.Hour)
+			})
+
+			Convey("Its outputs are identified as a reaction.", func() {
+				actual, err := db.BlueprintOutputs(formulaName)
+				So(err, ShouldBeNil)
+				So(actual, ShouldNotBeEmpty)
+				So(actual[0].ActivityType, ShouldEqual, evego.Reactions)
+			})
+		})
+
+		Convey("With a reaction product", func() {
+			Convey("We get the formula that produces it.", func() {
+				actual, err := db.ReactionsForProduct("Carbon Polymers")
+				So(err, ShouldBeNil)
+				So(actual, ShouldHaveLength, 1)
+				So(actual[0].Formula.Name, ShouldEqual, "Carbon Polymers Reaction Formula")
+			})
+		})
+
+		Convey("With an invalid reaction formula", func() {
+			Convey("An error is returned.", func() {
+				_, err := db.ReactionFormulas("Unobtainium Reaction Formula")
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
 // shouldContainItem takes a slice of Items and a type ID, and passes if some
 // item in the slice has the input type ID.
 func shouldContainItem(actual interface{}, expected ...interface{}) string {
@@ -220,7 +281,7 @@ func shouldNotContainItem(actual interface{}, expected ...interface{}) string {
 
 func TestReprocessOutputs(t *testing.T) {
 	Convey("Open a database connection.", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db := openTestDatabase()
 
 		Convey("Get the list of reprocessing outputs", func() {
 			outputs, err := db.ReprocessOutputMaterials()
@@ -0,0 +1,240 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import (
+	"context"
+
+	"github.com/backerman/evego"
+)
+
+// The methods of sqlDb that don't take a context run their queries under
+// context.Background().
+
+func (db *sqlDb) ItemForName(itemName string) (*evego.Item, error) {
+	return db.ItemForNameContext(context.Background(), itemName)
+}
+
+func (db *sqlDb) ItemForID(itemID int) (*evego.Item, error) {
+	return db.ItemForIDContext(context.Background(), itemID)
+}
+
+func (db *sqlDb) ItemsForIDs(itemIDs []int) ([]*evego.Item, error) {
+	return db.ItemsForIDsContext(context.Background(), itemIDs)
+}
+
+func (db *sqlDb) ItemComposition(itemID int) ([]evego.InventoryLine, error) {
+	return db.ItemCompositionContext(context.Background(), itemID)
+}
+
+func (db *sqlDb) ItemsComposition(itemIDs []int) (map[int][]evego.InventoryLine, error) {
+	return db.ItemsCompositionContext(context.Background(), itemIDs)
+}
+
+func (db *sqlDb) MarketGroupForItem(item *evego.Item) (*evego.MarketGroup, error) {
+	return db.MarketGroupForItemContext(context.Background(), item)
+}
+
+func (db *sqlDb) MarketGroupChildren(groupID int) ([]evego.MarketGroup, error) {
+	return db.MarketGroupChildrenContext(context.Background(), groupID)
+}
+
+func (db *sqlDb) ItemsInMarketGroup(groupID int, recursive bool) ([]*evego.Item, error) {
+	return db.ItemsInMarketGroupContext(context.Background(), groupID, recursive)
+}
+
+func (db *sqlDb) MarketTree() ([]*evego.MarketGroupNode, error) {
+	return db.MarketTreeContext(context.Background())
+}
+
+func (db *sqlDb) ItemIDs() ([]int, error) {
+	return db.ItemIDsContext(context.Background())
+}
+
+func (db *sqlDb) SearchItems(query string, limit int) ([]evego.ItemMatch, error) {
+	return db.SearchItemsContext(context.Background(), query, limit)
+}
+
+func (db *sqlDb) SolarSystemForID(systemID int) (*evego.SolarSystem, error) {
+	return db.SolarSystemForIDContext(context.Background(), systemID)
+}
+
+func (db *sqlDb) SolarSystemForName(systemName string) (*evego.SolarSystem, error) {
+	return db.SolarSystemForNameContext(context.Background(), systemName)
+}
+
+func (db *sqlDb) SolarSystemsForPattern(systemName string) ([]evego.SolarSystem, error) {
+	return db.SolarSystemsForPatternContext(context.Background(), systemName)
+}
+
+func (db *sqlDb) RegionForName(regionName string) (*evego.Region, error) {
+	return db.RegionForNameContext(context.Background(), regionName)
+}
+
+func (db *sqlDb) StationForID(stationID int) (*evego.Station, error) {
+	return db.StationForIDContext(context.Background(), stationID)
+}
+
+func (db *sqlDb) StationsForName(stationName string) ([]evego.Station, error) {
+	return db.StationsForNameContext(context.Background(), stationName)
+}
+
+func (db *sqlDb) ConstellationForID(constellationID int) (*evego.Constellation, error) {
+	return db.ConstellationForIDContext(context.Background(), constellationID)
+}
+
+func (db *sqlDb) ConstellationsForRegion(regionID int) ([]evego.Constellation, error) {
+	return db.ConstellationsForRegionContext(context.Background(), regionID)
+}
+
+func (db *sqlDb) SolarSystemsForConstellation(constellationID int) ([]evego.SolarSystem, error) {
+	return db.SolarSystemsForConstellationContext(context.Background(), constellationID)
+}
+
+func (db *sqlDb) SolarSystemsForRegion(regionID int) ([]evego.SolarSystem, error) {
+	return db.SolarSystemsForRegionContext(context.Background(), regionID)
+}
+
+func (db *sqlDb) SolarSystemNeighbors(systemID int) ([]evego.SolarSystem, error) {
+	return db.SolarSystemNeighborsContext(context.Background(), systemID)
+}
+
+func (db *sqlDb) StargatesForSystem(systemID int) ([]evego.Stargate, error) {
+	return db.StargatesForSystemContext(context.Background(), systemID)
+}
+
+func (db *sqlDb) PlanetsForSystem(systemID int) ([]evego.Planet, error) {
+	return db.PlanetsForSystemContext(context.Background(), systemID)
+}
+
+func (db *sqlDb) MoonsForSystem(systemID int) ([]evego.Moon, error) {
+	return db.MoonsForSystemContext(context.Background(), systemID)
+}
+
+func (db *sqlDb) AsteroidBeltsForSystem(systemID int) ([]evego.AsteroidBelt, error) {
+	return db.AsteroidBeltsForSystemContext(context.Background(), systemID)
+}
+
+func (db *sqlDb) StargatesForRegion(regionID int) ([]evego.Stargate, error) {
+	return db.StargatesForRegionContext(context.Background(), regionID)
+}
+
+func (db *sqlDb) PlanetsForRegion(regionID int) ([]evego.Planet, error) {
+	return db.PlanetsForRegionContext(context.Background(), regionID)
+}
+
+func (db *sqlDb) MoonsForRegion(regionID int) ([]evego.Moon, error) {
+	return db.MoonsForRegionContext(context.Background(), regionID)
+}
+
+func (db *sqlDb) AsteroidBeltsForRegion(regionID int) ([]evego.AsteroidBelt, error) {
+	return db.AsteroidBeltsForRegionContext(context.Background(), regionID)
+}
+
+func (db *sqlDb) FactionForID(factionID int) (*evego.Faction, error) {
+	return db.FactionForIDContext(context.Background(), factionID)
+}
+
+func (db *sqlDb) Factions() ([]evego.Faction, error) {
+	return db.FactionsContext(context.Background())
+}
+
+func (db *sqlDb) NPCCorporationForID(corporationID int) (*evego.NPCCorp, error) {
+	return db.NPCCorporationForIDContext(context.Background(), corporationID)
+}
+
+func (db *sqlDb) NPCCorporationsForFaction(factionID int) ([]evego.NPCCorp, error) {
+	return db.NPCCorporationsForFactionContext(context.Background(), factionID)
+}
+
+func (db *sqlDb) AgentForID(agentID int) (*evego.Agent, error) {
+	return db.AgentForIDContext(context.Background(), agentID)
+}
+
+func (db *sqlDb) AgentsForCorporation(corporationID int) ([]evego.Agent, error) {
+	return db.AgentsForCorporationContext(context.Background(), corporationID)
+}
+
+func (db *sqlDb) AgentsForStation(stationID int) ([]evego.Agent, error) {
+	return db.AgentsForStationContext(context.Background(), stationID)
+}
+
+func (db *sqlDb) BlueprintOutputs(typeName string) ([]evego.IndustryActivity, error) {
+	return db.BlueprintOutputsContext(context.Background(), typeName)
+}
+
+func (db *sqlDb) BlueprintForProduct(typeName string) ([]evego.IndustryActivity, error) {
+	return db.BlueprintForProductContext(context.Background(), typeName)
+}
+
+func (db *sqlDb) BlueprintsUsingMaterial(typeName string) ([]evego.IndustryActivity, error) {
+	return db.BlueprintsUsingMaterialContext(context.Background(), typeName)
+}
+
+func (db *sqlDb) BlueprintsInputs(typeIDs []int) (map[evego.BlueprintActivity][]evego.InventoryLine, error) {
+	return db.BlueprintsInputsContext(context.Background(), typeIDs)
+}
+
+func (db *sqlDb) BlueprintProductionInputs(
+	typeName string, outputTypeName string) ([]evego.InventoryLine, error) {
+	return db.BlueprintProductionInputsContext(context.Background(), typeName, outputTypeName)
+}
+
+func (db *sqlDb) ReactionFormulas(typeName string) ([]evego.Reaction, error) {
+	return db.ReactionFormulasContext(context.Background(), typeName)
+}
+
+func (db *sqlDb) ReactionsForProduct(typeName string) ([]evego.Reaction, error) {
+	return db.ReactionsForProductContext(context.Background(), typeName)
+}
+
+func (db *sqlDb) AttributeForID(attributeID int) (*evego.DogmaAttribute, error) {
+	return db.AttributeForIDContext(context.Background(), attributeID)
+}
+
+func (db *sqlDb) AttributeForName(attributeName string) (*evego.DogmaAttribute, error) {
+	return db.AttributeForNameContext(context.Background(), attributeName)
+}
+
+func (db *sqlDb) ItemAttributes(typeID int) ([]evego.ItemAttribute, error) {
+	return db.ItemAttributesContext(context.Background(), typeID)
+}
+
+func (db *sqlDb) ItemsAttributes(typeIDs []int) (map[int][]evego.ItemAttribute, error) {
+	return db.ItemsAttributesContext(context.Background(), typeIDs)
+}
+
+func (db *sqlDb) ItemAttributeValue(typeID, attributeID int) (float64, error) {
+	return db.ItemAttributeValueContext(context.Background(), typeID, attributeID)
+}
+
+func (db *sqlDb) ItemAttributeValueForName(typeID int, attributeName string) (float64, error) {
+	return db.ItemAttributeValueForNameContext(context.Background(), typeID, attributeName)
+}
+
+func (db *sqlDb) ItemEffects(typeID int) ([]evego.ItemEffect, error) {
+	return db.ItemEffectsContext(context.Background(), typeID)
+}
+
+func (db *sqlDb) ItemsEffects(typeIDs []int) (map[int][]evego.ItemEffect, error) {
+	return db.ItemsEffectsContext(context.Background(), typeIDs)
+}
+
+func (db *sqlDb) ReprocessOutputMaterials() ([]evego.Item, error) {
+	return db.ReprocessOutputMaterialsContext(context.Background())
+}
@@ -28,24 +28,42 @@ var (
   AND t."typeID" = m."typeID"
   AND mt."typeID" = m."materialTypeID"
   `
-	itemInfo = `
-  SELECT t."typeID", t."typeName", t."portionSize", g."groupID", g."groupName", c."categoryName"
-  FROM "invTypes" t, "invCategories" c, "invGroups" g
-  WHERE t."typeName" = ? AND t."groupID" = g."groupID"
-  AND   g."categoryID" = c."categoryID"
+	// What are the materials of these items? (for use with sqlx.In)
+	itemsComposition = `
+  SELECT m."typeID", m."materialTypeID", m."quantity"
+  FROM "invTypeMaterials" m
+  WHERE m."typeID" IN (?)
+  ORDER BY m."typeID", m."materialTypeID"
   `
-	itemIDInfo = `
-  SELECT t."typeID", t."typeName", t."portionSize", g."groupID", g."groupName", c."categoryName"
-  FROM "invTypes" t, "invCategories" c, "invGroups" g
-  WHERE t."typeID" = ? AND t."groupID" = g."groupID"
-  AND   g."categoryID" = c."categoryID"
+	// Ships and containers have a packaged volume (in invVolumes) that's
+	// different from their assembled volume.
+	itemBase = `
+  SELECT t."typeID", t."typeName", t."portionSize", g."groupID", g."groupName", c."categoryName",
+         COALESCE(t."volume", 0) "volume",
+         COALESCE(v."volume", t."volume", 0) "packagedVolume",
+         COALESCE(t."capacity", 0) "capacity", COALESCE(t."mass", 0) "mass",
+         COALESCE(t."basePrice", 0) "basePrice"
+  FROM "invTypes" t
+  JOIN "invGroups" g ON t."groupID" = g."groupID"
+  JOIN "invCategories" c ON g."categoryID" = c."categoryID"
+  LEFT JOIN "invVolumes" v ON v."typeID" = t."typeID"
+  WHERE QUERYCOLUMN
   `
+	itemInfo   = strings.Replace(itemBase, "QUERYCOLUMN", "t.\"typeName\" = ?", 1)
+	itemIDInfo = strings.Replace(itemBase, "QUERYCOLUMN", "t.\"typeID\" = ?", 1)
 
-	itemIDsInfo = `
-  SELECT t."typeID", t."typeName", t."portionSize", g."groupID", g."groupName", c."categoryName"
-  FROM "invTypes" t, "invCategories" c, "invGroups" g
-  WHERE t."typeID" IN (?) AND t."groupID" = g."groupID"
-  AND   g."categoryID" = c."categoryID"
+	itemIDsInfo = strings.Replace(itemBase, "QUERYCOLUMN", "t.\"typeID\" IN (?)", 1)
+
+	// Every item's type ID.
+	allItemIDs = `
+  SELECT "typeID" FROM "invTypes" ORDER BY "typeID"
+  `
+
+	// Every item's name, for the search index.
+	allItemNames = `
+  SELECT "typeID", "typeName", "published", "marketGroupID"
+  FROM "invTypes"
+  WHERE "typeName" IS NOT NULL
   `
 
 	catTree = `
@@ -64,37 +82,160 @@ var (
     FROM "invMarketGroups" mg
     INNER JOIN parents p ON mg."marketGroupID"=p."parentGroupID"
   )
-  SELECT p."marketGroupID", m1."marketGroupName", m1."description",
-				 p."parentGroupID", m2."marketGroupName", m2."description"
+  SELECT p."marketGroupID", m1."marketGroupName", COALESCE(m1."description", ''),
+				 p."parentGroupID", m2."marketGroupName", COALESCE(m2."description", '')
   FROM parents p
   JOIN "invMarketGroups" m1 ON p."marketGroupID" = m1."marketGroupID"
   JOIN "invMarketGroups" m2 ON p."parentGroupID" = m2."marketGroupID"
   `
 
-	systemInfo = `
+	marketGroupBase = `
+  SELECT "marketGroupID", COALESCE("parentGroupID", 0) "parentGroupID",
+         "marketGroupName", COALESCE("description", '') "description"
+  FROM   "invMarketGroups"
+  WHERE  QUERYCOLUMN
+  ORDER BY "marketGroupName", "marketGroupID"
+  `
+	marketGroupInfo     = strings.Replace(marketGroupBase, "QUERYCOLUMN", "\"marketGroupID\" = ?", 1)
+	marketGroupChildren = strings.Replace(marketGroupBase, "QUERYCOLUMN", "COALESCE(\"parentGroupID\", 0) = ?", 1)
+	allMarketGroups     = strings.Replace(marketGroupBase, "QUERYCOLUMN", "1 = 1", 1)
+
+	itemsInMarketGroup = strings.Replace(itemBase, "QUERYCOLUMN",
+		"t.\"marketGroupID\" = ? ORDER BY t.\"typeName\", t.\"typeID\"", 1)
+	itemsInMarketGroupTree = strings.Replace(itemBase, "QUERYCOLUMN", `t."marketGroupID" IN (
+    WITH RECURSIVE subgroups("marketGroupID") AS (
+      SELECT "marketGroupID" FROM "invMarketGroups" WHERE "marketGroupID" = ?
+      UNION
+      SELECT mg."marketGroupID"
+      FROM   "invMarketGroups" mg
+      JOIN   subgroups s ON mg."parentGroupID" = s."marketGroupID"
+    )
+    SELECT "marketGroupID" FROM subgroups
+  )
+  ORDER BY t."typeName", t."typeID"`, 1)
+
+	// The market group of every item on the market.
+	marketGroupTypes = `
+  SELECT "typeID", "marketGroupID"
+  FROM   "invTypes"
+  WHERE  "marketGroupID" IS NOT NULL
+  `
+
+	// The market group of each of these items. (for use with sqlx.In)
+	marketGroupsOfTypes = `
+  SELECT "typeID", "marketGroupID"
+  FROM   "invTypes"
+  WHERE  "marketGroupID" IS NOT NULL AND "typeID" IN (?)
+  `
+
+	systemBase = `
       SELECT   s."solarSystemName", s."solarSystemID", s."security",
                c."constellationName", c."constellationID", r."regionName", r."regionID"
       FROM     "mapSolarSystems" s
       JOIN     "mapConstellations" c USING("constellationID")
       JOIN     "mapRegions" r ON r."regionID" = c."regionID"
-      WHERE    LOWER(s."solarSystemName") LIKE LOWER(?)
-			ORDER BY s."solarSystemName"
+      WHERE    QUERYCOLUMN
+      ORDER BY s."solarSystemName"
       `
+	systemInfo   = strings.Replace(systemBase, "QUERYCOLUMN", "LOWER(s.\"solarSystemName\") LIKE LOWER(?)", 1)
+	systemIDInfo = strings.Replace(systemBase, "QUERYCOLUMN", "s.\"solarSystemID\" = ?", 1)
 
-	systemIDInfo = `
-      SELECT s."solarSystemName", s."solarSystemID", s."security",
-             c."constellationName", c."constellationID", r."regionName", r."regionID"
-      FROM   "mapSolarSystems" s
-      JOIN   "mapConstellations" c USING("constellationID")
-      JOIN   "mapRegions" r ON r."regionID" = c."regionID"
-      WHERE  s."solarSystemID" = ?
-      `
+	// The systems in a constellation or region, and a system's neighbours.
+	constellationSystems = strings.Replace(systemBase, "QUERYCOLUMN", "c.\"constellationID\" = ?", 1)
+	regionSystems        = strings.Replace(systemBase, "QUERYCOLUMN", "r.\"regionID\" = ?", 1)
+	systemNeighbors      = strings.Replace(systemBase, "QUERYCOLUMN", `s."solarSystemID" IN (
+        SELECT "toSolarSystemID"
+        FROM   "mapSolarSystemJumps"
+        WHERE  "fromSolarSystemID" = ?
+      )`, 1)
 
-	regionInfo = `
+	regionBase = `
       SELECT "regionID", "regionName"
       FROM   "mapRegions"
-      WHERE  "regionName" = ?
+      WHERE  QUERYCOLUMN = ?
+      `
+	regionInfo   = strings.Replace(regionBase, "QUERYCOLUMN", "\"regionName\"", 1)
+	regionIDInfo = strings.Replace(regionBase, "QUERYCOLUMN", "\"regionID\"", 1)
+
+	constellationBase = `
+      SELECT   c."constellationName", c."constellationID", r."regionName", r."regionID",
+               COALESCE(c."x", 0) "x", COALESCE(c."y", 0) "y", COALESCE(c."z", 0) "z"
+      FROM     "mapConstellations" c
+      JOIN     "mapRegions" r ON r."regionID" = c."regionID"
+      WHERE    QUERYCOLUMN = ?
+      ORDER BY c."constellationName"
+      `
+	constellationIDInfo  = strings.Replace(constellationBase, "QUERYCOLUMN", "c.\"constellationID\"", 1)
+	regionConstellations = strings.Replace(constellationBase, "QUERYCOLUMN", "r.\"regionID\"", 1)
+
+	factionBase = `
+      SELECT   "factionID", "factionName", COALESCE("description", '') "description",
+               COALESCE("solarSystemID", 0) "solarSystemID",
+               COALESCE("corporationID", 0) "corporationID",
+               COALESCE("militiaCorporationID", 0) "militiaCorporationID"
+      FROM     "chrFactions"
+      WHERE    QUERYCOLUMN
+      ORDER BY "factionName"
       `
+	factionIDInfo = strings.Replace(factionBase, "QUERYCOLUMN", "\"factionID\" = ?", 1)
+	allFactions   = strings.Replace(factionBase, "QUERYCOLUMN", "1 = 1", 1)
+
+	// NPC corporations' and agents' names are in invNames.
+	npcCorpBase = `
+      SELECT   c."corporationID", n."itemName" "corporationName",
+               COALESCE(c."factionID", 0) "factionID",
+               COALESCE(f."factionName", '') "factionName",
+               COALESCE(c."solarSystemID", 0) "solarSystemID"
+      FROM     "crpNPCCorporations" c
+      JOIN     "invNames" n ON n."itemID" = c."corporationID"
+      LEFT JOIN "chrFactions" f ON f."factionID" = c."factionID"
+      WHERE    QUERYCOLUMN
+      ORDER BY n."itemName"
+      `
+	npcCorpIDInfo   = strings.Replace(npcCorpBase, "QUERYCOLUMN", "c.\"corporationID\" = ?", 1)
+	factionNPCCorps = strings.Replace(npcCorpBase, "QUERYCOLUMN", "c.\"factionID\" = ?", 1)
+	allNPCCorps     = strings.Replace(npcCorpBase, "QUERYCOLUMN", "1 = 1", 1)
+
+	agentBase = `
+      SELECT   a."agentID", n."itemName" "agentName", a."agentTypeID",
+               a."corporationID", a."divisionID",
+               COALESCE(d."divisionName", '') "divisionName", a."level", a."locationID"
+      FROM     "agtAgents" a
+      JOIN     "invNames" n ON n."itemID" = a."agentID"
+      LEFT JOIN "crpNPCDivisions" d ON d."divisionID" = a."divisionID"
+      WHERE    QUERYCOLUMN
+      ORDER BY n."itemName"
+      `
+	agentIDInfo       = strings.Replace(agentBase, "QUERYCOLUMN", "a.\"agentID\" = ?", 1)
+	corporationAgents = strings.Replace(agentBase, "QUERYCOLUMN", "a.\"corporationID\" = ?", 1)
+	stationAgents     = strings.Replace(agentBase, "QUERYCOLUMN", "a.\"locationID\" = ?", 1)
+	allAgents         = strings.Replace(agentBase, "QUERYCOLUMN", "1 = 1", 1)
+
+	// Planets, moons, asteroid belts, and stargates are in mapDenormalize;
+	// stargates also have a destination in mapJumps.
+	celestialBase = `
+      SELECT   d."itemID", COALESCE(d."itemName", '') "itemName", d."groupID",
+               d."solarSystemID", d."typeID", COALESCE(t."typeName", '') "typeName",
+               COALESCE(d."orbitID", 0) "orbitID",
+               COALESCE(j."destinationID", 0) "destinationID",
+               COALESCE(dd."solarSystemID", 0) "destinationSystemID",
+               COALESCE(d."x", 0) "x", COALESCE(d."y", 0) "y", COALESCE(d."z", 0) "z"
+      FROM     "mapDenormalize" d
+      LEFT JOIN "invTypes" t ON t."typeID" = d."typeID"
+      LEFT JOIN "mapJumps" j ON j."stargateID" = d."itemID"
+      LEFT JOIN "mapDenormalize" dd ON dd."itemID" = j."destinationID"
+      WHERE    QUERYCOLUMN
+      ORDER BY d."itemID"
+      `
+	systemCelestials = strings.Replace(celestialBase, "QUERYCOLUMN",
+		"d.\"solarSystemID\" = ? AND d.\"groupID\" = ?", 1)
+	// As with regionSystems, a system's region is that of its constellation.
+	regionCelestials = strings.Replace(celestialBase, "QUERYCOLUMN", `d."solarSystemID" IN (
+        SELECT s."solarSystemID"
+        FROM   "mapSolarSystems" s
+        JOIN   "mapConstellations" c USING("constellationID")
+        WHERE  c."regionID" = ?
+      ) AND d."groupID" = ?`, 1)
 
 	stationIDInfo = `
       SELECT "stationName", "stationID", "solarSystemID", "constellationID", "regionID",
@@ -157,6 +298,52 @@ var (
 		ORDER BY "inputItem", "outputProduct", "inputMaterial"
 		`
 
+	// The materials for every activity of these blueprints. (for use with
+	// sqlx.In)
+	blueprintsInputs = `
+		SELECT iam."typeID", "activityName", iam."materialTypeID", iam."quantity"
+		FROM   "industryActivityMaterials" iam
+		JOIN   "ramActivities" USING("activityID")
+		JOIN   "invTypes" tm
+		ON     iam."materialTypeID" = tm."typeID"
+		WHERE  iam."typeID" IN (?)
+		ORDER BY iam."typeID", iam."activityID", tm."typeName"
+		`
+
+	// Reaction formulas are activity 11 in ramActivities.
+	reactionBase = `
+		SELECT DISTINCT t."typeID", ia."time"
+		FROM   "industryActivity" ia
+		JOIN   "invTypes" t USING("typeID")
+		JOIN   "industryActivityProducts" iap
+		ON     iap."typeID" = ia."typeID" AND iap."activityID" = ia."activityID"
+		JOIN   "invTypes" tyo ON iap."productTypeID" = tyo."typeID"
+		WHERE  ia."activityID" = 11 AND QUERYCOLUMN LIKE ?
+		ORDER BY t."typeID"
+		`
+
+	// What does a reaction formula do?
+	reactionFormulas = strings.Replace(reactionBase, "QUERYCOLUMN", "t.\"typeName\"", 1)
+
+	// What reaction formulas produce this item?
+	reactionsForProduct = strings.Replace(reactionBase, "QUERYCOLUMN", "tyo.\"typeName\"", 1)
+
+	// The inputs to one run of a reaction.
+	reactionInputs = `
+		SELECT "materialTypeID", "quantity"
+		FROM   "industryActivityMaterials"
+		WHERE  "typeID" = ? AND "activityID" = 11
+		ORDER BY "materialTypeID"
+		`
+
+	// The outputs of one run of a reaction.
+	reactionOutputs = `
+		SELECT "productTypeID", "quantity"
+		FROM   "industryActivityProducts"
+		WHERE  "typeID" = ? AND "activityID" = 11
+		ORDER BY "productTypeID"
+		`
+
 	// What are the possible outputs from reprocessing an item?
 	reprocessOutputsStmt = `
 		SELECT t_mat."typeID"
@@ -167,4 +354,138 @@ var (
 		GROUP BY t_mat."typeID", t_mat."typeName"
 		ORDER BY t_mat."typeName"
 		`
+	attributeBase = `
+		SELECT "attributeID", "attributeName", "displayName", "description",
+		       "defaultValue", "unitID", "published", "stackable", "highIsGood"
+		FROM   "dgmAttributeTypes"
+		WHERE  QUERYCOLUMN = ?
+		`
+
+	// Look up an attribute definition by ID or name.
+	attributeForID   = strings.Replace(attributeBase, "QUERYCOLUMN", "\"attributeID\"", 1)
+	attributeForName = strings.Replace(attributeBase, "QUERYCOLUMN", "\"attributeName\"", 1)
+
+	// Dogma attribute values are stored as either integers or floats,
+	// depending on the vintage of the SDE conversion.
+	itemAttributesBase = `
+		SELECT ta."typeID", ta."attributeID", at."attributeName",
+		       COALESCE(ta."valueFloat", ta."valueInt") "value"
+		FROM   "dgmTypeAttributes" ta
+		JOIN   "dgmAttributeTypes" at USING("attributeID")
+		WHERE  ta."typeID" QUERYCOLUMN
+		ORDER BY ta."typeID", ta."attributeID"
+		`
+
+	// What are the attributes of these items? (for use with sqlx.In)
+	itemsAttributes = strings.Replace(itemAttributesBase, "QUERYCOLUMN", "IN (?)", 1)
+
+	// What is the value of this item's attribute?
+	itemAttributeValue = strings.Replace(itemAttributesBase, "QUERYCOLUMN",
+		"= ? AND ta.\"attributeID\" = ?", 1)
+	itemAttributeValueForName = strings.Replace(itemAttributesBase, "QUERYCOLUMN",
+		"= ? AND at.\"attributeName\" = ?", 1)
+
+	itemEffectsBase = `
+		SELECT te."typeID", te."effectID", e."effectName", e."effectCategory",
+		       te."isDefault"
+		FROM   "dgmTypeEffects" te
+		JOIN   "dgmEffects" e USING("effectID")
+		WHERE  QUERYCOLUMN
+		ORDER BY te."typeID", te."effectID"
+		`
+
+	// What are the effects of these items? (for use with sqlx.In)
+	itemsEffects = strings.Replace(itemEffectsBase, "QUERYCOLUMN", "te.\"typeID\" IN (?)", 1)
+	// The tables copied into a snapshot. Nullable columns are coalesced
+	// except for booleans, which have different types in different dialects.
+	snapshotTypes = `
+		SELECT t."typeID", COALESCE(t."typeName", '') "typeName", t."groupID",
+		       COALESCE(t."portionSize", 1) "portionSize",
+		       COALESCE(t."volume", 0) "volume",
+		       COALESCE(v."volume", t."volume", 0) "packagedVolume",
+		       COALESCE(t."capacity", 0) "capacity", COALESCE(t."mass", 0) "mass",
+		       COALESCE(t."basePrice", 0) "basePrice",
+		       COALESCE(t."marketGroupID", 0) "marketGroupID", t."published"
+		FROM   "invTypes" t
+		LEFT JOIN "invVolumes" v ON v."typeID" = t."typeID"
+		ORDER BY t."typeID"
+		`
+	snapshotGroups = `
+		SELECT "groupID", "groupName", "categoryID"
+		FROM   "invGroups"
+		ORDER BY "groupID"
+		`
+	snapshotCategories = `
+		SELECT "categoryID", "categoryName"
+		FROM   "invCategories"
+		ORDER BY "categoryID"
+		`
+	snapshotMarketGroups = `
+		SELECT "marketGroupID", COALESCE("parentGroupID", 0) "parentGroupID",
+		       "marketGroupName", COALESCE("description", '') "description"
+		FROM   "invMarketGroups"
+		ORDER BY "marketGroupID"
+		`
+	snapshotTypeMaterials = `
+		SELECT "typeID", "materialTypeID", "quantity"
+		FROM   "invTypeMaterials"
+		ORDER BY "typeID", "materialTypeID"
+		`
+	snapshotRegions = `
+		SELECT "regionID", "regionName"
+		FROM   "mapRegions"
+		ORDER BY "regionID"
+		`
+	snapshotConstellations = `
+		SELECT "constellationID", "constellationName", "regionID",
+		       COALESCE("x", 0) "x", COALESCE("y", 0) "y", COALESCE("z", 0) "z"
+		FROM   "mapConstellations"
+		ORDER BY "constellationID"
+		`
+	snapshotSolarSystems = `
+		SELECT "solarSystemID", "solarSystemName", "constellationID", "regionID",
+		       "security"
+		FROM   "mapSolarSystems"
+		ORDER BY "solarSystemID"
+		`
+	snapshotSystemJumps = `
+		SELECT "fromSolarSystemID", "toSolarSystemID"
+		FROM   "mapSolarSystemJumps"
+		ORDER BY "fromSolarSystemID", "toSolarSystemID"
+		`
+	snapshotCelestials = strings.Replace(celestialBase, "QUERYCOLUMN",
+		"d.\"groupID\" IN (7, 8, 9, 10)", 1)
+	snapshotStations = `
+		SELECT "stationName", "stationID", "solarSystemID", "constellationID", "regionID",
+		       "corporationID", "itemName" "corporationName", "reprocessingEfficiency"
+		FROM   "staStations" s
+		JOIN   "invNames" n ON n."itemID" = s."corporationID"
+		ORDER BY "stationID"
+		`
+	snapshotActivities = `
+		SELECT "activityID", "activityName"
+		FROM   "ramActivities"
+		ORDER BY "activityID"
+		`
+	snapshotIndustryActivities = `
+		SELECT "typeID", "activityID", "time"
+		FROM   "industryActivity"
+		ORDER BY "typeID", "activityID"
+		`
+	snapshotIndustryMaterials = `
+		SELECT "typeID", "activityID", "materialTypeID", "quantity"
+		FROM   "industryActivityMaterials"
+		ORDER BY "typeID", "activityID", "materialTypeID"
+		`
+	snapshotIndustryProducts = `
+		SELECT "typeID", "activityID", "productTypeID" "materialTypeID", "quantity"
+		FROM   "industryActivityProducts"
+		ORDER BY "typeID", "activityID", "productTypeID"
+		`
+	snapshotAttributes = strings.Replace(attributeBase, "QUERYCOLUMN = ?",
+		"\"attributeID\" IS NOT NULL ORDER BY \"attributeID\"", 1)
+	snapshotTypeAttributes = strings.Replace(itemAttributesBase, "QUERYCOLUMN",
+		"IS NOT NULL", 1)
+	snapshotTypeEffects = strings.Replace(itemEffectsBase, "QUERYCOLUMN",
+		"te.\"typeID\" IS NOT NULL", 1)
 )
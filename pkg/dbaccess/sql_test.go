@@ -18,6 +18,7 @@ limitations under the License.
 package dbaccess_test
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -35,15 +36,50 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-var testDbDriver, testDbPath string
+var testDbDriver, testDbPath, testBackend string
 
 func init() {
 	viper.SetDefault("DBDriver", "sqlite3")
 	viper.SetDefault("DBPath", "../../testdb.sqlite")
+	viper.SetDefault("Backend", "sql")
 	viper.SetEnvPrefix("EVEGO_TEST")
 	viper.AutomaticEnv()
 	testDbDriver = viper.GetString("DBDriver")
 	testDbPath = viper.GetString("DBPath")
+	testBackend = viper.GetString("Backend")
+}
+
+// openTestDatabase opens the test database. If EVEGO_TEST_BACKEND is set to
+// "memory", the test suite is run against a MemoryDatabase loaded from a
+// snapshot of the test database instead.
+func openTestDatabase() evego.Database {
+	if testBackend != "memory" {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		if err != nil {
+			panic(err)
+		}
+		return db
+	}
+	snap, err := testSnapshot()
+	if err != nil {
+		panic(err)
+	}
+	return dbaccess.MemoryDatabase(snap)
+}
+
+// testSnapshot takes a snapshot of the test database and round-trips it
+// through its binary form.
+func testSnapshot() (*dbaccess.Snapshot, error) {
+	snap, err := dbaccess.SnapshotFromSQL(testDbDriver, testDbPath)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = snap.Write(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return dbaccess.ReadSnapshot(&buf)
 }
 
 type testElement struct {
@@ -110,7 +146,7 @@ func shouldMatchSystem(actual interface{}, expected ...interface{}) string {
 
 func TestItems(t *testing.T) {
 	Convey("Open a database connection", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db := openTestDatabase()
 		defer db.Close()
 
 		Convey("With a valid item name", func() {
@@ -137,6 +173,8 @@ func TestItems(t *testing.T) {
 				So(actual.Category, ShouldEqual, expected.Category)
 				So(actual.Group, ShouldEqual, expected.Group)
 				So(actual.BatchSize, ShouldEqual, expected.BatchSize)
+				So(actual.Volume, ShouldBeGreaterThan, 0)
+				So(actual.PackagedVolume, ShouldEqual, actual.Volume)
 				So(mats, ShouldHaveComposition, []Component{
 					{"Tritanium", 1890},
 					{"Pyerite", 456},
@@ -173,13 +211,34 @@ func TestItems(t *testing.T) {
 			Convey("The correct information is returned.", func() {
 				actual, err := db.ItemForID(itemID)
 				So(err, ShouldBeNil)
-				So(actual, ShouldResemble, expected)
+				So(actual.Volume, ShouldEqual, 0.01)
+				So(withoutPhysicals(actual), ShouldResemble, expected)
 				mats, err := db.ItemComposition(itemID)
 				So(err, ShouldBeNil)
 				So(mats, ShouldBeEmpty) // skillbooks can't be reprocessed
 			})
 		})
 
+		Convey("Compositions can be fetched in bulk.", func() {
+			itemIDs := []int{3831, 3328, 1228} // Medium Shield Extender II, Gallente Frigate, Scordite
+			comps, err := db.ItemsComposition(itemIDs)
+			So(err, ShouldBeNil)
+			So(comps, ShouldHaveLength, 2)
+			So(comps, ShouldNotContainKey, 3328) // skillbooks can't be reprocessed
+			for _, id := range []int{3831, 1228} {
+				expected, err := db.ItemComposition(id)
+				So(err, ShouldBeNil)
+				quantities := make(map[int]int)
+				for _, m := range expected {
+					quantities[m.Item.ID] = m.Quantity
+				}
+				So(comps[id], ShouldHaveLength, len(expected))
+				for _, m := range comps[id] {
+					So(m.Quantity, ShouldEqual, quantities[m.Item.ID])
+				}
+			}
+		})
+
 		Convey("With an invalid item type ID", func() {
 			itemID := 1234567890
 
@@ -207,16 +266,42 @@ func TestItems(t *testing.T) {
 					Category:  "Module",
 					BatchSize: 1,
 				}
-				So(actual, ShouldResemble, expected)
+				So(withoutPhysicals(actual), ShouldResemble, expected)
+			})
+		})
+
+		Convey("With a ship", func() {
+			actual, err := db.ItemForName("Vexor")
+			So(err, ShouldBeNil)
+
+			Convey("Its packaged volume is returned.", func() {
+				So(actual.Volume, ShouldEqual, 115000)
+				So(actual.PackagedVolume, ShouldEqual, 10000)
+				So(actual.Mass, ShouldBeGreaterThan, 0)
+				So(actual.Capacity, ShouldBeGreaterThan, 0)
+			})
+
+			Convey("An inventory line of ships reports its packaged volume.", func() {
+				line := evego.InventoryLine{Quantity: 3, Item: actual}
+				So(line.Volume(), ShouldEqual, 30000)
 			})
 		})
 	})
 }
 
+// withoutPhysicals returns a copy of the item with its volume, mass, and price
+// cleared, for comparing identifying information only.
+func withoutPhysicals(item *evego.Item) *evego.Item {
+	result := *item
+	result.Volume, result.PackagedVolume = 0, 0
+	result.Capacity, result.Mass, result.BasePrice = 0, 0, 0
+	return &result
+}
+
 func TestSolarSystems(t *testing.T) {
 
 	Convey("Open a database connection", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db := openTestDatabase()
 
 		Convey("With a valid system name", func() {
 			systemName := "Poitot"
@@ -329,7 +414,7 @@ func TestSolarSystems(t *testing.T) {
 func TestStations(t *testing.T) {
 
 	Convey("Open a database connection", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db := openTestDatabase()
 
 		Convey("With a valid station ID", func() {
 			stationID := 60010312
@@ -395,7 +480,7 @@ func TestStations(t *testing.T) {
 func TestRegions(t *testing.T) {
 
 	Convey("Open a database connection", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db := openTestDatabase()
 
 		Convey("With a valid region name", func() {
 			regionName := "Outer Ring"
@@ -422,3 +507,420 @@ func TestRegions(t *testing.T) {
 		})
 	})
 }
+
+func TestUniverseTopology(t *testing.T) {
+
+	Convey("Open a database connection", t, func() {
+		db := openTestDatabase()
+		system, err := db.SolarSystemForName("Dodixie")
+		So(err, ShouldBeNil)
+
+		Convey("The system's constellation is found.", func() {
+			constellation, err := db.ConstellationForID(system.ConstellationID)
+			So(err, ShouldBeNil)
+			So(constellation.Name, ShouldEqual, system.Constellation)
+			So(constellation.RegionID, ShouldEqual, system.RegionID)
+			So(constellation.Position, ShouldNotResemble, evego.Position{})
+
+			constellations, err := db.ConstellationsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			So(constellations, ShouldContain, *constellation)
+		})
+
+		Convey("The system is in its constellation and region.", func() {
+			systems, err := db.SolarSystemsForConstellation(system.ConstellationID)
+			So(err, ShouldBeNil)
+			So(systems, ShouldContain, *system)
+			systems, err = db.SolarSystemsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			So(systems, ShouldContain, *system)
+		})
+
+		Convey("The system's neighbours are reached by its stargates.", func() {
+			gates, err := db.StargatesForSystem(system.ID)
+			So(err, ShouldBeNil)
+			So(gates, ShouldNotBeEmpty)
+			destinations := make(map[int]bool)
+			for _, g := range gates {
+				So(g.SystemID, ShouldEqual, system.ID)
+				So(g.DestinationID, ShouldNotEqual, 0)
+				destinations[g.DestinationSystemID] = true
+			}
+			neighbors, err := db.SolarSystemNeighbors(system.ID)
+			So(err, ShouldBeNil)
+			for _, n := range neighbors {
+				So(destinations[n.ID], ShouldBeTrue)
+			}
+		})
+
+		Convey("The system's moons and belts orbit its planets.", func() {
+			planets, err := db.PlanetsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			So(planets, ShouldNotBeEmpty)
+			planetIDs := make(map[int]bool)
+			for _, p := range planets {
+				So(p.Type, ShouldStartWith, "Planet (")
+				planetIDs[p.ID] = true
+			}
+			moons, err := db.MoonsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			So(moons, ShouldNotBeEmpty)
+			for _, m := range moons {
+				So(planetIDs[m.PlanetID], ShouldBeTrue)
+			}
+			belts, err := db.AsteroidBeltsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			So(belts, ShouldNotBeEmpty)
+			for _, b := range belts {
+				So(planetIDs[b.PlanetID], ShouldBeTrue)
+			}
+		})
+
+		Convey("The region's objects include the system's.", func() {
+			planets, err := db.PlanetsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			regionPlanets, err := db.PlanetsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			for _, p := range planets {
+				So(regionPlanets, ShouldContain, p)
+			}
+			moons, err := db.MoonsForSystem(system.ID)
+			So(err, ShouldBeNil)
+			regionMoons, err := db.MoonsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			for _, m := range moons {
+				So(regionMoons, ShouldContain, m)
+			}
+			belts, err := db.AsteroidBeltsForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			So(belts, ShouldNotBeEmpty)
+			gates, err := db.StargatesForRegion(system.RegionID)
+			So(err, ShouldBeNil)
+			So(gates, ShouldNotBeEmpty)
+		})
+
+		Convey("Invalid IDs return an error.", func() {
+			_, err := db.ConstellationForID(42)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.ConstellationsForRegion(42)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.SolarSystemsForRegion(42)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.SolarSystemNeighbors(42)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.PlanetsForSystem(42)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.PlanetsForRegion(42)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
+
+func TestNPCs(t *testing.T) {
+
+	Convey("Open a database connection", t, func() {
+		db := openTestDatabase()
+
+		Convey("With a valid corporation ID", func() {
+			corpID := 1000102
+
+			Convey("We get the corporation and its faction.", func() {
+				corp, err := db.NPCCorporationForID(corpID)
+				So(err, ShouldBeNil)
+				So(corp.Name, ShouldEqual, "Roden Shipyards")
+				So(corp.FactionID, ShouldEqual, 500004)
+				So(corp.Faction, ShouldEqual, "Gallente Federation")
+
+				faction, err := db.FactionForID(corp.FactionID)
+				So(err, ShouldBeNil)
+				So(faction.Name, ShouldEqual, "Gallente Federation")
+				factions, err := db.Factions()
+				So(err, ShouldBeNil)
+				So(factions, ShouldContain, *faction)
+
+				corps, err := db.NPCCorporationsForFaction(corp.FactionID)
+				So(err, ShouldBeNil)
+				So(corps, ShouldContain, *corp)
+			})
+
+			Convey("We get the corporation's agents.", func() {
+				agents, err := db.AgentsForCorporation(corpID)
+				So(err, ShouldBeNil)
+				So(agents, ShouldNotBeEmpty)
+				for _, a := range agents {
+					So(a.CorporationID, ShouldEqual, corpID)
+					So(a.Level, ShouldBeBetweenOrEqual, 1, 5)
+					So(a.Division, ShouldNotBeBlank)
+				}
+				agent, err := db.AgentForID(agents[0].ID)
+				So(err, ShouldBeNil)
+				So(*agent, ShouldResemble, agents[0])
+			})
+		})
+
+		Convey("With invalid IDs", func() {
+			Convey("An error is returned.", func() {
+				_, err := db.NPCCorporationForID(42)
+				So(err, ShouldEqual, sql.ErrNoRows)
+				_, err = db.FactionForID(42)
+				So(err, ShouldEqual, sql.ErrNoRows)
+				_, err = db.AgentForID(42)
+				So(err, ShouldEqual, sql.ErrNoRows)
+				_, err = db.AgentsForCorporation(42)
+				So(err, ShouldEqual, sql.ErrNoRows)
+				_, err = db.AgentsForStation(42)
+				So(err, ShouldEqual, sql.ErrNoRows)
+			})
+		})
+	})
+}
+
+func TestDogma(t *testing.T) {
+	Convey("Open a database connection", t, func() {
+		db := openTestDatabase()
+		defer db.Close()
+
+		Convey("Attribute definitions can be looked up", func() {
+			Convey("By ID.", func() {
+				attr, err := db.AttributeForID(evego.AttributeCPU)
+				So(err, ShouldBeNil)
+				So(attr.Name, ShouldEqual, "cpu")
+			})
+			Convey("By name.", func() {
+				attr, err := db.AttributeForName("metaLevel")
+				So(err, ShouldBeNil)
+				So(attr.ID, ShouldEqual, evego.AttributeMetaLevel)
+			})
+			Convey("But not if they don't exist.", func() {
+				_, err := db.AttributeForName("awesomeness")
+				So(err, ShouldEqual, sql.ErrNoRows)
+			})
+		})
+
+		Convey("With a valid item", func() {
+			itemID := 3831 // Medium Shield Extender II
+
+			Convey("Its attributes are returned.", func() {
+				attrs, err := db.ItemAttributes(itemID)
+				So(err, ShouldBeNil)
+				So(attrs, ShouldNotBeEmpty)
+				for _, a := range attrs {
+					So(a.TypeID, ShouldEqual, itemID)
+				}
+			})
+
+			Convey("Single attribute values can be looked up.", func() {
+				metaLevel, err := db.ItemAttributeValue(itemID, evego.AttributeMetaLevel)
+				So(err, ShouldBeNil)
+				So(metaLevel, ShouldEqual, 5)
+				techLevel, err := db.ItemAttributeValueForName(itemID, "techLevel")
+				So(err, ShouldBeNil)
+				So(techLevel, ShouldEqual, 2)
+				_, err = db.ItemAttributeValue(itemID, evego.AttributeHiSlots)
+				So(err, ShouldEqual, sql.ErrNoRows)
+			})
+
+			Convey("Its effects are returned.", func() {
+				effects, err := db.ItemEffects(itemID)
+				So(err, ShouldBeNil)
+				var effectIDs []int
+				for _, e := range effects {
+					effectIDs = append(effectIDs, e.EffectID)
+				}
+				So(effectIDs, ShouldContain, evego.EffectMedPower)
+			})
+		})
+
+		Convey("Attributes and effects can be fetched in bulk.", func() {
+			itemIDs := []int{3831, 3634} // Medium Shield Extender II, Civilian Gatling Autocannon
+			attrs, err := db.ItemsAttributes(itemIDs)
+			So(err, ShouldBeNil)
+			So(attrs, ShouldHaveLength, 2)
+			effects, err := db.ItemsEffects(itemIDs)
+			So(err, ShouldBeNil)
+			So(effects, ShouldHaveLength, 2)
+			var effectIDs []int
+			for _, e := range effects[3634] {
+				effectIDs = append(effectIDs, e.EffectID)
+			}
+			So(effectIDs, ShouldContain, evego.EffectHiPower)
+			So(effectIDs, ShouldContain, evego.EffectTurretFitted)
+		})
+
+		Convey("Bulk lookups take more IDs than fit in one query.", func() {
+			itemIDs := make([]int, 1200)
+			for i := range itemIDs {
+				itemIDs[i] = 900000000 + i // no such items
+			}
+			itemIDs[0], itemIDs[700], itemIDs[1100] = 34, 3831, 3634
+			items, err := db.ItemsForIDs(itemIDs)
+			So(err, ShouldBeNil)
+			var found []int
+			for _, item := range items {
+				found = append(found, item.ID)
+			}
+			So(found, ShouldHaveLength, 3)
+			So(found, ShouldContain, 3831)
+			So(found, ShouldContain, 3634)
+			attrs, err := db.ItemsAttributes(itemIDs)
+			So(err, ShouldBeNil)
+			So(attrs, ShouldContainKey, 3831)
+			So(attrs, ShouldContainKey, 3634)
+			effects, err := db.ItemsEffects(itemIDs)
+			So(err, ShouldBeNil)
+			So(effects, ShouldContainKey, 3831)
+			So(effects, ShouldContainKey, 3634)
+		})
+	})
+}
+
+func TestItemSearch(t *testing.T) {
+	Convey("Open a database connection", t, func() {
+		db := openTestDatabase()
+		defer db.Close()
+
+		Convey("An exact match ignoring case comes first.", func() {
+			matches, err := db.SearchItems("vexor", 0)
+			So(err, ShouldBeNil)
+			So(len(matches), ShouldBeGreaterThanOrEqualTo, 2)
+			So(matches[0].Item.Name, ShouldEqual, "Vexor")
+			So(matches[0].Kind, ShouldEqual, evego.ExactMatch)
+			So(matches[1].Item.Name, ShouldEqual, "Vexor Blueprint")
+			So(matches[1].Kind, ShouldEqual, evego.PrefixMatch)
+		})
+
+		Convey("Substring matches are found.", func() {
+			matches, err := db.SearchItems("shield extender", 0)
+			So(err, ShouldBeNil)
+			So(matches[0].Item.Name, ShouldEqual, "Medium Shield Extender II")
+			So(matches[0].Kind, ShouldEqual, evego.SubstringMatch)
+		})
+
+		Convey("Misspelled names are found.", func() {
+			matches, err := db.SearchItems("Tritanum", 1)
+			So(err, ShouldBeNil)
+			So(matches, ShouldHaveLength, 1)
+			So(matches[0].Item.Name, ShouldEqual, "Tritanium")
+			So(matches[0].Kind, ShouldEqual, evego.FuzzyMatch)
+			So(matches[0].Distance, ShouldEqual, 1)
+		})
+
+		Convey("The number of results is limited.", func() {
+			matches, err := db.SearchItems("a", 3)
+			So(err, ShouldBeNil)
+			So(matches, ShouldHaveLength, 3)
+		})
+
+		Convey("An error is returned when nothing matches.", func() {
+			_, err := db.SearchItems("W76 Thermonuclear Device", 0)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
+
+func itemNames(items []*evego.Item) []string {
+	var names []string
+	for _, i := range items {
+		names = append(names, i.Name)
+	}
+	return names
+}
+
+func TestMarketGroups(t *testing.T) {
+	Convey("Open a database connection", t, func() {
+		db := openTestDatabase()
+		defer db.Close()
+
+		tree, err := db.MarketTree()
+		So(err, ShouldBeNil)
+		So(tree, ShouldNotBeEmpty)
+		var minerals *evego.MarketGroupNode
+		for _, root := range tree {
+			if minerals = root.Find("Minerals"); minerals != nil {
+				break
+			}
+		}
+		So(minerals, ShouldNotBeNil)
+
+		Convey("The tree contains the minerals and their parents.", func() {
+			So(itemNames(minerals.Items), ShouldContain, "Tritanium")
+			So(minerals.Parent, ShouldNotBeNil)
+			item, err := db.ItemForName("Tritanium")
+			So(err, ShouldBeNil)
+			group, err := db.MarketGroupForItem(item)
+			So(err, ShouldBeNil)
+			So(group.ID, ShouldEqual, minerals.ID)
+			So(group.Parent.ID, ShouldEqual, minerals.Parent.ID)
+		})
+
+		Convey("The top-level groups are the tree's roots.", func() {
+			groups, err := db.MarketGroupChildren(0)
+			So(err, ShouldBeNil)
+			So(groups, ShouldHaveLength, len(tree))
+			for i := range groups {
+				So(groups[i].Name, ShouldEqual, tree[i].Name)
+				So(tree[i].Parent, ShouldBeNil)
+			}
+		})
+
+		Convey("A group's children include the group we came from.", func() {
+			groups, err := db.MarketGroupChildren(minerals.Parent.ID)
+			So(err, ShouldBeNil)
+			var names []string
+			for _, g := range groups {
+				names = append(names, g.Name)
+			}
+			So(names, ShouldContain, "Minerals")
+		})
+
+		Convey("A group's items are those in the tree.", func() {
+			items, err := db.ItemsInMarketGroup(minerals.ID, false)
+			So(err, ShouldBeNil)
+			So(itemNames(items), ShouldResemble, itemNames(minerals.Items))
+		})
+
+		Convey("A recursive listing includes the subgroups' items.", func() {
+			top := minerals.Parent
+			for top.Parent != nil {
+				top = top.Parent
+			}
+			var topNode *evego.MarketGroupNode
+			for _, root := range tree {
+				if root.ID == top.ID {
+					topNode = root
+				}
+			}
+			So(topNode, ShouldNotBeNil)
+			items, err := db.ItemsInMarketGroup(top.ID, true)
+			So(err, ShouldBeNil)
+			So(itemNames(items), ShouldContain, "Tritanium")
+			So(items, ShouldHaveLength, len(topNode.AllItems()))
+		})
+
+		Convey("Items in the tree have the same types as when looked up alone.", func() {
+			var all []*evego.Item
+			for _, root := range tree {
+				all = append(all, root.AllItems()...)
+				items, err := db.ItemsInMarketGroup(root.ID, true)
+				So(err, ShouldBeNil)
+				all = append(all, items...)
+			}
+			So(all, ShouldNotBeEmpty)
+			types := make(map[evego.ItemType]bool)
+			for _, item := range all {
+				expected, err := db.ItemForID(item.ID)
+				So(err, ShouldBeNil)
+				So(item.Type, ShouldEqual, expected.Type)
+				types[item.Type] = true
+			}
+			So(types, ShouldContainKey, evego.Ore)
+		})
+
+		Convey("An invalid group returns an error.", func() {
+			_, err := db.MarketGroupChildren(-1)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.ItemsInMarketGroup(-1, true)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package dbaccess
+
+import "github.com/backerman/evego"
+
+// The inventory groups of the objects in a solar system that we return.
+const (
+	groupPlanet       = 7
+	groupMoon         = 8
+	groupAsteroidBelt = 9
+	groupStargate     = 10
+)
+
+// snapCelestial is an object in a solar system, along with the stargate at the
+// other end of the jump if it's a stargate.
+type snapCelestial struct {
+	ID                  int    `db:"itemID"`
+	Name                string `db:"itemName"`
+	GroupID             int    `db:"groupID"`
+	SystemID            int    `db:"solarSystemID"`
+	TypeID              int    `db:"typeID"`
+	TypeName            string `db:"typeName"`
+	OrbitID             int    `db:"orbitID"`
+	DestinationID       int    `db:"destinationID"`
+	DestinationSystemID int    `db:"destinationSystemID"`
+	evego.Position
+}
+
+func stargates(rows []snapCelestial) []evego.Stargate {
+	var result []evego.Stargate
+	for _, c := range rows {
+		result = append(result, evego.Stargate{
+			Name:                c.Name,
+			ID:                  c.ID,
+			SystemID:            c.SystemID,
+			DestinationID:       c.DestinationID,
+			DestinationSystemID: c.DestinationSystemID,
+			Position:            c.Position,
+		})
+	}
+	return result
+}
+
+func planets(rows []snapCelestial) []evego.Planet {
+	var result []evego.Planet
+	for _, c := range rows {
+		result = append(result, evego.Planet{
+			Name:     c.Name,
+			ID:       c.ID,
+			SystemID: c.SystemID,
+			TypeID:   c.TypeID,
+			Type:     c.TypeName,
+			Position: c.Position,
+		})
+	}
+	return result
+}
+
+func moons(rows []snapCelestial) []evego.Moon {
+	var result []evego.Moon
+	for _, c := range rows {
+		result = append(result, evego.Moon{
+			Name:     c.Name,
+			ID:       c.ID,
+			SystemID: c.SystemID,
+			PlanetID: c.OrbitID,
+			Position: c.Position,
+		})
+	}
+	return result
+}
+
+func asteroidBelts(rows []snapCelestial) []evego.AsteroidBelt {
+	var result []evego.AsteroidBelt
+	for _, c := range rows {
+		result = append(result, evego.AsteroidBelt{
+			Name:     c.Name,
+			ID:       c.ID,
+			SystemID: c.SystemID,
+			PlanetID: c.OrbitID,
+			Position: c.Position,
+		})
+	}
+	return result
+}
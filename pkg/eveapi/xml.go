@@ -20,6 +20,7 @@ limitations under the License.
 package eveapi
 
 import (
+	"context"
 	"encoding/xml"
 	"io/ioutil"
 	"log"
@@ -42,6 +43,8 @@ const (
 	characterBlueprints = "/char/Blueprints.xml.aspx"
 	characterSheet      = "/char/CharacterSheet.xml.aspx"
 	characterStandings  = "/char/Standings.xml.aspx"
+	characterID         = "/eve/CharacterID.xml.aspx"
+	characterAffil      = "/eve/CharacterAffiliation.xml.aspx"
 	conqerableStations  = "/eve/ConquerableStationList.xml.aspx"
 )
 
@@ -89,26 +92,39 @@ type expiryInfo struct {
 
 // get executes a call to the EVE API given the endpoint path and an optional
 // url.Values containing the parameters to be passed.
-func (x *xmlAPI) get(endpoint string, params ...url.Values) ([]byte, error) {
+func (x *xmlAPI) get(ctx context.Context, endpoint string, params ...url.Values) ([]byte, error) {
+	urlStr := x.endpointURL(endpoint, params...)
+	// Check cache.
+	cachedBody, found := x.cache.GetContext(ctx, urlStr)
+	if found {
+		return cachedBody, nil
+	}
+
+	body, err := x.fetch(ctx, urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Put our repsonse in the cache.
+	x.cache.PutContext(ctx, urlStr, body, responseExpiry(body))
+	return body, nil
+}
+
+// endpointURL returns the URL to call an endpoint of the EVE API with an
+// optional url.Values containing the parameters to be passed.
+func (x *xmlAPI) endpointURL(endpoint string, params ...url.Values) string {
 	// Make a copy of our base URL and modify as appropriate for this call.
 	callURL := *x.url
 	callURL.Path = endpoint
-	var (
-		req *http.Request
-		err error
-	)
 	if params != nil {
 		callURL.RawQuery = params[0].Encode()
 	}
+	return callURL.String()
+}
 
-	urlStr := callURL.String()
-	// Check cache.
-	cachedBody, found := x.cache.Get(urlStr)
-	if found {
-		return cachedBody, nil
-	}
-
-	req, err = http.NewRequest("GET", urlStr, nil)
+// fetch retrieves a URL from the EVE API without checking the cache.
+func (x *xmlAPI) fetch(ctx context.Context, urlStr string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -118,14 +134,15 @@ func (x *xmlAPI) get(endpoint string, params ...url.Values) ([]byte, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	return ioutil.ReadAll(resp.Body)
+}
 
-	// Put our repsonse in the cache.
+// responseExpiry returns the time at which an API response should be
+// removed from the cache.
+func responseExpiry(body []byte) time.Time {
 	expiry := expiryInfo{}
 	xml.Unmarshal(body, &expiry)
-	expiresAt := expirationTime(expiry.CurrentTime, expiry.CachedUntil)
-	x.cache.Put(urlStr, body, expiresAt)
-	return body, err
+	return expirationTime(expiry.CurrentTime, expiry.CachedUntil)
 }
 
 func (x *xmlAPI) Close() error {
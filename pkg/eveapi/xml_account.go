@@ -18,6 +18,7 @@ limitations under the License.
 package eveapi
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"net/url"
@@ -32,10 +33,14 @@ type charsResponse struct {
 }
 
 func (x *xmlAPI) AccountCharacters(key *evego.XMLKey) ([]evego.Character, error) {
+	return x.AccountCharactersContext(context.Background(), key)
+}
+
+func (x *xmlAPI) AccountCharactersContext(ctx context.Context, key *evego.XMLKey) ([]evego.Character, error) {
 	params := url.Values{}
 	params.Set("keyID", fmt.Sprintf("%d", key.KeyID))
 	params.Set("vcode", key.VerificationCode)
-	xmlBytes, err := x.get(accountCharacters, params)
+	xmlBytes, err := x.get(ctx, accountCharacters, params)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package eveapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+// The most names or IDs that the API will accept in one call.
+const xmlBatchSize = 250
+
+// apiError is an error returned by the EVE API in place of a result.
+type apiError struct {
+	Code    int    `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+type characterIDResponse struct {
+	Error      *apiError `xml:"error"`
+	Characters []struct {
+		Name string `xml:"name,attr"`
+		ID   int    `xml:"characterID,attr"`
+	} `xml:"result>rowset>row"`
+}
+
+type affiliationResponse struct {
+	Error      *apiError `xml:"error"`
+	Characters []struct {
+		Name          string `xml:"characterName,attr"`
+		ID            int    `xml:"characterID,attr"`
+		Corporation   string `xml:"corporationName,attr"`
+		CorporationID int    `xml:"corporationID,attr"`
+		Alliance      string `xml:"allianceName,attr"`
+		AllianceID    int    `xml:"allianceID,attr"`
+	} `xml:"result>rowset>row"`
+}
+
+func characterIDKey(name string) string {
+	return "eveapi:characterid:" + strings.ToLower(name)
+}
+
+func affiliationKey(id int) string {
+	return fmt.Sprintf("eveapi:affiliation:%d", id)
+}
+
+// batches splits a list into slices of no more than xmlBatchSize items.
+func batches(items []string) [][]string {
+	var result [][]string
+	for len(items) > xmlBatchSize {
+		result = append(result, items[:xmlBatchSize])
+		items = items[xmlBatchSize:]
+	}
+	if len(items) > 0 {
+		result = append(result, items)
+	}
+	return result
+}
+
+// callBatch calls an endpoint that takes a comma-separated list of names or
+// IDs and unmarshals the response. The response is not cached; callers cache
+// each result separately, since the same name will rarely be looked up in
+// the same batch twice.
+func (x *xmlAPI) callBatch(ctx context.Context, endpoint, param string, batch []string, response interface{}) ([]byte, error) {
+	params := url.Values{}
+	params.Set(param, strings.Join(batch, ","))
+	body, err := x.fetch(ctx, x.endpointURL(endpoint, params))
+	if err != nil {
+		return nil, err
+	}
+	if err := xml.Unmarshal(body, response); err != nil {
+		return nil, fmt.Errorf("Unable to parse response from %s: %v", endpoint, err)
+	}
+	return body, nil
+}
+
+// characterIDs returns the character IDs for the provided names, keyed by
+// the lowercased name. Names that aren't characters have an ID of 0.
+func (x *xmlAPI) characterIDs(ctx context.Context, names []string) (map[string]int, error) {
+	ids := make(map[string]int)
+	var toFetch []string
+	for _, name := range names {
+		if cached, found := x.cache.GetContext(ctx, characterIDKey(name)); found {
+			if id, err := strconv.Atoi(string(cached)); err == nil {
+				ids[strings.ToLower(name)] = id
+				continue
+			}
+		}
+		toFetch = append(toFetch, name)
+	}
+	for _, batch := range batches(toFetch) {
+		var response characterIDResponse
+		body, err := x.callBatch(ctx, characterID, "names", batch, &response)
+		if err != nil {
+			return nil, err
+		}
+		if response.Error != nil {
+			return nil, fmt.Errorf("Unable to look up character IDs: %v", response.Error.Message)
+		}
+		expiresAt := responseExpiry(body)
+		for _, c := range response.Characters {
+			ids[strings.ToLower(c.Name)] = c.ID
+			x.cache.PutContext(ctx, characterIDKey(c.Name), []byte(strconv.Itoa(c.ID)), expiresAt)
+		}
+	}
+	return ids, nil
+}
+
+// affiliations returns the characters with the provided IDs, keyed by ID.
+func (x *xmlAPI) affiliations(ctx context.Context, ids []int) (map[int]evego.Character, error) {
+	chars := make(map[int]evego.Character)
+	var toFetch []string
+	for _, id := range ids {
+		if cached, found := x.cache.GetContext(ctx, affiliationKey(id)); found {
+			var char evego.Character
+			if err := gob.NewDecoder(bytes.NewBuffer(cached)).Decode(&char); err == nil {
+				chars[id] = char
+				continue
+			}
+		}
+		toFetch = append(toFetch, strconv.Itoa(id))
+	}
+	for _, batch := range batches(toFetch) {
+		var response affiliationResponse
+		body, err := x.callBatch(ctx, characterAffil, "ids", batch, &response)
+		if err != nil {
+			return nil, err
+		}
+		if response.Error != nil {
+			return nil, fmt.Errorf("Unable to look up character affiliations: %v", response.Error.Message)
+		}
+		expiresAt := responseExpiry(body)
+		for _, c := range response.Characters {
+			char := evego.Character{
+				Name:          c.Name,
+				ID:            c.ID,
+				Corporation:   c.Corporation,
+				CorporationID: c.CorporationID,
+				Alliance:      c.Alliance,
+				AllianceID:    c.AllianceID,
+			}
+			chars[c.ID] = char
+			var gobbed bytes.Buffer
+			if err := gob.NewEncoder(&gobbed).Encode(&char); err == nil {
+				x.cache.PutContext(ctx, affiliationKey(c.ID), gobbed.Bytes(), expiresAt)
+			}
+		}
+	}
+	return chars, nil
+}
+
+func (x *xmlAPI) CharactersForNames(names []string) ([]evego.Character, error) {
+	return x.CharactersForNamesContext(context.Background(), names)
+}
+
+func (x *xmlAPI) CharactersForNamesContext(ctx context.Context, names []string) ([]evego.Character, error) {
+	// Look up each name only once.
+	seen := make(map[string]bool)
+	var unique []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if name == "" || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		unique = append(unique, name)
+	}
+	ids, err := x.characterIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	var charIDs []int
+	for _, name := range unique {
+		if id := ids[strings.ToLower(name)]; id != 0 {
+			charIDs = append(charIDs, id)
+		}
+	}
+	chars, err := x.affiliations(ctx, charIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]evego.Character, 0, len(charIDs))
+	for _, id := range charIDs {
+		if char, ok := chars[id]; ok {
+			result = append(result, char)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package eveapi_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/eveapi"
+	"github.com/backerman/evego/pkg/test"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	affilHeader = `<?xml version='1.0' encoding='UTF-8'?>
+<eveapi version="2">
+  <currentTime>2015-02-17 03:27:50</currentTime>
+  <result>
+    <rowset name="characters" key="characterID" columns="%s">
+`
+	affilFooter = `    </rowset>
+  </result>
+  <cachedUntil>2015-02-17 04:27:50</cachedUntil>
+</eveapi>
+`
+)
+
+// Characters known to the test server. Pilots named "Pilot N" also exist,
+// with ID N and no alliance.
+var testPilots = map[string]evego.Character{
+	"arjun kansene": {
+		Name:          "Arjun Kansene",
+		ID:            94319654,
+		Corporation:   "Center for Advanced Studies",
+		CorporationID: 1000169,
+	},
+	"all reps on cain": {
+		Name:          "All reps on Cain",
+		ID:            123456,
+		Corporation:   "Yes, this is test data",
+		CorporationID: 78910,
+		Alliance:      "Some Alliance",
+		AllianceID:    494949,
+	},
+}
+
+func testPilot(name string) (evego.Character, bool) {
+	if char, ok := testPilots[strings.ToLower(name)]; ok {
+		return char, true
+	}
+	if strings.HasPrefix(name, "Pilot ") {
+		id, err := strconv.Atoi(strings.TrimPrefix(name, "Pilot "))
+		if err == nil {
+			return evego.Character{Name: name, ID: id, Corporation: "Test Corp", CorporationID: 42}, true
+		}
+	}
+	return evego.Character{}, false
+}
+
+func testPilotForID(id int) (evego.Character, bool) {
+	for _, char := range testPilots {
+		if char.ID == id {
+			return char, true
+		}
+	}
+	return testPilot(fmt.Sprintf("Pilot %d", id))
+}
+
+func TestCharactersForNames(t *testing.T) {
+	Convey("Set up API interface", t, func() {
+		calls := make(map[string]int)
+		ts := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls[r.URL.Path]++
+				switch r.URL.Path {
+				case "/eve/CharacterID.xml.aspx":
+					fmt.Fprintf(w, affilHeader, "name,characterID")
+					for _, name := range strings.Split(r.URL.Query().Get("names"), ",") {
+						char, _ := testPilot(name)
+						fmt.Fprintf(w, "      <row name=%q characterID=\"%d\" />\n", name, char.ID)
+					}
+				case "/eve/CharacterAffiliation.xml.aspx":
+					fmt.Fprintf(w, affilHeader, "characterID,characterName,corporationID,corporationName,allianceID,allianceName,factionID,factionName")
+					for _, idStr := range strings.Split(r.URL.Query().Get("ids"), ",") {
+						id, _ := strconv.Atoi(idStr)
+						char, ok := testPilotForID(id)
+						if !ok {
+							continue
+						}
+						fmt.Fprintf(w, "      <row characterID=\"%d\" characterName=%q corporationID=\"%d\" corporationName=%q allianceID=\"%d\" allianceName=%q factionID=\"0\" factionName=\"\" />\n",
+							char.ID, char.Name, char.CorporationID, char.Corporation, char.AllianceID, char.Alliance)
+					}
+				default:
+					fmt.Fprint(w, `<eveapi version="2"><error code="222">Key has expired.</error></eveapi>`)
+					return
+				}
+				fmt.Fprint(w, affilFooter)
+			}))
+		defer ts.Close()
+		cacheData := test.CacheData{}
+		x := eveapi.XML(ts.URL, nil, test.Cache(&cacheData))
+
+		Convey("Given a list of names", func() {
+			names := []string{"Arjun Kansene", "Nobody Here", "all reps on cain", "ARJUN KANSENE"}
+
+			Convey("The characters are returned in order, once each.", func() {
+				chars, err := x.CharactersForNames(names)
+				So(err, ShouldBeNil)
+				So(chars, ShouldResemble, []evego.Character{
+					testPilots["arjun kansene"],
+					testPilots["all reps on cain"],
+				})
+				So(calls["/eve/CharacterID.xml.aspx"], ShouldEqual, 1)
+				So(calls["/eve/CharacterAffiliation.xml.aspx"], ShouldEqual, 1)
+			})
+
+			Convey("Each name and affiliation is cached separately.", func() {
+				_, err := x.CharactersForNames(names)
+				So(err, ShouldBeNil)
+				So(cacheData.PutKeys, ShouldContainKey, "eveapi:characterid:arjun kansene")
+				So(cacheData.PutKeys, ShouldContainKey, "eveapi:characterid:nobody here")
+				So(cacheData.PutKeys, ShouldContainKey, "eveapi:affiliation:94319654")
+				So(cacheData.PutKeys, ShouldContainKey, "eveapi:affiliation:123456")
+				So(cacheData.NumPuts, ShouldEqual, 5)
+			})
+		})
+
+		Convey("Given a long list of names", func() {
+			var names []string
+			for i := 1; i <= 300; i++ {
+				names = append(names, fmt.Sprintf("Pilot %d", i))
+			}
+
+			Convey("The lookups are batched.", func() {
+				chars, err := x.CharactersForNames(names)
+				So(err, ShouldBeNil)
+				So(chars, ShouldHaveLength, 300)
+				So(chars[299].Name, ShouldEqual, "Pilot 300")
+				So(chars[299].Corporation, ShouldEqual, "Test Corp")
+				So(calls["/eve/CharacterID.xml.aspx"], ShouldEqual, 2)
+				So(calls["/eve/CharacterAffiliation.xml.aspx"], ShouldEqual, 2)
+			})
+		})
+
+		Convey("Given no names", func() {
+			Convey("No calls are made.", func() {
+				chars, err := x.CharactersForNames(nil)
+				So(err, ShouldBeNil)
+				So(chars, ShouldBeEmpty)
+				So(calls, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given an API that returns an error", t, func() {
+		ts := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<eveapi version="2"><error code="500">Internal error.</error></eveapi>`)
+			}))
+		defer ts.Close()
+		x := eveapi.XML(ts.URL, nil, test.Cache(&test.CacheData{}))
+
+		Convey("The error is returned.", func() {
+			_, err := x.CharactersForNames([]string{"Arjun Kansene"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Internal error.")
+		})
+	})
+}
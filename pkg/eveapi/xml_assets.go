@@ -18,6 +18,7 @@ limitations under the License.
 package eveapi
 
 import (
+	"context"
 	"encoding/xml"
 	"net/url"
 	"strconv"
@@ -45,7 +46,7 @@ type blueprintsResponse struct {
 	CachedUntil string         `xml:"cachedUntil"`
 }
 
-func (x *xmlAPI) processAssets(assets []evego.InventoryItem, station int) error {
+func (x *xmlAPI) processAssets(ctx context.Context, assets []evego.InventoryItem, station int) error {
 	// Make list of our distinct item IDs.
 	itemIDMap := make(map[int]bool)
 	for _, asset := range assets {
@@ -57,7 +58,7 @@ func (x *xmlAPI) processAssets(assets []evego.InventoryItem, station int) error
 		itemIDs[i] = key
 		i++
 	}
-	items, err := x.db.ItemsForIDs(itemIDs)
+	items, err := x.db.ItemsForIDsContext(ctx, itemIDs)
 	if err != nil {
 		return err
 	}
@@ -93,7 +94,7 @@ func (x *xmlAPI) processAssets(assets []evego.InventoryItem, station int) error
 			// just a nil) for consistency.
 			asset.Contents = make([]evego.InventoryItem, 0, 0)
 		} else if len(asset.Contents) > 0 {
-			err = x.processAssets(asset.Contents, asset.StationID)
+			err = x.processAssets(ctx, asset.Contents, asset.StationID)
 			if err != nil {
 				return err
 			}
@@ -104,18 +105,22 @@ func (x *xmlAPI) processAssets(assets []evego.InventoryItem, station int) error
 }
 
 func (x *xmlAPI) Assets(key *evego.XMLKey, characterID int) ([]evego.InventoryItem, error) {
+	return x.AssetsContext(context.Background(), key, characterID)
+}
+
+func (x *xmlAPI) AssetsContext(ctx context.Context, key *evego.XMLKey, characterID int) ([]evego.InventoryItem, error) {
 	params := url.Values{}
 	params.Set("keyID", strconv.Itoa(key.KeyID))
 	params.Set("characterID", strconv.Itoa(characterID))
 	params.Set("vcode", key.VerificationCode)
-	xmlBytes, err := x.get(characterAssets, params)
+	xmlBytes, err := x.get(ctx, characterAssets, params)
 	if err != nil {
 		return nil, err
 	}
 	var response assetsResponse
 	xml.Unmarshal(xmlBytes, &response)
 	assets := []evego.InventoryItem(response.Assets)
-	err = x.processAssets(assets, 0)
+	err = x.processAssets(ctx, assets, 0)
 	return assets, err
 }
 
@@ -148,11 +153,15 @@ func (x *xmlAPI) processBlueprints(blueprints []evego.BlueprintItem, assetsIn []
 }
 
 func (x *xmlAPI) Blueprints(key *evego.XMLKey, characterID int, assets []evego.InventoryItem) ([]evego.BlueprintItem, error) {
+	return x.BlueprintsContext(context.Background(), key, characterID, assets)
+}
+
+func (x *xmlAPI) BlueprintsContext(ctx context.Context, key *evego.XMLKey, characterID int, assets []evego.InventoryItem) ([]evego.BlueprintItem, error) {
 	params := url.Values{}
 	params.Set("keyID", strconv.Itoa(key.KeyID))
 	params.Set("characterID", strconv.Itoa(characterID))
 	params.Set("vcode", key.VerificationCode)
-	xmlBytes, err := x.get(characterBlueprints, params)
+	xmlBytes, err := x.get(ctx, characterBlueprints, params)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +65,8 @@ func TestBlueprints(t *testing.T) {
 			}))
 
 		defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		cacheData := test.CacheData{}
 		x := eveapi.XML(ts.URL, db, test.Cache(&cacheData))
 
@@ -55,7 +55,8 @@ func TestCharacterSheet(t *testing.T) {
 			}))
 
 		defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		cacheData := test.CacheData{}
 		x := eveapi.XML(ts.URL, db, test.Cache(&cacheData))
 
@@ -88,6 +89,14 @@ func TestCharacterSheet(t *testing.T) {
 						{Name: "Power Grid Management", Group: "Engineering", GroupID: 1216, TypeID: 3413, NumSkillpoints: 256000, Level: 5, Published: true},
 						{Name: "Hacking", Group: "Scanning", GroupID: 1217, TypeID: 21718, NumSkillpoints: 135765, Level: 4, Published: true},
 					},
+					Attributes: evego.Attributes{
+						Intelligence: 17,
+						Memory:       23,
+						Charisma:     17,
+						Perception:   25,
+						Willpower:    17,
+					},
+					Implants: []int{10208, 10212, 10216, 10221, 10225, 33329},
 				}
 				actual, err := x.CharacterSheet(key, characterID)
 				So(err, ShouldBeNil)
@@ -126,7 +135,8 @@ func TestAccountCharacters(t *testing.T) {
 			}))
 
 		defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		cacheData := test.CacheData{}
 		x := eveapi.XML(ts.URL, db, test.Cache(&cacheData))
 
@@ -189,7 +199,8 @@ func TestCharacterStandings(t *testing.T) {
 			}))
 
 		defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		cacheData := test.CacheData{}
 		x := eveapi.XML(ts.URL, db, test.Cache(&cacheData))
 
@@ -18,6 +18,7 @@ limitations under the License.
 package eveapi
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -70,6 +71,12 @@ func (cs *charSheet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 					skillRow := evego.Skill{}
 					d.DecodeElement(&skillRow, &tok)
 					cs.Skills = append(cs.Skills, skillRow)
+				case "implants":
+					implantRow := struct {
+						TypeID int `xml:"typeID,attr"`
+					}{}
+					d.DecodeElement(&implantRow, &tok)
+					cs.Implants = append(cs.Implants, implantRow.TypeID)
 				}
 			}
 			// if this is a row in a rowset, act based on parent rowset.
@@ -91,6 +98,16 @@ func (cs *charSheet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 					cs.Alliance = string(contents)
 				case "allianceID":
 					unmarshalInt(contents, &cs.AllianceID)
+				case "intelligence":
+					unmarshalInt(contents, &cs.Attributes.Intelligence)
+				case "memory":
+					unmarshalInt(contents, &cs.Attributes.Memory)
+				case "charisma":
+					unmarshalInt(contents, &cs.Attributes.Charisma)
+				case "perception":
+					unmarshalInt(contents, &cs.Attributes.Perception)
+				case "willpower":
+					unmarshalInt(contents, &cs.Attributes.Willpower)
 				}
 			}
 		}
@@ -104,11 +121,15 @@ type charSheetAPIResponse struct {
 }
 
 func (x *xmlAPI) CharacterSheet(key *evego.XMLKey, characterID int) (*evego.CharacterSheet, error) {
+	return x.CharacterSheetContext(context.Background(), key, characterID)
+}
+
+func (x *xmlAPI) CharacterSheetContext(ctx context.Context, key *evego.XMLKey, characterID int) (*evego.CharacterSheet, error) {
 	params := url.Values{}
 	params.Set("keyID", strconv.Itoa(key.KeyID))
 	params.Set("characterID", strconv.Itoa(characterID))
 	params.Set("vcode", key.VerificationCode)
-	xmlBytes, err := x.get(characterSheet, params)
+	xmlBytes, err := x.get(ctx, characterSheet, params)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +140,10 @@ func (x *xmlAPI) CharacterSheet(key *evego.XMLKey, characterID int) (*evego.Char
 	// Look up the name of each skill
 	for i := range sheet.Skills {
 		skill := &sheet.Skills[i]
-		skillItem, err := x.db.ItemForID(skill.TypeID)
+		skillItem, err := x.db.ItemForIDContext(ctx, skill.TypeID)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
 			skill.Name = fmt.Sprintf("Unknown skill (%d)", skill.TypeID)
 			skill.Group = fmt.Sprintf("Unknown")
@@ -20,6 +20,7 @@ limitations under the License.
 package eveapi
 
 import (
+	"context"
 	"database/sql"
 	"encoding/xml"
 	"fmt"
@@ -49,19 +50,18 @@ type outpost struct {
 }
 
 // Check the cache expiry time and update it if necessary.
-func (x *xmlAPI) checkOutpostCache() error {
+func (x *xmlAPI) checkOutpostCache(ctx context.Context) error {
 	if time.Now().After(cacheExpiry) {
 		// The cache has expired or has not yet been populated.
 		// FIXME Only one goroutine should update cache. Mutex? Channel?
 		newOutposts := make(map[int]*evego.Station)
-		xmlBytes, err := x.get(conqerableStations)
+		xmlBytes, err := x.get(ctx, conqerableStations)
 		if err != nil {
 			// FIXME some sort of throttling required
 			return err
 		}
 		var response outpostAPIResponse
 		xml.Unmarshal(xmlBytes, &response)
-		cacheExpiry = expirationTime(response.CurrentTime, response.CachedUntil)
 		for i := range response.Outposts {
 			o := response.Outposts[i]
 			stn := evego.Station{
@@ -71,7 +71,7 @@ func (x *xmlAPI) checkOutpostCache() error {
 				Corporation:   o.CorporationName,
 				CorporationID: o.CorporationID,
 			}
-			system, err := x.db.SolarSystemForID(stn.SystemID)
+			system, err := x.db.SolarSystemForIDContext(ctx, stn.SystemID)
 			if err != nil {
 				return err
 			}
@@ -79,14 +79,21 @@ func (x *xmlAPI) checkOutpostCache() error {
 			stn.RegionID = system.RegionID
 			newOutposts[o.ID] = &stn
 		}
+		// Only move the expiry once the new list is complete, so that an
+		// interrupted refresh is retried on the next call.
 		outposts = newOutposts
+		cacheExpiry = expirationTime(response.CurrentTime, response.CachedUntil)
 	}
 
 	return nil
 }
 
 func (x *xmlAPI) OutpostForID(id int) (*evego.Station, error) {
-	err := x.checkOutpostCache()
+	return x.OutpostForIDContext(context.Background(), id)
+}
+
+func (x *xmlAPI) OutpostForIDContext(ctx context.Context, id int) (*evego.Station, error) {
+	err := x.checkOutpostCache(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -114,9 +121,13 @@ func (s stationsList) Swap(i, j int) {
 }
 
 func (x *xmlAPI) OutpostsForName(name string) ([]evego.Station, error) {
+	return x.OutpostsForNameContext(context.Background(), name)
+}
+
+func (x *xmlAPI) OutpostsForNameContext(ctx context.Context, name string) ([]evego.Station, error) {
 	// This is a horribly inefficient implementation. Switch to SQLite
 	// in-memory DB rather than keeping everything as Golang structs?
-	err := x.checkOutpostCache()
+	err := x.checkOutpostCache(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +139,7 @@ func (x *xmlAPI) OutpostsForName(name string) ([]evego.Station, error) {
 	}
 	for id, stn := range outposts {
 		if nameRE.MatchString(stn.Name) {
-			matchStn, err := x.OutpostForID(id)
+			matchStn, err := x.OutpostForIDContext(ctx, id)
 			if err != nil {
 				return nil, err
 			}
@@ -144,12 +155,19 @@ func (x *xmlAPI) OutpostsForName(name string) ([]evego.Station, error) {
 }
 
 func (x *xmlAPI) DumpOutposts() []*evego.Station {
+	// Errors are ignored here for compatibility; the last list retrieved
+	// is returned.
+	outpostsSlice, _ := x.DumpOutpostsContext(context.Background())
+	return outpostsSlice
+}
+
+func (x *xmlAPI) DumpOutpostsContext(ctx context.Context) ([]*evego.Station, error) {
 	// Refresh if necessary.
-	x.checkOutpostCache()
+	err := x.checkOutpostCache(ctx)
 	// ... and dump 'em.
 	outpostsSlice := make([]*evego.Station, 0, len(outposts))
 	for _, outpost := range outposts {
 		outpostsSlice = append(outpostsSlice, outpost)
 	}
-	return outpostsSlice
+	return outpostsSlice, err
 }
@@ -18,6 +18,7 @@ limitations under the License.
 package eveapi
 
 import (
+	"context"
 	"encoding/xml"
 	"io"
 	"log"
@@ -91,11 +92,15 @@ func (sl *standingsList) UnmarshalXML(d *xml.Decoder, start xml.StartElement) er
 }
 
 func (x *xmlAPI) CharacterStandings(key *evego.XMLKey, characterID int) ([]evego.Standing, error) {
+	return x.CharacterStandingsContext(context.Background(), key, characterID)
+}
+
+func (x *xmlAPI) CharacterStandingsContext(ctx context.Context, key *evego.XMLKey, characterID int) ([]evego.Standing, error) {
 	params := url.Values{}
 	params.Set("keyID", strconv.Itoa(key.KeyID))
 	params.Set("characterID", strconv.Itoa(characterID))
 	params.Set("vcode", key.VerificationCode)
-	xmlBytes, err := x.get(characterStandings, params)
+	xmlBytes, err := x.get(ctx, characterStandings, params)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +67,8 @@ func TestOutpostID(t *testing.T) {
 			}))
 
 		defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		x := eveapi.XML(ts.URL, db, cache.NilCache())
 
 		Convey("Given a valid outpost ID", func() {
@@ -115,7 +116,8 @@ func TestOutpostName(t *testing.T) {
 			}))
 
 		defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		x := eveapi.XML(ts.URL, db, cache.NilCache())
 
 		Convey("Given a valid outpost name pattern", func() {
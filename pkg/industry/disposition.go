@@ -0,0 +1,112 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"github.com/backerman/evego"
+)
+
+// Disposition is what should be done with an item: sell it as-is, or
+// reprocess it and sell the resulting materials.
+type Disposition int
+
+// The Disposition values.
+const (
+	Sell Disposition = iota
+	Reprocess
+)
+
+func (d Disposition) String() string {
+	if d == Reprocess {
+		return "Reprocess"
+	}
+	return "Sell"
+}
+
+// DispositionLine compares the value of one inventory line when sold with the
+// value of its reprocessed outputs.
+type DispositionLine struct {
+	Line evego.InventoryLine
+	// SellValue is the value of the line if sold to the best buy order
+	// available at the station.
+	SellValue float64
+	// Outputs is the result of reprocessing the line.
+	Outputs []evego.InventoryLine
+	// ReprocessValue is the value of the reprocessed outputs if sold to the
+	// best buy orders available at the station.
+	ReprocessValue float64
+	// Best is the more valuable of the two options.
+	Best Disposition
+	// Margin is how much more ISK the better option returns.
+	Margin float64
+}
+
+// ReprocessOrSell determines, for each inventory line, whether it is worth
+// more sold as-is or reprocessed in the given station, by a character with
+// the given standing towards the station's owner and reprocessing skills.
+// Items are valued at the best buy order that can be filled from the station;
+// items with no such orders are valued at zero.
+func ReprocessOrSell(db evego.Database, market evego.Market, items []evego.InventoryLine, station *evego.Station, standing float64, skills ReproSkills) ([]DispositionLine, error) {
+	taxRate := StationTax(standing)
+	prices := make(map[int]float64)
+	priceOf := func(item *evego.Item) (float64, error) {
+		if price, found := prices[item.ID]; found {
+			return price, nil
+		}
+		price, err := StationBuyPrice(market, item, station)
+		if err == ErrNoOrders {
+			price, err = 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		prices[item.ID] = price
+		return price, nil
+	}
+
+	results := make([]DispositionLine, 0, len(items))
+	for _, line := range items {
+		result := DispositionLine{Line: line}
+		price, err := priceOf(line.Item)
+		if err != nil {
+			return nil, err
+		}
+		result.SellValue = price * float64(line.Quantity)
+		result.Outputs, err = ReprocessItem(db, line.Item, line.Quantity,
+			station.ReprocessingEfficiency, taxRate, skills)
+		if err != nil {
+			return nil, err
+		}
+		for _, out := range result.Outputs {
+			price, err := priceOf(out.Item)
+			if err != nil {
+				return nil, err
+			}
+			result.ReprocessValue += price * float64(out.Quantity)
+		}
+		if result.ReprocessValue > result.SellValue {
+			result.Best = Reprocess
+			result.Margin = result.ReprocessValue - result.SellValue
+		} else {
+			result.Best = Sell
+			result.Margin = result.SellValue - result.ReprocessValue
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry_test
+
+import (
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/industry"
+
+	. "github.com/backerman/evego/pkg/test"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReprocessOrSell(t *testing.T) {
+	Convey("Set up mock database and market", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+		prices := &MarketData{
+			BuyPrices: map[int]float64{
+				34: 5.0,  // Tritanium
+				35: 10.0, // Pyerite
+				36: 50.0, // Mexallon
+			},
+		}
+		market := Market(prices)
+		station := &evego.Station{Name: "Somewhere", ReprocessingEfficiency: 0.5}
+		skills := industry.ReproSkills{OreProcessing: map[string]int{}}
+
+		gun, err := db.ItemForName("150mm Prototype Gauss Gun")
+		So(err, ShouldBeNil)
+		items := []evego.InventoryLine{{Item: gun, Quantity: 1}}
+
+		Convey("When the module is worth more than its minerals", func() {
+			prices.BuyPrices[gun.ID] = 10000.0
+
+			Convey("It should be sold.", func() {
+				report, err := industry.ReprocessOrSell(db, market, items, station, 6.67, skills)
+				So(err, ShouldBeNil)
+				So(report, ShouldHaveLength, 1)
+				So(report[0].Outputs, ShouldHaveComposition, []Component{
+					{Name: "Tritanium", Quantity: 614},
+					{Name: "Pyerite", Quantity: 33},
+					{Name: "Mexallon", Quantity: 38},
+				})
+				So(report[0].SellValue, ShouldAlmostEqual, 10000.0)
+				So(report[0].ReprocessValue, ShouldAlmostEqual, 5300.0)
+				So(report[0].Best, ShouldEqual, industry.Sell)
+				So(report[0].Margin, ShouldAlmostEqual, 4700.0)
+			})
+		})
+
+		Convey("When nobody is buying the module", func() {
+			delete(prices.BuyPrices, gun.ID)
+
+			Convey("It should be reprocessed.", func() {
+				report, err := industry.ReprocessOrSell(db, market, items, station, 6.67, skills)
+				So(err, ShouldBeNil)
+				So(report, ShouldHaveLength, 1)
+				So(report[0].SellValue, ShouldAlmostEqual, 0.0)
+				So(report[0].Best, ShouldEqual, industry.Reprocess)
+				So(report[0].Margin, ShouldAlmostEqual, 5300.0)
+			})
+		})
+	})
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"fmt"
+
+	"github.com/backerman/evego"
+)
+
+// SlotValue is the value of the items lost from one location in a ship.
+type SlotValue struct {
+	Destroyed float64
+	Dropped   float64
+}
+
+// KillmailValue is the value of the losses on a killmail.
+type KillmailValue struct {
+	// Hull is the value of the ship itself, which is always destroyed.
+	Hull float64
+	// Destroyed and Dropped are the values of what was destroyed (including
+	// the hull) and of what dropped for the killer to loot.
+	Destroyed float64
+	Dropped   float64
+	// Total is the value of everything lost.
+	Total float64
+	// BySlot is the value of the items in each location in the ship. The
+	// contents of a container are counted with the container.
+	BySlot map[evego.InventoryFlag]SlotValue
+}
+
+// killmailPricer values the items on a killmail.
+type killmailPricer struct {
+	market  evego.Market
+	station *evego.Station
+	prices  map[int]float64
+}
+
+func (p *killmailPricer) price(item *evego.Item) (float64, error) {
+	if item == nil {
+		return 0, fmt.Errorf("Killmail types have not been looked up")
+	}
+	if price, found := p.prices[item.ID]; found {
+		return price, nil
+	}
+	price, err := StationSellPrice(p.market, item, p.station)
+	if err == ErrNoOrders {
+		price, err = 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	p.prices[item.ID] = price
+	return price, nil
+}
+
+// value adds the value of a stack of items, and their contents, to a slot.
+func (p *killmailPricer) value(item evego.KillmailItem, slot *SlotValue) error {
+	if !item.BlueprintCopy() {
+		price, err := p.price(item.Item)
+		if err != nil {
+			return err
+		}
+		slot.Destroyed += price * float64(item.QuantityDestroyed)
+		slot.Dropped += price * float64(item.QuantityDropped)
+	}
+	for _, contents := range item.Items {
+		if err := p.value(contents, slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValueKillmail values the losses on a killmail, whose types have been looked
+// up, at the lowest sell price in the given station: that is, at the cost of
+// replacing them. Blueprint copies, which can't be sold on the market, and
+// items with no sell orders in the station are valued at zero.
+func ValueKillmail(market evego.Market, km *evego.Killmail, station *evego.Station) (*KillmailValue, error) {
+	p := &killmailPricer{market: market, station: station, prices: make(map[int]float64)}
+	hull, err := p.price(km.Victim.Ship)
+	if err != nil {
+		return nil, err
+	}
+	result := &KillmailValue{
+		Hull:      hull,
+		Destroyed: hull,
+		BySlot:    make(map[evego.InventoryFlag]SlotValue),
+	}
+	for _, item := range km.Victim.Items {
+		slot := result.BySlot[item.Flag]
+		if err := p.value(item, &slot); err != nil {
+			return nil, err
+		}
+		result.BySlot[item.Flag] = slot
+	}
+	for _, slot := range result.BySlot {
+		result.Destroyed += slot.Destroyed
+		result.Dropped += slot.Dropped
+	}
+	result.Total = result.Destroyed + result.Dropped
+	return result, nil
+}
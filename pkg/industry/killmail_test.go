@@ -0,0 +1,91 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/industry"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/backerman/evego/pkg/test"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValueKillmail(t *testing.T) {
+	Convey("Given a killmail and a market", t, func() {
+		data, err := ioutil.ReadFile("../../testdata/test-killmail.json")
+		So(err, ShouldBeNil)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+		km, err := parsing.ParseKillmail(data, db)
+		So(err, ShouldBeNil)
+
+		prices := &MarketData{
+			SellPrices: map[int]float64{
+				626:   10000000.0, // Vexor
+				11269: 100000.0,   // Limited Kinetic Plating I
+				3831:  1000000.0,  // Medium Shield Extender II
+				7247:  500000.0,   // 150mm Prototype Gauss Gun
+				222:   100.0,      // Antimatter Charge M
+				31360: 2000000.0,  // Medium Hybrid Burst Aerator I
+				2456:  300000.0,   // Hobgoblin II
+				17366: 50000.0,    // Station Container
+				34:    5.0,        // Tritanium
+				1053:  99999999.0, // Vexor Blueprint
+			},
+		}
+		market := Market(prices)
+		station := &evego.Station{Name: "Somewhere"}
+
+		Convey("The losses are valued.", func() {
+			value, err := industry.ValueKillmail(market, km, station)
+			So(err, ShouldBeNil)
+			So(value.Hull, ShouldAlmostEqual, 10000000.0)
+			So(value.Destroyed, ShouldAlmostEqual, 14050100.0)
+			So(value.Dropped, ShouldAlmostEqual, 1205000.0)
+			So(value.Total, ShouldAlmostEqual, 15255100.0)
+		})
+
+		Convey("Each slot is valued separately, with containers' contents.", func() {
+			value, err := industry.ValueKillmail(market, km, station)
+			So(err, ShouldBeNil)
+			So(value.BySlot, ShouldResemble, map[evego.InventoryFlag]industry.SlotValue{
+				evego.InvLoSlot0:  {Destroyed: 100000},
+				evego.InvLoSlot1:  {Dropped: 100000},
+				evego.InvMedSlot0: {Destroyed: 1000000},
+				evego.InvHiSlot0:  {Destroyed: 100, Dropped: 500000},
+				evego.InvRigSlot0: {Destroyed: 2000000},
+				evego.InvDroneBay: {Destroyed: 900000, Dropped: 600000},
+				evego.InvCargo:    {Destroyed: 50000, Dropped: 5000},
+			})
+		})
+
+		Convey("Items without sell orders are worth nothing.", func() {
+			delete(prices.SellPrices, 626)
+			value, err := industry.ValueKillmail(market, km, station)
+			So(err, ShouldBeNil)
+			So(value.Hull, ShouldEqual, 0)
+			So(value.Total, ShouldAlmostEqual, 5255100.0)
+		})
+	})
+}
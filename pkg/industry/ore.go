@@ -0,0 +1,184 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/backerman/evego"
+)
+
+// OrePrice is an ore that may be bought to meet a mineral target, along with
+// the price of a single unit of it. Compressed ores may be included alongside
+// their uncompressed variants.
+type OrePrice struct {
+	Item  *evego.Item
+	Price float64
+}
+
+// OrePurchase is a set of ores to buy, what they cost, and the materials they
+// yield when reprocessed.
+type OrePurchase struct {
+	Ores  []evego.InventoryLine
+	Cost  float64
+	Yield []evego.InventoryLine
+}
+
+// OrePricesAtStation looks up the price of each ore at the given station.
+// Ores that are not for sale there are omitted from the result.
+func OrePricesAtStation(market evego.Market, ores []*evego.Item, station *evego.Station) ([]OrePrice, error) {
+	var prices []OrePrice
+	for _, ore := range ores {
+		price, err := StationSellPrice(market, ore, station)
+		if err == ErrNoOrders {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, OrePrice{Item: ore, Price: price})
+	}
+	return prices, nil
+}
+
+// shortfall returns the quantity of each target material that is not met by
+// the given yield, keyed by type ID.
+func shortfall(targets []evego.InventoryLine, yield []evego.InventoryLine) map[int]int {
+	have := make(map[int]int)
+	for _, line := range yield {
+		have[line.Item.ID] += line.Quantity
+	}
+	short := make(map[int]int)
+	for _, t := range targets {
+		if missing := t.Quantity - have[t.Item.ID]; missing > 0 {
+			short[t.Item.ID] = missing
+		}
+	}
+	return short
+}
+
+// OptimizeOrePurchase finds the cheapest combination of the candidate ores
+// that, once reprocessed with the given station yield, tax rate, and skills,
+// yields at least the target quantity of each material.
+//
+// Ores are bought in whole reprocessing batches. The purchase is found by
+// solving the linear relaxation of the problem and rounding up; if the
+// per-batch rounding done when reprocessing leaves a target unmet, batches of
+// the most cost-effective ore for that material are added until it is.
+func OptimizeOrePurchase(db evego.Database, targets []evego.InventoryLine, ores []OrePrice, stationYield float64, taxRate float64, skills ReproSkills) (*OrePurchase, error) {
+	targetRow := make(map[int]int, len(targets))
+	b := make([]float64, len(targets))
+	for i, t := range targets {
+		targetRow[t.Item.ID] = i
+		b[i] = float64(t.Quantity)
+	}
+	a := make([][]float64, len(targets))
+	for i := range a {
+		a[i] = make([]float64, len(ores))
+	}
+	cost := make([]float64, len(ores))
+	for j, ore := range ores {
+		if ore.Item.BatchSize < 1 {
+			return nil, fmt.Errorf("Invalid batch size for %v", ore.Item)
+		}
+		mats, err := db.ItemComposition(ore.Item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to get composition of %v: %w", ore.Item, err)
+		}
+		yield := reprocessYield(ore.Item, stationYield, skills) * (1.0 - taxRate)
+		for _, mat := range mats {
+			if i, ok := targetRow[mat.Item.ID]; ok {
+				a[i][j] = float64(mat.Quantity) * yield
+			}
+		}
+		cost[j] = ore.Price * float64(ore.Item.BatchSize)
+	}
+
+	batches, err := solveLP(cost, a, b)
+	if err != nil {
+		return nil, err
+	}
+	numBatches := make([]int, len(ores))
+	for j, x := range batches {
+		numBatches[j] = int(math.Ceil(x - lpEpsilon))
+	}
+
+	purchase := func() []evego.InventoryLine {
+		var lines []evego.InventoryLine
+		for j, n := range numBatches {
+			if n > 0 {
+				lines = append(lines, evego.InventoryLine{
+					Quantity: n * ores[j].Item.BatchSize,
+					Item:     ores[j].Item,
+				})
+			}
+		}
+		return lines
+	}
+
+	// Rounding in reprocessing can leave us a few units short; top up as
+	// required.
+	var yield []evego.InventoryLine
+	for {
+		yield, err = ReprocessItems(db, purchase(), stationYield, taxRate, skills)
+		if err != nil {
+			return nil, err
+		}
+		short := shortfall(targets, yield)
+		if len(short) == 0 {
+			break
+		}
+		// Pick the ore that provides the most of a missing material per ISK.
+		best, bestValue := -1, 0.0
+		for i, t := range targets {
+			if short[t.Item.ID] == 0 {
+				continue
+			}
+			for j := range ores {
+				if a[i][j] <= 0 {
+					continue
+				}
+				value := a[i][j] / math.Max(cost[j], lpEpsilon)
+				if value > bestValue {
+					best, bestValue = j, value
+				}
+			}
+		}
+		if best == -1 {
+			return nil, ErrInfeasible
+		}
+		numBatches[best]++
+	}
+
+	result := &OrePurchase{Ores: purchase(), Yield: yield}
+	for j, n := range numBatches {
+		result.Cost += float64(n) * cost[j]
+	}
+	sort.Sort(inventoryByName(result.Ores))
+	sort.Sort(inventoryByName(result.Yield))
+	return result, nil
+}
+
+// inventoryByName sorts inventory lines by item name.
+type inventoryByName []evego.InventoryLine
+
+func (l inventoryByName) Len() int           { return len(l) }
+func (l inventoryByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l inventoryByName) Less(i, j int) bool { return l[i].Item.Name < l[j].Item.Name }
@@ -0,0 +1,100 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry_test
+
+import (
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/industry"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOrePurchase(t *testing.T) {
+	Convey("Set up mock database", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		item := func(name string) *evego.Item {
+			i, err := db.ItemForName(name)
+			So(err, ShouldBeNil)
+			return i
+		}
+		tritanium, pyerite, mexallon := item("Tritanium"), item("Pyerite"), item("Mexallon")
+		ores := []industry.OrePrice{
+			{Item: item("Scordite"), Price: 15.0},
+			{Item: item("Condensed Scordite"), Price: 16.0},
+			{Item: item("Luminous Kernite"), Price: 50.0},
+		}
+		skills := industry.ReproSkills{
+			Reprocessing:           5,
+			ReprocessingEfficiency: 3,
+			OreProcessing: map[string]int{
+				"Scordite": 4,
+				"Kernite":  3,
+			},
+		}
+
+		Convey("Given a mineral target", func() {
+			targets := []evego.InventoryLine{
+				{Item: tritanium, Quantity: 100000},
+				{Item: pyerite, Quantity: 40000},
+				{Item: mexallon, Quantity: 5000},
+			}
+
+			Convey("The purchase meets the target.", func() {
+				purchase, err := industry.OptimizeOrePurchase(db, targets, ores, 0.5, 0.05, skills)
+				So(err, ShouldBeNil)
+				yielded := make(map[int]int)
+				for _, line := range purchase.Yield {
+					yielded[line.Item.ID] += line.Quantity
+				}
+				for _, t := range targets {
+					So(yielded[t.Item.ID], ShouldBeGreaterThanOrEqualTo, t.Quantity)
+				}
+
+				Convey("The cost matches the ores purchased.", func() {
+					prices := make(map[int]float64)
+					for _, o := range ores {
+						prices[o.Item.ID] = o.Price
+					}
+					cost := 0.0
+					for _, line := range purchase.Ores {
+						So(line.Quantity%line.Item.BatchSize, ShouldEqual, 0)
+						cost += float64(line.Quantity) * prices[line.Item.ID]
+					}
+					So(purchase.Cost, ShouldAlmostEqual, cost, 0.01)
+				})
+			})
+		})
+
+		Convey("Given a target that no ore can meet", func() {
+			targets := []evego.InventoryLine{
+				{Item: item("Megacyte"), Quantity: 1},
+			}
+
+			Convey("An error is returned.", func() {
+				_, err := industry.OptimizeOrePurchase(db, targets, ores, 0.5, 0.05, skills)
+				So(err, ShouldEqual, industry.ErrInfeasible)
+			})
+		})
+	})
+}
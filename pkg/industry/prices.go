@@ -0,0 +1,68 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"errors"
+	"math"
+
+	"github.com/backerman/evego"
+)
+
+// ErrNoOrders is returned when there are no market orders from which to
+// determine an item's price.
+var ErrNoOrders = errors.New("No applicable market orders were found")
+
+// StationSellPrice returns the lowest price at which an item is offered for
+// sale in the given station; this is the price to buy it immediately.
+func StationSellPrice(market evego.Market, item *evego.Item, station *evego.Station) (float64, error) {
+	orders, err := market.OrdersInStation(item, station)
+	if err != nil {
+		return 0, err
+	}
+	price := math.Inf(1)
+	for _, o := range *orders {
+		if o.Type == evego.Sell && o.Price < price {
+			price = o.Price
+		}
+	}
+	if math.IsInf(price, 1) {
+		return 0, ErrNoOrders
+	}
+	return price, nil
+}
+
+// StationBuyPrice returns the highest price of the buy orders that can be
+// filled from the given station; this is the price to sell an item
+// immediately.
+func StationBuyPrice(market evego.Market, item *evego.Item, station *evego.Station) (float64, error) {
+	orders, err := market.BuyInStation(item, station)
+	if err != nil {
+		return 0, err
+	}
+	price := math.Inf(-1)
+	for _, o := range *orders {
+		if o.Type == evego.Buy && o.Price > price {
+			price = o.Price
+		}
+	}
+	if math.IsInf(price, -1) {
+		return 0, ErrNoOrders
+	}
+	return price, nil
+}
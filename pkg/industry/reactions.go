@@ -0,0 +1,115 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/backerman/evego"
+)
+
+// Refinery is a structure in which reactions are run.
+type Refinery struct {
+	Structure StructureType
+	// Rig is the tier of the structure's reactor efficiency rig, if any.
+	Rig      RigTier
+	Security SecurityBand
+}
+
+// ReactionJob is the materials consumed and produced, and the time taken, by
+// a number of runs of a reaction.
+type ReactionJob struct {
+	Reaction *evego.Reaction
+	Runs     int
+	Inputs   []evego.InventoryLine
+	Outputs  []evego.InventoryLine
+	Time     time.Duration
+}
+
+// ErrReactionsInHighSec is returned when a reaction is planned in a high-sec
+// refinery; reactions can only be run in low-sec, null-sec, or wormholes.
+var ErrReactionsInHighSec = errors.New("Reactions cannot be run in high-sec space")
+
+// reactionRigBonuses returns the material and time reductions granted by a
+// reactor efficiency rig.
+func reactionRigBonuses(rig RigTier) (material, time float64) {
+	switch rig {
+	case T1Rig:
+		return 0.02, 0.20
+	case T2Rig:
+		return 0.024, 0.24
+	}
+	return 0, 0
+}
+
+// reactionSecurityMultiplier returns the factor by which reactor rig bonuses
+// are multiplied in a system of the given security band.
+func reactionSecurityMultiplier(band SecurityBand) float64 {
+	if band == NullSec {
+		return 1.1
+	}
+	return 1.0
+}
+
+// reactionTimeBonus returns the structure's role bonus to reaction time.
+func reactionTimeBonus(structure StructureType) float64 {
+	if structure == Tatara {
+		return 0.25
+	}
+	return 0.0
+}
+
+// PlanReaction returns the materials and time required for the given number
+// of runs of a reaction in a refinery, given the character's level in the
+// Reactions skill.
+func PlanReaction(reaction *evego.Reaction, runs int, facility Refinery, reactionsSkill int) (*ReactionJob, error) {
+	if facility.Security == HighSec {
+		return nil, ErrReactionsInHighSec
+	}
+	if runs < 1 {
+		return nil, errors.New("The number of runs must be positive")
+	}
+	matBonus, timeBonus := reactionRigBonuses(facility.Rig)
+	secMult := reactionSecurityMultiplier(facility.Security)
+	matModifier := 1.0 - matBonus*secMult
+
+	job := &ReactionJob{
+		Reaction: reaction,
+		Runs:     runs,
+	}
+	for _, in := range reaction.Inputs {
+		// Round to two places before taking the ceiling so that floating-point
+		// error doesn't cost us an extra unit.
+		needed := math.Ceil(math.Floor(float64(in.Quantity*runs)*matModifier*100+0.5) / 100)
+		quantity := int(math.Max(float64(runs), needed))
+		job.Inputs = append(job.Inputs, evego.InventoryLine{Quantity: quantity, Item: in.Item})
+	}
+	for _, out := range reaction.Outputs {
+		job.Outputs = append(job.Outputs,
+			evego.InventoryLine{Quantity: out.Quantity * runs, Item: out.Item})
+	}
+
+	runTime := reaction.Time.Seconds()
+	runTime *= 1.0 - 0.04*float64(reactionsSkill)
+	runTime *= 1.0 - reactionTimeBonus(facility.Structure)
+	runTime *= 1.0 - timeBonus*secMult
+	job.Time = time.Duration(round(runTime*float64(runs))) * time.Second
+	return job, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/industry"
+
+	. "github.com/backerman/evego/pkg/test"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReactions(t *testing.T) {
+	Convey("Given a simple reaction formula", t, func() {
+		hydrocarbons := &evego.Item{Name: "Hydrocarbons", ID: 16633, BatchSize: 1}
+		silicates := &evego.Item{Name: "Silicates", ID: 16636, BatchSize: 1}
+		fuel := &evego.Item{Name: "Helium Fuel Block", ID: 4247, BatchSize: 40}
+		polymers := &evego.Item{Name: "Carbon Polymers", ID: 16659, BatchSize: 1}
+		reaction := &evego.Reaction{
+			Formula: &evego.Item{Name: "Carbon Polymers Reaction Formula"},
+			Inputs: []evego.InventoryLine{
+				{Quantity: 100, Item: hydrocarbons},
+				{Quantity: 100, Item: silicates},
+				{Quantity: 5, Item: fuel},
+			},
+			Outputs: []evego.InventoryLine{
+				{Quantity: 200, Item: polymers},
+			},
+			Time: 3 * time.Hour,
+		}
+
+		Convey("In an unrigged low-sec Athanor with no skills", func() {
+			facility := industry.Refinery{
+				Structure: industry.Athanor,
+				Rig:       industry.NoRig,
+				Security:  industry.LowSec,
+			}
+
+			Convey("One run uses the base materials and time.", func() {
+				job, err := industry.PlanReaction(reaction, 1, facility, 0)
+				So(err, ShouldBeNil)
+				So(job.Inputs, ShouldHaveComposition, []Component{
+					{Name: "Hydrocarbons", Quantity: 100},
+					{Name: "Silicates", Quantity: 100},
+					{Name: "Helium Fuel Block", Quantity: 5},
+				})
+				So(job.Outputs, ShouldHaveComposition, []Component{
+					{Name: "Carbon Polymers", Quantity: 200},
+				})
+				So(job.Time, ShouldEqual, 3*time.Hour)
+			})
+		})
+
+		Convey("In a T2-rigged null-sec Tatara with Reactions V", func() {
+			facility := industry.Refinery{
+				Structure: industry.Tatara,
+				Rig:       industry.T2Rig,
+				Security:  industry.NullSec,
+			}
+
+			Convey("Materials and time are reduced.", func() {
+				job, err := industry.PlanReaction(reaction, 10, facility, 5)
+				So(err, ShouldBeNil)
+				So(job.Inputs, ShouldHaveComposition, []Component{
+					{Name: "Hydrocarbons", Quantity: 974},
+					{Name: "Silicates", Quantity: 974},
+					{Name: "Helium Fuel Block", Quantity: 49},
+				})
+				So(job.Outputs, ShouldHaveComposition, []Component{
+					{Name: "Carbon Polymers", Quantity: 2000},
+				})
+				So(job.Time, ShouldEqual, 47693*time.Second)
+			})
+		})
+
+		Convey("In a high-sec refinery", func() {
+			facility := industry.Refinery{Security: industry.HighSec}
+
+			Convey("An error is returned.", func() {
+				_, err := industry.PlanReaction(reaction, 1, facility, 5)
+				So(err, ShouldEqual, industry.ErrReactionsInHighSec)
+			})
+		})
+	})
+
+	Convey("Security bands are determined from true security.", t, func() {
+		So(industry.SecurityBandFor(0.45), ShouldEqual, industry.HighSec)
+		So(industry.SecurityBandFor(0.4499), ShouldEqual, industry.LowSec)
+		So(industry.SecurityBandFor(0.01), ShouldEqual, industry.LowSec)
+		So(industry.SecurityBandFor(0.0), ShouldEqual, industry.NullSec)
+		So(industry.SecurityBandFor(-1.0), ShouldEqual, industry.NullSec)
+	})
+}
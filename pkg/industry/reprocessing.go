@@ -52,9 +52,9 @@ func round(in float64) float64 {
 	return i + 1.0
 }
 
-// ReprocessItem returns the result of reprocessing a given item and the number
-// of input items that were reprocessed.
-func reprocessItem(item *evego.Item, itemMats []evego.InventoryLine, quantity int, stationYield float64, taxRate float64, skills ReproSkills) []evego.InventoryLine {
+// reprocessYield returns the fraction of an item's materials that a character
+// with the given skills recovers when reprocessing it.
+func reprocessYield(item *evego.Item, stationYield float64, skills ReproSkills) float64 {
 	yield := stationYield
 	switch item.Type {
 	case evego.Ice, evego.Ore:
@@ -66,6 +66,40 @@ func reprocessItem(item *evego.Item, itemMats []evego.InventoryLine, quantity in
 	default:
 		yield *= 1.0 + float64(skills.ScrapmetalProcessing)*0.02
 	}
+	return yield
+}
+
+// ReprocessingFacility is a station or structure in which items can be
+// reprocessed.
+type ReprocessingFacility interface {
+	// Yield returns the fraction of an item's materials that a character with
+	// the given skills recovers when reprocessing it here.
+	Yield(item *evego.Item, skills ReproSkills) float64
+	// Tax returns the fraction of the reprocessed materials taken by the
+	// facility's owner.
+	Tax() float64
+}
+
+// NPCStation is an NPC station's reprocessing plant. The station yield and tax
+// rate are expressed as a number in 0..1 (e.g. 0.05 for 5%).
+type NPCStation struct {
+	StationYield float64
+	TaxRate      float64
+}
+
+// Yield implements ReprocessingFacility.
+func (s NPCStation) Yield(item *evego.Item, skills ReproSkills) float64 {
+	return reprocessYield(item, s.StationYield, skills)
+}
+
+// Tax implements ReprocessingFacility.
+func (s NPCStation) Tax() float64 {
+	return s.TaxRate
+}
+
+// ReprocessItem returns the result of reprocessing a given item and the number
+// of input items that were reprocessed.
+func reprocessItem(item *evego.Item, itemMats []evego.InventoryLine, quantity int, yield float64, taxRate float64) []evego.InventoryLine {
 	reprocessed := []evego.InventoryLine{}
 
 	// Ensure that the quantity is okay.
@@ -98,14 +132,21 @@ func reprocessItem(item *evego.Item, itemMats []evego.InventoryLine, quantity in
 // output item. The input station yield and tax rate are expressed as a number
 // in 0..1 (e.g. 0.05 for 5%).
 func ReprocessItems(db evego.Database, items []evego.InventoryLine, stationYield float64, taxRate float64, skills ReproSkills) ([]evego.InventoryLine, error) {
+	return ReprocessItemsAt(db, items, NPCStation{StationYield: stationYield, TaxRate: taxRate}, skills)
+}
+
+// ReprocessItemsAt reprocesses a number of items in the given facility,
+// consolidating stacks of each output item.
+func ReprocessItemsAt(db evego.Database, items []evego.InventoryLine, facility ReprocessingFacility, skills ReproSkills) ([]evego.InventoryLine, error) {
 
 	reproed := []evego.InventoryLine{}
 	for _, item := range items {
 		itemMats, err := db.ItemComposition(item.Item.ID)
 		if err != nil {
-			return nil, fmt.Errorf("Unable to get composition of %v", item.Item)
+			return nil, fmt.Errorf("Unable to get composition of %v: %w", item.Item, err)
 		}
-		outItems := reprocessItem(item.Item, itemMats, item.Quantity, stationYield, taxRate, skills)
+		yield := facility.Yield(item.Item, skills)
+		outItems := reprocessItem(item.Item, itemMats, item.Quantity, yield, facility.Tax())
 		reproed = append(reproed, outItems...)
 	}
 
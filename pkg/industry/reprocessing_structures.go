@@ -0,0 +1,115 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+// Yield bonuses from the Zainou 'Beancounter' Reprocessing implants, for use
+// as ReprocessingStructure.Implant.
+const (
+	BeancounterRX801 = 0.01
+	BeancounterRX802 = 0.02
+	BeancounterRX804 = 0.04
+)
+
+// ReprocessingStructure is a player-owned structure's reprocessing facility.
+type ReprocessingStructure struct {
+	Structure StructureType
+	// Rig is the tier of the structure's ore or ice reprocessing rig, if any.
+	Rig      RigTier
+	Security SecurityBand
+	// Implant is the reprocessing yield bonus from the character's implants
+	// (e.g. BeancounterRX804).
+	Implant float64
+	// TaxRate is the tax set by the structure's owner, as a number in 0..1.
+	TaxRate float64
+}
+
+// structureBaseYield is the yield of a structure's reprocessing facility
+// before any bonuses.
+const structureBaseYield = 0.50
+
+// reprocessingRigBonus returns the amount by which a reprocessing rig raises a
+// structure's base yield.
+func reprocessingRigBonus(rig RigTier) float64 {
+	switch rig {
+	case T1Rig:
+		return 0.01
+	case T2Rig:
+		return 0.03
+	}
+	return 0.0
+}
+
+// reprocessingSecurityBonus returns the bonus applied to a rigged structure's
+// yield in a system of the given security band.
+func reprocessingSecurityBonus(band SecurityBand) float64 {
+	switch band {
+	case LowSec:
+		return 0.06
+	case NullSec:
+		return 0.12
+	}
+	return 0.0
+}
+
+// reprocessingStructureBonus returns the structure's role bonus to
+// reprocessing yield.
+func reprocessingStructureBonus(structure StructureType) float64 {
+	switch structure {
+	case Athanor:
+		return 0.02
+	case Tatara:
+		return 0.055
+	}
+	return 0.0
+}
+
+// Yield implements ReprocessingFacility. Ore and ice are reprocessed with the
+// facility's rig, security, and structure bonuses; rigs do not apply to
+// scrapmetal, which is reprocessed at the base yield.
+func (s ReprocessingStructure) Yield(item *evego.Item, skills ReproSkills) float64 {
+	yield := structureBaseYield
+	switch item.Type {
+	case evego.Ice, evego.Ore:
+		if s.Rig != NoRig {
+			// The security modifier is a property of the rig.
+			yield += reprocessingRigBonus(s.Rig)
+			yield *= 1.0 + reprocessingSecurityBonus(s.Security)
+		}
+		yield *= 1.0 + reprocessingStructureBonus(s.Structure)
+		splitName := strings.Split(item.Name, " ")
+		baseName := splitName[len(splitName)-1]
+		yield *= 1.0 + float64(skills.Reprocessing)*0.03
+		yield *= 1.0 + float64(skills.ReprocessingEfficiency)*0.02
+		yield *= 1.0 + float64(skills.OreProcessing[baseName])*0.02
+		yield *= 1.0 + s.Implant
+	default:
+		yield *= 1.0 + float64(skills.ScrapmetalProcessing)*0.02
+	}
+	return yield
+}
+
+// Tax implements ReprocessingFacility.
+func (s ReprocessingStructure) Tax() float64 {
+	return s.TaxRate
+}
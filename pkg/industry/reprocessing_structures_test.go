@@ -0,0 +1,131 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry_test
+
+import (
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/industry"
+
+	. "github.com/backerman/evego/pkg/test"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStructureYield(t *testing.T) {
+	Convey("Given a character with maxed reprocessing skills", t, func() {
+		skills := industry.ReproSkills{
+			Reprocessing:           5,
+			ReprocessingEfficiency: 5,
+			ScrapmetalProcessing:   5,
+			OreProcessing: map[string]int{
+				"Scordite": 5,
+			},
+		}
+		ore := &evego.Item{Name: "Scordite", Type: evego.Ore, BatchSize: 100}
+		module := &evego.Item{Name: "Armor Plates", Type: evego.Other, BatchSize: 1}
+
+		Convey("In a T2-rigged null-sec Tatara with a Beancounter RX-804", func() {
+			facility := industry.ReprocessingStructure{
+				Structure: industry.Tatara,
+				Rig:       industry.T2Rig,
+				Security:  industry.NullSec,
+				Implant:   industry.BeancounterRX804,
+			}
+
+			Convey("Ore yield is the maximum possible.", func() {
+				So(facility.Yield(ore, skills), ShouldAlmostEqual, 0.90628, 0.00001)
+			})
+
+			Convey("Scrapmetal gets no structure bonuses.", func() {
+				So(facility.Yield(module, skills), ShouldAlmostEqual, 0.55)
+			})
+		})
+
+		Convey("In an unrigged high-sec Athanor", func() {
+			facility := industry.ReprocessingStructure{
+				Structure: industry.Athanor,
+				Security:  industry.HighSec,
+			}
+
+			Convey("The security band has no effect without a rig.", func() {
+				nullsec := facility
+				nullsec.Security = industry.NullSec
+				So(facility.Yield(ore, skills), ShouldAlmostEqual, 0.709665, 0.000001)
+				So(nullsec.Yield(ore, skills), ShouldAlmostEqual, facility.Yield(ore, skills))
+			})
+		})
+
+		Convey("In an NPC station", func() {
+			facility := industry.NPCStation{StationYield: 0.5, TaxRate: 0.05}
+
+			Convey("The station yield is used.", func() {
+				So(facility.Yield(ore, skills), ShouldAlmostEqual, 0.5*1.15*1.1*1.1, 0.000001)
+				So(facility.Tax(), ShouldEqual, 0.05)
+			})
+		})
+	})
+}
+
+func TestReprocessingInStructure(t *testing.T) {
+	Convey("Set up mock database", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("Given some ore", func() {
+			scordite, err := db.ItemForName("Scordite")
+			So(err, ShouldBeNil)
+			items := []evego.InventoryLine{{Item: scordite, Quantity: 38841}}
+			skills := industry.ReproSkills{
+				Reprocessing:           5,
+				ReprocessingEfficiency: 3,
+				OreProcessing: map[string]int{
+					"Scordite": 4,
+				},
+			}
+
+			Convey("An NPC station facility matches the station path.", func() {
+				station := industry.NPCStation{StationYield: 0.5, TaxRate: 0.05}
+				viaFacility, err := industry.ReprocessItemsAt(db, items, station, skills)
+				So(err, ShouldBeNil)
+				So(viaFacility, ShouldHaveComposition, []Component{
+					{Name: "Tritanium", Quantity: 83951},
+					{Name: "Pyerite", Quantity: 41976},
+					{Name: "Scordite", Quantity: 41},
+				})
+			})
+
+			Convey("A rigged refinery yields more than the NPC station.", func() {
+				refinery := industry.ReprocessingStructure{
+					Structure: industry.Tatara,
+					Rig:       industry.T2Rig,
+					Security:  industry.LowSec,
+				}
+				reprocessed, err := industry.ReprocessItemsAt(db, items, refinery, skills)
+				So(err, ShouldBeNil)
+				for _, line := range reprocessed {
+					if line.Item.Name == "Tritanium" {
+						So(line.Quantity, ShouldBeGreaterThan, 83951)
+					}
+				}
+			})
+		})
+	})
+}
@@ -18,6 +18,7 @@ limitations under the License.
 package industry_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/backerman/evego"
@@ -46,7 +47,8 @@ func init() {
 
 func TestReprocessingModules(t *testing.T) {
 	Convey("Set up mock database", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		defer db.Close()
 
 		Convey("Given a module", func() {
@@ -178,7 +180,8 @@ func TestReprocessingModules(t *testing.T) {
 func TestReprocessingOre(t *testing.T) {
 
 	Convey("Set up mock database", t, func() {
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		defer db.Close()
 
 		Convey("Given some ore", func() {
@@ -266,3 +269,20 @@ func TestReprocessingOre(t *testing.T) {
 	})
 
 }
+
+func TestReprocessingErrors(t *testing.T) {
+	Convey("Given a closed database", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		item, err := db.ItemForName("Scordite")
+		So(err, ShouldBeNil)
+		db.Close()
+
+		Convey("Reprocessing returns the database's error.", func() {
+			items := []evego.InventoryLine{{Quantity: 100, Item: item}}
+			_, err := industry.ReprocessItems(db, items, 0.5, 0, industry.ReproSkills{})
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, dbaccess.ErrBackend), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,481 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/backerman/evego"
+)
+
+// Skills that affect industry job slots and durations.
+const (
+	skillIndustry                    = 3380
+	skillMassProduction              = 3387
+	skillAdvancedIndustry            = 3388
+	skillScience                     = 3402
+	skillResearch                    = 3403
+	skillLaboratoryOperation         = 3406
+	skillMetallurgy                  = 3409
+	skillAdvancedLaboratoryOperation = 24624
+	skillAdvancedMassProduction      = 24625
+	skillReactions                   = 45746
+	skillMassReactions               = 45748
+	skillAdvancedMassReactions       = 45749
+)
+
+// SlotType is the kind of industry slot that a job occupies.
+type SlotType int
+
+// The SlotType values.
+const (
+	ManufacturingSlot SlotType = iota
+	ScienceSlot
+	ReactionSlot
+)
+
+// SlotTypeFor returns the kind of slot used by jobs of the given activity.
+func SlotTypeFor(activity evego.ActivityType) SlotType {
+	switch activity {
+	case evego.Manufacturing:
+		return ManufacturingSlot
+	case evego.Reactions:
+		return ReactionSlot
+	}
+	return ScienceSlot
+}
+
+// Job is an industry job to be scheduled.
+type Job struct {
+	// ID uniquely identifies the job within a plan.
+	ID       string             `json:"id"`
+	Activity evego.ActivityType `json:"activity"`
+	// Product is a description of what the job produces.
+	Product string `json:"product"`
+	Runs    int    `json:"runs"`
+	// RunSeconds is the duration of a single run, in seconds, before any
+	// skill or facility modifiers.
+	RunSeconds int `json:"runSeconds"`
+	// DependsOn lists the IDs of jobs that must complete before this one can
+	// start (e.g. the jobs that build its components).
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// CharacterID, if nonzero, restricts the job to a single character (e.g.
+	// the owner of the blueprint).
+	CharacterID int `json:"characterID,omitempty"`
+}
+
+// Facility is a station or structure in which industry jobs can be run.
+type Facility struct {
+	Name string `json:"name"`
+	// TimeBonus maps each activity that the facility supports to the
+	// reduction in job time that it provides, as a number in 0..1.
+	TimeBonus map[evego.ActivityType]float64 `json:"timeBonus"`
+}
+
+// activityName is an activity that is represented by name in JSON.
+type activityName evego.ActivityType
+
+// MarshalText implements encoding.TextMarshaler.
+func (a activityName) MarshalText() ([]byte, error) {
+	return []byte(evego.ActivityType(a).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *activityName) UnmarshalText(text []byte) error {
+	for t := evego.None; t <= evego.Reactions; t++ {
+		if t.String() == string(text) {
+			*a = activityName(t)
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown activity type %q", text)
+}
+
+// MarshalJSON implements json.Marshaler, so that the job's activity is
+// represented by name.
+func (j Job) MarshalJSON() ([]byte, error) {
+	type plainJob Job
+	return json.Marshal(struct {
+		plainJob
+		Activity activityName `json:"activity"`
+	}{plainJob(j), activityName(j.Activity)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *Job) UnmarshalJSON(data []byte) error {
+	type plainJob Job
+	aux := struct {
+		*plainJob
+		Activity activityName `json:"activity"`
+	}{(*plainJob)(j), activityName(j.Activity)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	j.Activity = evego.ActivityType(aux.Activity)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, so that the facility's bonuses are
+// keyed by activity name.
+func (f Facility) MarshalJSON() ([]byte, error) {
+	bonuses := make(map[activityName]float64, len(f.TimeBonus))
+	for a, bonus := range f.TimeBonus {
+		bonuses[activityName(a)] = bonus
+	}
+	type plainFacility Facility
+	return json.Marshal(struct {
+		plainFacility
+		TimeBonus map[activityName]float64 `json:"timeBonus"`
+	}{plainFacility(f), bonuses})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Facility) UnmarshalJSON(data []byte) error {
+	type plainFacility Facility
+	aux := struct {
+		*plainFacility
+		TimeBonus map[activityName]float64 `json:"timeBonus"`
+	}{plainFacility: (*plainFacility)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.TimeBonus != nil {
+		f.TimeBonus = make(map[evego.ActivityType]float64, len(aux.TimeBonus))
+		for a, bonus := range aux.TimeBonus {
+			f.TimeBonus[evego.ActivityType(a)] = bonus
+		}
+	}
+	return nil
+}
+
+// ScheduledJob is a job that has been assigned to a character, facility, and
+// time.
+type ScheduledJob struct {
+	Job         *Job      `json:"job"`
+	Character   string    `json:"character"`
+	CharacterID int       `json:"characterID"`
+	Facility    string    `json:"facility"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// Schedule is a time-ordered plan of industry jobs.
+type Schedule struct {
+	Jobs []ScheduledJob `json:"jobs"`
+	// Finish is the time at which the last job completes.
+	Finish time.Time `json:"finish"`
+}
+
+// PlanRequest is everything required to produce a Schedule.
+type PlanRequest struct {
+	Start      time.Time              `json:"start"`
+	Jobs       []Job                  `json:"jobs"`
+	Characters []evego.CharacterSheet `json:"characters"`
+	// Facilities available to the characters. If none are given, jobs are
+	// assumed to run in a facility with no bonuses.
+	Facilities []Facility `json:"facilities,omitempty"`
+}
+
+// skillLevels returns a character's trained skill levels, keyed by type ID.
+func skillLevels(sheet *evego.CharacterSheet) map[int]int {
+	levels := make(map[int]int, len(sheet.Skills))
+	for _, sk := range sheet.Skills {
+		levels[sk.TypeID] = sk.Level
+	}
+	return levels
+}
+
+// Slots returns the number of slots of each type that a character's skills
+// provide.
+func Slots(sheet *evego.CharacterSheet) map[SlotType]int {
+	levels := skillLevels(sheet)
+	return map[SlotType]int{
+		ManufacturingSlot: 1 + levels[skillMassProduction] + levels[skillAdvancedMassProduction],
+		ScienceSlot:       1 + levels[skillLaboratoryOperation] + levels[skillAdvancedLaboratoryOperation],
+		ReactionSlot:      1 + levels[skillMassReactions] + levels[skillAdvancedMassReactions],
+	}
+}
+
+// skillTimeModifier returns the multiplier that a character's skills apply
+// to the duration of a job of the given activity.
+func skillTimeModifier(levels map[int]int, activity evego.ActivityType) float64 {
+	advanced := 1.0 - 0.03*float64(levels[skillAdvancedIndustry])
+	switch activity {
+	case evego.Manufacturing:
+		return (1.0 - 0.04*float64(levels[skillIndustry])) * advanced
+	case evego.ResearchingTE:
+		return (1.0 - 0.05*float64(levels[skillResearch])) * advanced
+	case evego.ResearchingME:
+		return (1.0 - 0.05*float64(levels[skillMetallurgy])) * advanced
+	case evego.Copying:
+		return (1.0 - 0.05*float64(levels[skillScience])) * advanced
+	case evego.Reactions:
+		return 1.0 - 0.04*float64(levels[skillReactions])
+	}
+	return advanced
+}
+
+// industrialist is a character's state while scheduling.
+type industrialist struct {
+	sheet  *evego.CharacterSheet
+	levels map[int]int
+	// slots holds the time at which each of the character's slots becomes
+	// free, by slot type.
+	slots map[SlotType][]time.Time
+}
+
+// jobDuration returns the time that the character would take to run the job
+// in the best available facility, and that facility's name. It returns false
+// if no facility supports the job's activity.
+func (c *industrialist) jobDuration(job *Job, facilities []Facility) (time.Duration, string, bool) {
+	bestBonus, bestName, found := 0.0, "", false
+	for _, f := range facilities {
+		bonus, ok := f.TimeBonus[job.Activity]
+		if ok && (!found || bonus > bestBonus) {
+			bestBonus, bestName, found = bonus, f.Name, true
+		}
+	}
+	if !found {
+		return 0, "", false
+	}
+	seconds := float64(job.RunSeconds*job.Runs) *
+		skillTimeModifier(c.levels, job.Activity) * (1.0 - bestBonus)
+	return time.Duration(math.Ceil(seconds)) * time.Second, bestName, true
+}
+
+// ErrDependencyCycle is returned when the jobs in a plan depend on each other
+// in a cycle.
+var ErrDependencyCycle = errors.New("The jobs' dependencies contain a cycle")
+
+// topoSort returns the plan's jobs in an order in which every job follows
+// the jobs it depends on.
+func topoSort(jobs []Job) ([]*Job, error) {
+	byID := make(map[string]*Job, len(jobs))
+	for i := range jobs {
+		if _, dup := byID[jobs[i].ID]; dup {
+			return nil, fmt.Errorf("Duplicate job ID %q", jobs[i].ID)
+		}
+		byID[jobs[i].ID] = &jobs[i]
+	}
+	indegree := make(map[string]int, len(jobs))
+	dependents := make(map[string][]string)
+	for _, j := range jobs {
+		for _, dep := range j.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				return nil, fmt.Errorf("Job %q depends on unknown job %q", j.ID, dep)
+			}
+			indegree[j.ID]++
+			dependents[dep] = append(dependents[dep], j.ID)
+		}
+	}
+	var queue []string
+	for _, j := range jobs {
+		if indegree[j.ID] == 0 {
+			queue = append(queue, j.ID)
+		}
+	}
+	sorted := make([]*Job, 0, len(jobs))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, byID[id])
+		for _, next := range dependents[id] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(sorted) != len(jobs) {
+		return nil, ErrDependencyCycle
+	}
+	return sorted, nil
+}
+
+// PlanJobs schedules the requested jobs across the characters' industry
+// slots, trying to minimise the time at which the last job completes.
+//
+// Jobs are scheduled using a critical-path list heuristic: whenever a job's
+// dependencies are satisfied, the ready job with the longest chain of work
+// remaining after it is placed on whichever character's slot will finish it
+// soonest. The result is not guaranteed to be optimal, but is good in
+// practice and fast enough for interactive use.
+func PlanJobs(req *PlanRequest) (*Schedule, error) {
+	if len(req.Characters) == 0 {
+		return nil, errors.New("At least one character is required")
+	}
+	facilities := req.Facilities
+	if len(facilities) == 0 {
+		facilities = []Facility{{Name: "Default", TimeBonus: map[evego.ActivityType]float64{
+			evego.Manufacturing: 0, evego.ResearchingTE: 0, evego.ResearchingME: 0,
+			evego.Copying: 0, evego.Invention: 0, evego.ReverseEngineering: 0,
+			evego.Reactions: 0,
+		}}}
+	}
+	order, err := topoSort(req.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	chars := make([]*industrialist, len(req.Characters))
+	for i := range req.Characters {
+		sheet := &req.Characters[i]
+		c := &industrialist{
+			sheet:  sheet,
+			levels: skillLevels(sheet),
+			slots:  make(map[SlotType][]time.Time),
+		}
+		for slotType, n := range Slots(sheet) {
+			c.slots[slotType] = make([]time.Time, n)
+			for k := range c.slots[slotType] {
+				c.slots[slotType][k] = req.Start
+			}
+		}
+		chars[i] = c
+	}
+	eligible := func(job *Job, c *industrialist) bool {
+		return job.CharacterID == 0 || job.CharacterID == c.sheet.ID
+	}
+
+	// Compute each job's bottom level: its shortest possible duration plus
+	// the longest chain of work that depends on it.
+	minDuration := make(map[string]time.Duration, len(order))
+	for _, job := range order {
+		found := false
+		for _, c := range chars {
+			if !eligible(job, c) {
+				continue
+			}
+			if d, _, ok := c.jobDuration(job, facilities); ok && (!found || d < minDuration[job.ID]) {
+				minDuration[job.ID], found = d, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("No character and facility can run job %q", job.ID)
+		}
+	}
+	dependents := make(map[string][]*Job)
+	for _, job := range order {
+		for _, dep := range job.DependsOn {
+			dependents[dep] = append(dependents[dep], job)
+		}
+	}
+	bottomLevel := make(map[string]time.Duration, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		job := order[i]
+		var longest time.Duration
+		for _, next := range dependents[job.ID] {
+			if bottomLevel[next.ID] > longest {
+				longest = bottomLevel[next.ID]
+			}
+		}
+		bottomLevel[job.ID] = minDuration[job.ID] + longest
+	}
+
+	finished := make(map[string]time.Time, len(order))
+	schedule := &Schedule{Finish: req.Start}
+	remaining := append([]*Job(nil), order...)
+	for len(remaining) > 0 {
+		// Find the ready job with the highest priority.
+		pick := -1
+		for i, job := range remaining {
+			ready := true
+			for _, dep := range job.DependsOn {
+				if _, done := finished[dep]; !done {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			if pick == -1 || bottomLevel[job.ID] > bottomLevel[remaining[pick].ID] ||
+				(bottomLevel[job.ID] == bottomLevel[remaining[pick].ID] && job.ID < remaining[pick].ID) {
+				pick = i
+			}
+		}
+		job := remaining[pick]
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+
+		readyAt := req.Start
+		for _, dep := range job.DependsOn {
+			if finished[dep].After(readyAt) {
+				readyAt = finished[dep]
+			}
+		}
+
+		// Place it on the slot that will finish it soonest.
+		var (
+			best               *industrialist
+			bestSlot           int
+			bestStart, bestEnd time.Time
+			bestFacility       string
+		)
+		slotType := SlotTypeFor(job.Activity)
+		for _, c := range chars {
+			if !eligible(job, c) {
+				continue
+			}
+			duration, facility, ok := c.jobDuration(job, facilities)
+			if !ok {
+				continue
+			}
+			for k, free := range c.slots[slotType] {
+				start := readyAt
+				if free.After(start) {
+					start = free
+				}
+				end := start.Add(duration)
+				if best == nil || end.Before(bestEnd) {
+					best, bestSlot, bestStart, bestEnd, bestFacility = c, k, start, end, facility
+				}
+			}
+		}
+		if best == nil {
+			return nil, fmt.Errorf("No character has a free slot for job %q", job.ID)
+		}
+		best.slots[slotType][bestSlot] = bestEnd
+		finished[job.ID] = bestEnd
+		if bestEnd.After(schedule.Finish) {
+			schedule.Finish = bestEnd
+		}
+		schedule.Jobs = append(schedule.Jobs, ScheduledJob{
+			Job:         job,
+			Character:   best.sheet.Name,
+			CharacterID: best.sheet.ID,
+			Facility:    bestFacility,
+			Start:       bestStart,
+			End:         bestEnd,
+		})
+	}
+
+	sort.Stable(scheduleByStart(schedule.Jobs))
+	return schedule, nil
+}
+
+// scheduleByStart sorts scheduled jobs by start time.
+type scheduleByStart []ScheduledJob
+
+func (s scheduleByStart) Len() int           { return len(s) }
+func (s scheduleByStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s scheduleByStart) Less(i, j int) bool { return s[i].Start.Before(s[j].Start) }
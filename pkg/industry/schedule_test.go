@@ -0,0 +1,157 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/industry"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProductionPlanner(t *testing.T) {
+	Convey("Given two industrialists", t, func() {
+		builder := evego.CharacterSheet{
+			Character: evego.Character{Name: "Builder", ID: 1},
+			Skills: []evego.Skill{
+				{Name: "Industry", TypeID: 3380, Level: 5},
+				{Name: "Mass Production", TypeID: 3387, Level: 5},
+			},
+		}
+		newbie := evego.CharacterSheet{
+			Character: evego.Character{Name: "Newbie", ID: 2},
+		}
+		start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		Convey("Their slots are determined by their skills.", func() {
+			So(industry.Slots(&builder)[industry.ManufacturingSlot], ShouldEqual, 6)
+			So(industry.Slots(&builder)[industry.ScienceSlot], ShouldEqual, 1)
+			So(industry.Slots(&newbie)[industry.ManufacturingSlot], ShouldEqual, 1)
+		})
+
+		Convey("With a small build tree", func() {
+			req := &industry.PlanRequest{
+				Start:      start,
+				Characters: []evego.CharacterSheet{builder, newbie},
+				Jobs: []industry.Job{
+					{ID: "final", Activity: evego.Manufacturing, Runs: 1, RunSeconds: 7200,
+						DependsOn: []string{"a", "b"}},
+					{ID: "a", Activity: evego.Manufacturing, Runs: 1, RunSeconds: 3600},
+					{ID: "b", Activity: evego.Manufacturing, Runs: 1, RunSeconds: 3600},
+				},
+			}
+
+			Convey("Components are built in parallel before the final product.", func() {
+				schedule, err := industry.PlanJobs(req)
+				So(err, ShouldBeNil)
+				So(schedule.Jobs, ShouldHaveLength, 3)
+				ends := make(map[string]time.Time)
+				for _, j := range schedule.Jobs {
+					ends[j.Job.ID] = j.End
+				}
+				So(ends["a"], ShouldResemble, start.Add(48*time.Minute))
+				So(ends["b"], ShouldResemble, start.Add(48*time.Minute))
+				So(schedule.Jobs[2].Job.ID, ShouldEqual, "final")
+				So(schedule.Jobs[2].Character, ShouldEqual, "Builder")
+				So(schedule.Finish, ShouldResemble, start.Add(144*time.Minute))
+			})
+
+			Convey("Restricted jobs go to their character.", func() {
+				req.Jobs[1].CharacterID = 2
+				schedule, err := industry.PlanJobs(req)
+				So(err, ShouldBeNil)
+				for _, j := range schedule.Jobs {
+					if j.Job.ID == "a" {
+						So(j.Character, ShouldEqual, "Newbie")
+						So(j.End, ShouldResemble, start.Add(time.Hour))
+					}
+				}
+				So(schedule.Finish, ShouldResemble, start.Add(156*time.Minute))
+			})
+
+			Convey("Facility bonuses are applied.", func() {
+				req.Facilities = []industry.Facility{
+					{Name: "Station", TimeBonus: map[evego.ActivityType]float64{evego.Manufacturing: 0}},
+					{Name: "Raitaru", TimeBonus: map[evego.ActivityType]float64{evego.Manufacturing: 0.15}},
+				}
+				schedule, err := industry.PlanJobs(req)
+				So(err, ShouldBeNil)
+				So(schedule.Jobs[0].Facility, ShouldEqual, "Raitaru")
+			})
+
+			Convey("The schedule can be exported as JSON.", func() {
+				schedule, err := industry.PlanJobs(req)
+				So(err, ShouldBeNil)
+				out, err := json.Marshal(schedule)
+				So(err, ShouldBeNil)
+				So(string(out), ShouldContainSubstring, `"activity":"Manufacturing"`)
+			})
+
+			Convey("Facility bonuses are keyed by activity name in JSON.", func() {
+				raitaru := industry.Facility{Name: "Raitaru",
+					TimeBonus: map[evego.ActivityType]float64{evego.Manufacturing: 0.15}}
+				out, err := json.Marshal(raitaru)
+				So(err, ShouldBeNil)
+				So(string(out), ShouldContainSubstring, `"Manufacturing":0.15`)
+				var fromJSON industry.Facility
+				So(json.Unmarshal(out, &fromJSON), ShouldBeNil)
+				So(fromJSON, ShouldResemble, raitaru)
+			})
+
+			Convey("Unknown activities in JSON are an error.", func() {
+				var job industry.Job
+				err := json.Unmarshal([]byte(`{"id": "x", "activity": "Mining"}`), &job)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("A request can be read from JSON.", func() {
+				in := `{"start": "2016-01-01T00:00:00Z",
+				        "jobs": [{"id": "x", "activity": "Reactions", "runs": 2, "runSeconds": 10800}],
+				        "characters": [{"name": "Builder", "id": 1}]}`
+				var fromJSON industry.PlanRequest
+				So(json.Unmarshal([]byte(in), &fromJSON), ShouldBeNil)
+				So(fromJSON.Jobs[0].Activity, ShouldEqual, evego.Reactions)
+				So(fromJSON.Jobs[0].ID, ShouldEqual, "x")
+				So(fromJSON.Jobs[0].Runs, ShouldEqual, 2)
+				schedule, err := industry.PlanJobs(&fromJSON)
+				So(err, ShouldBeNil)
+				So(schedule.Finish, ShouldResemble, start.Add(6*time.Hour))
+			})
+		})
+
+		Convey("With a dependency cycle", func() {
+			req := &industry.PlanRequest{
+				Start:      start,
+				Characters: []evego.CharacterSheet{builder},
+				Jobs: []industry.Job{
+					{ID: "a", Activity: evego.Manufacturing, Runs: 1, RunSeconds: 60, DependsOn: []string{"b"}},
+					{ID: "b", Activity: evego.Manufacturing, Runs: 1, RunSeconds: 60, DependsOn: []string{"a"}},
+				},
+			}
+
+			Convey("An error is returned.", func() {
+				_, err := industry.PlanJobs(req)
+				So(err, ShouldEqual, industry.ErrDependencyCycle)
+			})
+		})
+	})
+}
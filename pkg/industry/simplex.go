@@ -0,0 +1,194 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"errors"
+	"math"
+)
+
+// lpEpsilon is the tolerance used when comparing tableau entries to zero.
+const lpEpsilon = 1e-9
+
+var (
+	// ErrInfeasible is returned when a linear program has no solution that
+	// satisfies all of its constraints.
+	ErrInfeasible = errors.New("The linear program is infeasible")
+	// ErrUnbounded is returned when a linear program's objective can be made
+	// arbitrarily small.
+	ErrUnbounded = errors.New("The linear program is unbounded")
+)
+
+// tableau is a simplex tableau. The last row is the objective (reduced
+// costs), and the last column is the right-hand side.
+type tableau struct {
+	rows  [][]float64
+	basis []int
+}
+
+func (t *tableau) numCols() int {
+	return len(t.rows[0]) - 1
+}
+
+func (t *tableau) pivot(row, col int) {
+	pivotRow := t.rows[row]
+	pivotVal := pivotRow[col]
+	for j := range pivotRow {
+		pivotRow[j] /= pivotVal
+	}
+	for i, r := range t.rows {
+		if i == row || r[col] == 0 {
+			continue
+		}
+		factor := r[col]
+		for j := range r {
+			r[j] -= factor * pivotRow[j]
+		}
+	}
+	t.basis[row] = col
+}
+
+// setObjective replaces the objective row with the reduced costs for the
+// given cost vector under the current basis.
+func (t *tableau) setObjective(cost []float64) {
+	obj := t.rows[len(t.rows)-1]
+	for j := range obj {
+		obj[j] = 0
+	}
+	copy(obj, cost)
+	for i, b := range t.basis {
+		if cost[b] == 0 {
+			continue
+		}
+		for j := range obj {
+			obj[j] -= cost[b] * t.rows[i][j]
+		}
+	}
+}
+
+// minimize runs the simplex method on the tableau's current objective,
+// considering only the first numAllowed columns as entering variables.
+// Bland's rule is used to prevent cycling.
+func (t *tableau) minimize(numAllowed int) error {
+	m := len(t.basis)
+	obj := t.rows[m]
+	rhs := t.numCols()
+	for {
+		entering := -1
+		for j := 0; j < numAllowed; j++ {
+			if obj[j] < -lpEpsilon {
+				entering = j
+				break
+			}
+		}
+		if entering == -1 {
+			// Optimal.
+			return nil
+		}
+		leaving := -1
+		bestRatio := math.Inf(1)
+		for i := 0; i < m; i++ {
+			coeff := t.rows[i][entering]
+			if coeff <= lpEpsilon {
+				continue
+			}
+			ratio := t.rows[i][rhs] / coeff
+			if ratio < bestRatio-lpEpsilon ||
+				(ratio < bestRatio+lpEpsilon && leaving != -1 && t.basis[i] < t.basis[leaving]) {
+				bestRatio = ratio
+				leaving = i
+			}
+		}
+		if leaving == -1 {
+			return ErrUnbounded
+		}
+		t.pivot(leaving, entering)
+	}
+}
+
+// solveLP minimizes cost·x subject to a·x >= b and x >= 0, using the
+// two-phase simplex method. It returns the optimal x.
+func solveLP(cost []float64, a [][]float64, b []float64) ([]float64, error) {
+	m, n := len(a), len(cost)
+	// Columns: n decision variables, m surplus variables, m artificial
+	// variables, and the right-hand side.
+	numCols := n + 2*m
+	t := &tableau{
+		rows:  make([][]float64, m+1),
+		basis: make([]int, m),
+	}
+	for i := 0; i < m; i++ {
+		row := make([]float64, numCols+1)
+		sign := 1.0
+		if b[i] < 0 {
+			// Keep the right-hand side non-negative.
+			sign = -1.0
+		}
+		for j := 0; j < n; j++ {
+			row[j] = sign * a[i][j]
+		}
+		row[n+i] = -sign
+		row[n+m+i] = 1
+		row[numCols] = sign * b[i]
+		t.rows[i] = row
+		t.basis[i] = n + m + i
+	}
+	t.rows[m] = make([]float64, numCols+1)
+
+	// Phase 1: minimize the sum of the artificial variables.
+	phase1 := make([]float64, numCols)
+	for i := 0; i < m; i++ {
+		phase1[n+m+i] = 1
+	}
+	t.setObjective(phase1)
+	if err := t.minimize(numCols); err != nil {
+		return nil, err
+	}
+	if -t.rows[m][numCols] > lpEpsilon*float64(1+m) {
+		return nil, ErrInfeasible
+	}
+	// Drive any artificial variables remaining in the basis (at zero) out.
+	for i, bv := range t.basis {
+		if bv < n+m {
+			continue
+		}
+		for j := 0; j < n+m; j++ {
+			if math.Abs(t.rows[i][j]) > lpEpsilon {
+				t.pivot(i, j)
+				break
+			}
+		}
+	}
+
+	// Phase 2: minimize the real objective, never letting an artificial
+	// variable re-enter the basis.
+	phase2 := make([]float64, numCols)
+	copy(phase2, cost)
+	t.setObjective(phase2)
+	if err := t.minimize(n + m); err != nil {
+		return nil, err
+	}
+
+	x := make([]float64, n)
+	for i, bv := range t.basis {
+		if bv < n {
+			x[bv] = t.rows[i][numCols]
+		}
+	}
+	return x, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSimplex(t *testing.T) {
+	Convey("Given a feasible linear program", t, func() {
+		// The classic diet problem: minimize 0.6x + 0.35y
+		// subject to 5x + 7y >= 8, 4x + 2y >= 15, 2x + y >= 3.
+		cost := []float64{0.6, 0.35}
+		a := [][]float64{
+			{5, 7},
+			{4, 2},
+			{2, 1},
+		}
+		b := []float64{8, 15, 3}
+
+		Convey("The optimal solution is found.", func() {
+			x, err := solveLP(cost, a, b)
+			So(err, ShouldBeNil)
+			So(x, ShouldHaveLength, 2)
+			So(x[0], ShouldAlmostEqual, 3.75, 1e-6)
+			So(x[1], ShouldAlmostEqual, 0, 1e-6)
+		})
+	})
+
+	Convey("Given a program where a cheaper mix beats a single input", t, func() {
+		// Two minerals; ore A yields only the first, ore B only the second,
+		// and ore C yields both but costs slightly more than A or B alone.
+		cost := []float64{1, 1, 1.5}
+		a := [][]float64{
+			{1, 0, 1},
+			{0, 1, 1},
+		}
+		b := []float64{10, 10}
+
+		Convey("The mixed ore is chosen.", func() {
+			x, err := solveLP(cost, a, b)
+			So(err, ShouldBeNil)
+			total := 0.0
+			for i := range x {
+				total += x[i] * cost[i]
+			}
+			So(total, ShouldAlmostEqual, 15, 1e-6)
+			So(x[2], ShouldAlmostEqual, 10, 1e-6)
+		})
+	})
+
+	Convey("Given an infeasible linear program", t, func() {
+		cost := []float64{1}
+		a := [][]float64{{0}}
+		b := []float64{1}
+
+		Convey("ErrInfeasible is returned.", func() {
+			_, err := solveLP(cost, a, b)
+			So(err, ShouldEqual, ErrInfeasible)
+		})
+	})
+
+	Convey("Given an unbounded linear program", t, func() {
+		cost := []float64{-1}
+		a := [][]float64{{1}}
+		b := []float64{1}
+
+		Convey("ErrUnbounded is returned.", func() {
+			_, err := solveLP(cost, a, b)
+			So(err, ShouldEqual, ErrUnbounded)
+		})
+	})
+
+	Convey("Solutions are non-negative.", t, func() {
+		x, err := solveLP([]float64{2, 3}, [][]float64{{1, 1}}, []float64{4})
+		So(err, ShouldBeNil)
+		for _, v := range x {
+			So(v, ShouldBeGreaterThanOrEqualTo, -1e-9)
+		}
+		So(math.Abs(x[0]-4), ShouldBeLessThan, 1e-6)
+	})
+}
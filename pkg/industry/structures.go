@@ -0,0 +1,62 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package industry
+
+// StructureType is a player-owned structure that provides industrial services.
+type StructureType int
+
+// The StructureType values.
+const (
+	Athanor StructureType = iota
+	Tatara
+)
+
+// RigTier is the tech level of an engineering rig fitted to a structure.
+type RigTier int
+
+// The RigTier values.
+const (
+	NoRig RigTier = iota
+	T1Rig
+	T2Rig
+)
+
+// SecurityBand is the security classification of the solar system in which
+// a structure is anchored. Rig bonuses are scaled by the system's band.
+type SecurityBand int
+
+// The SecurityBand values. Wormhole space is treated as null-sec.
+const (
+	HighSec SecurityBand = iota
+	LowSec
+	NullSec
+)
+
+// SecurityBandFor returns the security band of a system with the given
+// true security status.
+func SecurityBandFor(security float64) SecurityBand {
+	switch {
+	case security >= 0.45:
+		// 0.45 and above round to 0.5.
+		return HighSec
+	case security > 0.0:
+		return LowSec
+	default:
+		return NullSec
+	}
+}
@@ -19,6 +19,7 @@ package market
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"encoding/xml"
 	"fmt"
@@ -42,8 +43,8 @@ type eveCentral struct {
 
 // getCachedOrders checks the cache for the results of this function call.
 // If found, the gob will be unmarshalled and returned.
-func (e *eveCentral) getCachedOrders(key string) ([]evego.Order, bool) {
-	gobbed, found := e.respCache.Get(key)
+func (e *eveCentral) getCachedOrders(ctx context.Context, key string) ([]evego.Order, bool) {
+	gobbed, found := e.respCache.GetContext(ctx, key)
 	if found {
 		gobBuffer := bytes.NewBuffer(gobbed)
 		dec := gob.NewDecoder(gobBuffer)
@@ -58,7 +59,7 @@ func (e *eveCentral) getCachedOrders(key string) ([]evego.Order, bool) {
 }
 
 // putCachedOrders puts the specified slice of orders into the cache.
-func (e *eveCentral) putCachedOrders(key string, orders []evego.Order) error {
+func (e *eveCentral) putCachedOrders(ctx context.Context, key string, orders []evego.Order) error {
 	var gobbed bytes.Buffer
 	enc := gob.NewEncoder(&gobbed)
 	err := enc.Encode(&orders)
@@ -67,7 +68,7 @@ func (e *eveCentral) putCachedOrders(key string, orders []evego.Order) error {
 	}
 	// 10 minutes is arbitrary; EVE-Central doesn't request a specific caching
 	// duration.
-	e.respCache.Put(key, gobbed.Bytes(), time.Now().Add(10*time.Minute))
+	e.respCache.PutContext(ctx, key, gobbed.Bytes(), time.Now().Add(10*time.Minute))
 	return nil
 }
 
@@ -85,8 +86,8 @@ func EveCentral(db evego.Database, router evego.Router, xmlAPI evego.XMLAPI, end
 	return &ec
 }
 
-func (e *eveCentral) getURL(u string) ([]byte, error) {
-	req, err := http.NewRequest("GET", u, nil)
+func (e *eveCentral) getURL(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +119,7 @@ type quicklook struct {
 	BuyOrders  []order `xml:"quicklook>buy_orders>order"`
 }
 
-func (e *eveCentral) processOrders(data *quicklook, item *evego.Item, t evego.OrderType) []evego.Order {
+func (e *eveCentral) processOrders(ctx context.Context, data *quicklook, item *evego.Item, t evego.OrderType) ([]evego.Order, error) {
 	var toProcess *[]order
 	// Set up a temporary cache so that we only get each station's object once.
 	stationCache := make(map[int]*evego.Station)
@@ -131,10 +132,13 @@ func (e *eveCentral) processOrders(data *quicklook, item *evego.Item, t evego.Or
 	results := []evego.Order{}
 	for _, o := range *toProcess {
 		if stationCache[o.StationID] == nil {
-			sta, err := e.db.StationForID(o.StationID)
+			sta, err := e.db.StationForIDContext(ctx, o.StationID)
 			if err != nil {
 				// If it's not in the static databse, it's an outpost.
-				sta, err = e.xmlAPI.OutpostForID(o.StationID)
+				sta, err = e.xmlAPI.OutpostForIDContext(ctx, o.StationID)
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
 				if err != nil {
 					// Make a dummy station.
 					sta = &evego.Station{
@@ -171,13 +175,17 @@ func (e *eveCentral) processOrders(data *quicklook, item *evego.Item, t evego.Or
 		}
 		results = append(results, newOrder)
 	}
-	return results
+	return results, nil
 }
 
 func (e *eveCentral) OrdersForItem(item *evego.Item, location string, orderType evego.OrderType) (*[]evego.Order, error) {
+	return e.OrdersForItemContext(context.Background(), item, location, orderType)
+}
+
+func (e *eveCentral) OrdersForItemContext(ctx context.Context, item *evego.Item, location string, orderType evego.OrderType) (*[]evego.Order, error) {
 	// Check cache.
 	cacheKey := fmt.Sprintf("evecentral:orders:%v:%v:%v", orderType.String(), item.ID, location)
-	cached, found := e.getCachedOrders(cacheKey)
+	cached, found := e.getCachedOrders(ctx, cacheKey)
 	if found {
 		return &cached, nil
 	}
@@ -186,10 +194,10 @@ func (e *eveCentral) OrdersForItem(item *evego.Item, location string, orderType
 		region *evego.Region
 		err    error
 	)
-	system, err = e.db.SolarSystemForName(location)
+	system, err = e.db.SolarSystemForNameContext(ctx, location)
 	if err != nil {
 		// Not a system or unable to look up. Try region.
-		region, err = e.db.RegionForName(location)
+		region, err = e.db.RegionForNameContext(ctx, location)
 		if err != nil {
 			// Still can't find it. Return an error.
 			return nil, err
@@ -203,7 +211,7 @@ func (e *eveCentral) OrdersForItem(item *evego.Item, location string, orderType
 	}
 	query.Set("typeid", fmt.Sprintf("%d", item.ID))
 	e.endpoint.RawQuery = query.Encode()
-	orderXML, err := e.getURL(e.endpoint.String())
+	orderXML, err := e.getURL(ctx, e.endpoint.String())
 	if err != nil {
 		return nil, err
 	}
@@ -216,77 +224,75 @@ func (e *eveCentral) OrdersForItem(item *evego.Item, location string, orderType
 	}
 
 	// Convert returned XML struct into what we present to rest of library.
-	results := []evego.Order{}
+	var results []evego.Order
 	switch orderType {
 	case evego.AllOrders:
 		// The order here matters, if only because it's the order that the
 		// orders are presented by EVE Central and therefore the order in which
 		// the test cases expect results.
-		results = append(results, e.processOrders(orders, item, evego.Sell)...)
-		results = append(results, e.processOrders(orders, item, evego.Buy)...)
+		results, err = e.processOrders(ctx, orders, item, evego.Sell)
+		if err != nil {
+			return nil, err
+		}
+		buyOrders, err := e.processOrders(ctx, orders, item, evego.Buy)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, buyOrders...)
 	default:
-		results = e.processOrders(orders, item, orderType)
+		results, err = e.processOrders(ctx, orders, item, orderType)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Cache and return the results.
-	e.putCachedOrders(cacheKey, results)
+	e.putCachedOrders(ctx, cacheKey, results)
 	return &results, nil
 }
 
 func (e *eveCentral) BuyInStation(item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
-	system, err := e.db.SolarSystemForID(location.SystemID)
+	return e.BuyInStationContext(context.Background(), item, location)
+}
+
+func (e *eveCentral) BuyInStationContext(ctx context.Context, item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	system, err := e.db.SolarSystemForIDContext(ctx, location.SystemID)
 	if err != nil {
 		return nil, err
 	}
-	regionalOrders, err := e.OrdersForItem(item, system.Region, evego.Buy)
+	regionalOrders, err := e.OrdersForItemContext(ctx, item, system.Region, evego.Buy)
 	if err != nil {
 		return nil, err
 	}
-	orders := []evego.Order{}
-	for _, o := range *regionalOrders {
-		switch o.JumpRange {
-		case evego.BuyRegion:
-			orders = append(orders, o)
-		case evego.BuyNumberJumps:
-			numJumps, err := e.router.NumJumpsID(o.Station.SystemID, location.SystemID)
-			if err != nil {
-				return nil, err
-			}
-			if numJumps <= o.NumJumps {
-				orders = append(orders, o)
-			}
-		case evego.BuySystem:
-			if o.Station.SystemID == location.SystemID {
-				orders = append(orders, o)
-			}
-		case evego.BuyStation:
-			if o.Station.ID == location.ID {
-				orders = append(orders, o)
-			}
-		}
+	orders, err := buyOrdersInRange(ctx, e.router, *regionalOrders, location)
+	if err != nil {
+		return nil, err
 	}
-
 	return &orders, nil
 }
 
 func (e *eveCentral) OrdersInStation(item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	return e.OrdersInStationContext(context.Background(), item, location)
+}
+
+func (e *eveCentral) OrdersInStationContext(ctx context.Context, item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
 	// Check cache.
 	cacheKey := fmt.Sprintf("evecentral:orders-station:%v:%v", item.ID, location.ID)
-	cached, found := e.getCachedOrders(cacheKey)
+	cached, found := e.getCachedOrders(ctx, cacheKey)
 	if found {
 		return &cached, nil
 	}
-	orders, err := e.BuyInStation(item, location)
+	orders, err := e.BuyInStationContext(ctx, item, location)
 	if err != nil {
 		return nil, err
 	}
 	// Get the sell orders for the entire system, then append the ones for this
 	// station to the returned array.
-	orderSystem, err := e.db.SolarSystemForID(location.SystemID)
+	orderSystem, err := e.db.SolarSystemForIDContext(ctx, location.SystemID)
 	if err != nil {
 		return nil, err
 	}
-	sellInSystem, err := e.OrdersForItem(item, orderSystem.Name, evego.Sell)
+	sellInSystem, err := e.OrdersForItemContext(ctx, item, orderSystem.Name, evego.Sell)
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +303,7 @@ func (e *eveCentral) OrdersInStation(item *evego.Item, location *evego.Station)
 	}
 
 	// Cache and return the results.
-	e.putCachedOrders(cacheKey, *orders)
+	e.putCachedOrders(ctx, cacheKey, *orders)
 	return orders, nil
 }
 
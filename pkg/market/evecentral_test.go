@@ -150,7 +150,8 @@ func TestMarketOrders(t *testing.T) {
 					},
 				})
 		})
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		defer db.Close()
 		var router evego.Router
 		if testDbDriver == "sqlite3" {
@@ -316,7 +317,8 @@ func TestOutpostOrders(t *testing.T) {
 			}))
 		defer tsXMLAPI.Close()
 
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		xmlAPI := eveapi.XML(tsXMLAPI.URL, db, cache.NilCache())
 		ec := market.EveCentral(db, nil, xmlAPI, ts.URL, cache.NilCache())
 
@@ -364,7 +366,8 @@ func TestConnectionError(t *testing.T) {
 				http.Error(w, "Test error", http.StatusInternalServerError)
 			}))
 		// defer ts.Close()
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		xmlAPI := eveapi.XML(ts.URL, db, cache.NilCache())
 		ec := market.EveCentral(db, nil, xmlAPI, ts.URL, cache.NilCache())
 
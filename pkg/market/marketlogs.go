@@ -0,0 +1,252 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package market
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/parsing"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Matches the name of a market export, e.g.
+// "Sinq Laison-Tritanium-2015.02.17 032750.txt".
+var marketLogName = regexp.MustCompile(`^(.+)-(\d{4}\.\d{2}\.\d{2} \d{6})\.txt$`)
+
+// The format of the export time in a market export's name.
+const marketLogNameTime = "2006.01.02 150405"
+
+// exportKey identifies the item and region whose orders are in an export.
+type exportKey struct {
+	regionID, typeID int
+}
+
+// marketExport is the orders read from one market export.
+type marketExport struct {
+	exported time.Time
+	orders   []evego.Order
+}
+
+type marketLogs struct {
+	db      evego.Database
+	router  evego.Router
+	watcher *fsnotify.Watcher
+
+	// The latest export for each item and region.
+	lock    sync.RWMutex
+	exports map[exportKey]*marketExport
+}
+
+// MarketLogs returns an interface to the market exports saved by the EVE
+// client in a directory (normally Documents/EVE/logs/Marketlogs). Only the
+// latest export of an item's orders in each region is used, and new exports
+// are read as they're saved.
+func MarketLogs(dir string, db evego.Database, router evego.Router) (evego.Market, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	m := &marketLogs{
+		db:      db,
+		router:  router,
+		watcher: watcher,
+		exports: make(map[exportKey]*marketExport),
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	for _, f := range files {
+		m.load(f)
+	}
+	go m.watch()
+	return m, nil
+}
+
+// watch reads new market exports until the watcher is closed.
+func (m *marketLogs) watch() {
+	for {
+		select {
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+				m.load(event.Name)
+			}
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Unable to watch market exports: %v", err)
+		}
+	}
+}
+
+// exportKey returns the item and region of a market export, and the time at
+// which it was saved, from its file name.
+func (m *marketLogs) exportKey(name string) (exportKey, time.Time, bool) {
+	matches := marketLogName.FindStringSubmatch(filepath.Base(name))
+	if matches == nil {
+		return exportKey{}, time.Time{}, false
+	}
+	exported, err := time.Parse(marketLogNameTime, matches[2])
+	if err != nil {
+		return exportKey{}, time.Time{}, false
+	}
+	// Both region and item names can contain hyphens, so try each one in turn
+	// as the separator.
+	regionItem := matches[1]
+	for i := strings.Index(regionItem, "-"); i != -1; {
+		region, err := m.db.RegionForName(regionItem[:i])
+		if err == nil {
+			item, err := m.db.ItemForName(regionItem[i+1:])
+			if err == nil {
+				return exportKey{region.ID, item.ID}, exported, true
+			}
+		}
+		next := strings.Index(regionItem[i+1:], "-")
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return exportKey{}, time.Time{}, false
+}
+
+// load reads a market export, if it's newer than the one that we have for
+// its item and region.
+func (m *marketLogs) load(name string) {
+	key, exported, ok := m.exportKey(name)
+	if !ok {
+		return
+	}
+	m.lock.RLock()
+	current := m.exports[key]
+	m.lock.RUnlock()
+	if current != nil && current.exported.After(exported) {
+		return
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		log.Printf("Unable to open market export %v: %v", name, err)
+		return
+	}
+	defer f.Close()
+	orders, err := parsing.ParseMarketLog(f, m.db)
+	if err != nil {
+		// The client may not have finished writing the file; we'll try again
+		// when it does.
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if current := m.exports[key]; current == nil || !current.exported.After(exported) {
+		m.exports[key] = &marketExport{exported: exported, orders: orders}
+	}
+}
+
+// orders returns the orders of the given type from the latest export of an
+// item's orders in a region, for which keep returns true.
+func (m *marketLogs) orders(regionID int, item *evego.Item, orderType evego.OrderType, keep func(o *evego.Order) bool) []evego.Order {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	results := []evego.Order{}
+	export := m.exports[exportKey{regionID, item.ID}]
+	if export == nil {
+		return results
+	}
+	for _, o := range export.orders {
+		if (orderType == evego.AllOrders || o.Type == orderType) && keep(&o) {
+			results = append(results, o)
+		}
+	}
+	return results
+}
+
+func (m *marketLogs) OrdersForItem(item *evego.Item, location string, orderType evego.OrderType) (*[]evego.Order, error) {
+	return m.OrdersForItemContext(context.Background(), item, location, orderType)
+}
+
+func (m *marketLogs) OrdersForItemContext(ctx context.Context, item *evego.Item, location string, orderType evego.OrderType) (*[]evego.Order, error) {
+	var results []evego.Order
+	system, err := m.db.SolarSystemForNameContext(ctx, location)
+	if err == nil {
+		results = m.orders(system.RegionID, item, orderType, func(o *evego.Order) bool {
+			return o.Station.SystemID == system.ID
+		})
+		return &results, nil
+	}
+	// Not a system or unable to look up. Try region.
+	region, err := m.db.RegionForNameContext(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	results = m.orders(region.ID, item, orderType, func(o *evego.Order) bool {
+		return true
+	})
+	return &results, nil
+}
+
+func (m *marketLogs) BuyInStation(item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	return m.BuyInStationContext(context.Background(), item, location)
+}
+
+func (m *marketLogs) BuyInStationContext(ctx context.Context, item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	regionalOrders := m.orders(location.RegionID, item, evego.Buy, func(o *evego.Order) bool {
+		return true
+	})
+	orders, err := buyOrdersInRange(ctx, m.router, regionalOrders, location)
+	if err != nil {
+		return nil, err
+	}
+	return &orders, nil
+}
+
+func (m *marketLogs) OrdersInStation(item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	return m.OrdersInStationContext(context.Background(), item, location)
+}
+
+func (m *marketLogs) OrdersInStationContext(ctx context.Context, item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	orders, err := m.BuyInStationContext(ctx, item, location)
+	if err != nil {
+		return nil, err
+	}
+	sellInStation := m.orders(location.RegionID, item, evego.Sell, func(o *evego.Order) bool {
+		return o.Station.ID == location.ID
+	})
+	*orders = append(*orders, sellInStation...)
+	return orders, nil
+}
+
+func (m *marketLogs) Close() error {
+	return m.watcher.Close()
+}
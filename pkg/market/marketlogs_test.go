@@ -0,0 +1,170 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package market_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/market"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testMarketLogDir = "../../testdata/marketlogs"
+
+// fixedRouter says that every pair of systems is three jumps apart.
+type fixedRouter struct{}
+
+func (fixedRouter) NumJumps(fromSystem, toSystem *evego.SolarSystem) (int, error) {
+	return 3, nil
+}
+
+func (fixedRouter) NumJumpsContext(ctx context.Context, fromSystem, toSystem *evego.SolarSystem) (int, error) {
+	return 3, nil
+}
+
+func (fixedRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
+	return 3, nil
+}
+
+func (fixedRouter) NumJumpsIDContext(ctx context.Context, fromSystemID, toSystemID int) (int, error) {
+	return 3, nil
+}
+
+func (fixedRouter) Close() error {
+	return nil
+}
+
+// copyExport copies a market export from the test data into a directory.
+func copyExport(name, dir string) error {
+	data, err := ioutil.ReadFile(filepath.Join(testMarketLogDir, name))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+}
+
+func prices(orders *[]evego.Order) []float64 {
+	var result []float64
+	for _, o := range *orders {
+		result = append(result, o.Price)
+	}
+	return result
+}
+
+func TestMarketLogs(t *testing.T) {
+	Convey("Given a directory of market exports", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+		m, err := market.MarketLogs(testMarketLogDir, db, fixedRouter{})
+		So(err, ShouldBeNil)
+		defer m.Close()
+		item, err := db.ItemForName("Tritanium")
+		So(err, ShouldBeNil)
+
+		Convey("Only the latest export is used.", func() {
+			orders, err := m.OrdersForItem(item, "Sinq Laison", evego.Sell)
+			So(err, ShouldBeNil)
+			So(prices(orders), ShouldResemble, []float64{5.5, 5.75, 6.0})
+		})
+
+		Convey("Orders can be limited to a system.", func() {
+			orders, err := m.OrdersForItem(item, "Dodixie", evego.AllOrders)
+			So(err, ShouldBeNil)
+			So(*orders, ShouldHaveLength, 7)
+		})
+
+		Convey("Regions without exports have no orders.", func() {
+			orders, err := m.OrdersForItem(item, "Placid", evego.AllOrders)
+			So(err, ShouldBeNil)
+			So(*orders, ShouldBeEmpty)
+		})
+
+		Convey("Unknown locations are an error.", func() {
+			_, err := m.OrdersForItem(item, "Nowhere", evego.AllOrders)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("The orders in a station are those that can be traded there.", func() {
+			// Dodixie V - Moon 5 - Refinery
+			station, err := db.StationForID(60011867)
+			So(err, ShouldBeNil)
+			buy, err := m.BuyInStation(item, station)
+			So(err, ShouldBeNil)
+			So(prices(buy), ShouldResemble, []float64{4.0, 4.5, 4.25, 4.1})
+			orders, err := m.OrdersInStation(item, station)
+			So(err, ShouldBeNil)
+			So(prices(orders), ShouldResemble, []float64{4.0, 4.5, 4.25, 4.1, 5.75})
+
+			// Dodixie IX - Moon 20 - Federation Navy Assembly Plant
+			station, err = db.StationForID(60011866)
+			So(err, ShouldBeNil)
+			buy, err = m.BuyInStation(item, station)
+			So(err, ShouldBeNil)
+			So(prices(buy), ShouldResemble, []float64{4.0, 4.25, 4.1})
+		})
+	})
+
+	Convey("Given an empty directory", t, func() {
+		dir, err := ioutil.TempDir("", "marketlogs")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+		m, err := market.MarketLogs(dir, db, fixedRouter{})
+		So(err, ShouldBeNil)
+		defer m.Close()
+		item, err := db.ItemForName("Tritanium")
+		So(err, ShouldBeNil)
+
+		Convey("New exports are read when they're saved.", func() {
+			orders, err := m.OrdersForItem(item, "Sinq Laison", evego.Sell)
+			So(err, ShouldBeNil)
+			So(*orders, ShouldBeEmpty)
+
+			So(copyExport("Sinq Laison-Tritanium-2015.02.16 120000.txt", dir), ShouldBeNil)
+			deadline := time.Now().Add(5 * time.Second)
+			for len(*orders) == 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+				orders, err = m.OrdersForItem(item, "Sinq Laison", evego.Sell)
+				So(err, ShouldBeNil)
+			}
+			So(prices(orders), ShouldResemble, []float64{7.0})
+		})
+	})
+
+	Convey("Given a directory that doesn't exist", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("An error is returned.", func() {
+			_, err := market.MarketLogs("/nonexistent/marketlogs", db, fixedRouter{})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
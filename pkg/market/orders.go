@@ -0,0 +1,53 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package market
+
+import (
+	"context"
+
+	"github.com/backerman/evego"
+)
+
+// buyOrdersInRange returns the buy orders that can be sold to from the given
+// station.
+func buyOrdersInRange(ctx context.Context, router evego.Router, buyOrders []evego.Order, location *evego.Station) ([]evego.Order, error) {
+	orders := []evego.Order{}
+	for _, o := range buyOrders {
+		switch o.JumpRange {
+		case evego.BuyRegion:
+			orders = append(orders, o)
+		case evego.BuyNumberJumps:
+			numJumps, err := router.NumJumpsIDContext(ctx, o.Station.SystemID, location.SystemID)
+			if err != nil {
+				return nil, err
+			}
+			if numJumps <= o.NumJumps {
+				orders = append(orders, o)
+			}
+		case evego.BuySystem:
+			if o.Station.SystemID == location.SystemID {
+				orders = append(orders, o)
+			}
+		case evego.BuyStation:
+			if o.Station.ID == location.ID {
+				orders = append(orders, o)
+			}
+		}
+	}
+	return orders, nil
+}
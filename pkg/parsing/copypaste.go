@@ -20,19 +20,31 @@ limitations under the License.
 package parsing
 
 import (
-	"encoding/csv"
-	"fmt"
+	"database/sql"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/backerman/evego"
 )
 
 var (
-	// Matches a line such as "1,367,093 x Tritanium".
-	industryLine = regexp.MustCompile(`^\s*([.\d,]+)\s*x?\s*(.*?)\s*$`)
+	// Matches a line such as "1,367,093 x Tritanium" or "100 Tritanium".
+	industryLine = regexp.MustCompile(`^([\d.,]+)(?:\s*x\s+|\s+)(.+?)$`)
+	// Matches a line such as "Tritanium x 100".
+	suffixLine = regexp.MustCompile(`^(.+?)\s+x\s*([\d.,]+)$`)
+	// Matches a quantity such as "1,367,093".
+	quantity = regexp.MustCompile(`^[\d.,\s\x{a0}]+$`)
+	// Matches an estimated price such as "1,234.56 ISK".
+	iskPrice = regexp.MustCompile(`^([\d.,\s\x{a0}]+)\s*ISK$`)
+	// Matches a blueprint copy's name in a contract, e.g.
+	// "Vexor Blueprint (Copy)".
+	copySuffix = regexp.MustCompile(`(?i)^(.+?)\s*(?:\((?:Blueprint )?Copy\)|- Blueprint Copy)$`)
 )
 
+// The number of search results to consider when a name isn't exact.
+const searchLimit = 20
+
 // removeNonNumeric removes the separators (comma and/or full stop)
 // from the string representation of an integer.
 func removeNonNumeric(s string) string {
@@ -45,58 +57,243 @@ func removeNonNumeric(s string) string {
 	return string(newStrBuf)
 }
 
+// closestItem returns the item whose name is the same as the input except for
+// case or a small number of typos, or nil if there isn't one.
+func closestItem(itemName string, database evego.Database) *evego.Item {
+	matches, err := database.SearchItems(itemName, searchLimit)
+	if err != nil {
+		return nil
+	}
+	// Prefix and substring matches rank ahead of misspellings, so look past
+	// them for the best misspelling.
+	for _, m := range matches {
+		if m.Distance >= 0 {
+			return m.Item
+		}
+	}
+	return nil
+}
+
+// matchesWhere returns the search results for which keep returns true.
+func matchesWhere(matches []evego.ItemMatch, keep func(m evego.ItemMatch) bool) []evego.ItemMatch {
+	var kept []evego.ItemMatch
+	for _, m := range matches {
+		if keep(m) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// findItem looks up the item named on a pasted line, using the group and
+// category columns (if present) to choose between similar names. It returns
+// the item, or the candidates if the name is ambiguous, or neither if there's
+// no such item.
+func findItem(name, group, category string, database evego.Database) (*evego.Item, []*evego.Item, error) {
+	exact, err := database.ItemForName(name)
+	switch {
+	case err == nil && (group == "" || strings.EqualFold(exact.Group, group)):
+		return exact, nil, nil
+	case err == sql.ErrNoRows:
+		exact = nil
+	case err != nil:
+		return nil, nil, err
+	}
+	matches, err := database.SearchItems(name, searchLimit)
+	if err == sql.ErrNoRows {
+		return exact, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// Without any other information, only accept misspellings.
+	candidates := matchesWhere(matches, func(m evego.ItemMatch) bool {
+		return m.Distance >= 0
+	})
+	if group != "" {
+		inGroup := matchesWhere(matches, func(m evego.ItemMatch) bool {
+			return strings.EqualFold(m.Item.Group, group)
+		})
+		switch {
+		case len(inGroup) > 0:
+			candidates = inGroup
+		case exact != nil:
+			// The group column doesn't help; trust the name.
+			return exact, nil, nil
+		}
+	}
+	if category != "" && len(candidates) > 1 {
+		inCategory := matchesWhere(candidates, func(m evego.ItemMatch) bool {
+			return strings.EqualFold(m.Item.Category, category)
+		})
+		if len(inCategory) > 0 {
+			candidates = inCategory
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil, nil
+	}
+	// The name is ambiguous if the best matches are equally good.
+	best := candidates[0]
+	tied := matchesWhere(candidates, func(m evego.ItemMatch) bool {
+		return m.Kind == best.Kind && m.Distance == best.Distance &&
+			m.Published == best.Published && m.OnMarket == best.OnMarket
+	})
+	if len(tied) == 1 {
+		return best.Item, nil, nil
+	}
+	items := make([]*evego.Item, len(tied))
+	for i, m := range tied {
+		items[i] = m.Item
+	}
+	return nil, items, nil
+}
+
+// parseQuantity converts a quantity with thousands separators to an integer.
+// An empty quantity, as shown for a single unstackable item, is 1.
+func parseQuantity(s string) (int, bool) {
+	if s == "" {
+		return 1, true
+	}
+	if !quantity.MatchString(s) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(removeNonNumeric(s))
+	return n, err == nil
+}
+
+// parsePrice converts an estimated price such as "1,234.56 ISK" to a number.
+func parsePrice(s string) (float64, bool) {
+	matches := iskPrice.FindStringSubmatch(s)
+	if matches == nil {
+		return 0, false
+	}
+	number := strings.Map(func(r rune) rune {
+		if r == '.' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, matches[1])
+	price, err := strconv.ParseFloat(number, 64)
+	return price, err == nil
+}
+
+// pastedLine is a line of a pasted inventory, split into its parts.
+type pastedLine struct {
+	name, group, category string
+	quantity              int
+	price                 float64
+	copy                  bool
+}
+
+// splitLine extracts the parts of a line in any of the formats that
+// ParseInventory accepts.
+func splitLine(line string) (pastedLine, evego.ParseProblem, bool) {
+	var parsed pastedLine
+	fields := strings.Split(line, "\t")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) == 1 {
+		var qtyStr string
+		if matches := industryLine.FindStringSubmatch(fields[0]); matches != nil {
+			// "123,456,789 x Something"
+			qtyStr, parsed.name = matches[1], matches[2]
+		} else if matches := suffixLine.FindStringSubmatch(fields[0]); matches != nil {
+			// "Something x 123,456,789"
+			parsed.name, qtyStr = matches[1], matches[2]
+		} else {
+			// Just the name of a single item.
+			parsed.name = fields[0]
+		}
+		var ok bool
+		if parsed.quantity, ok = parseQuantity(qtyStr); !ok {
+			return parsed, evego.BadQuantity, false
+		}
+	} else {
+		// Name, quantity, group, and then (depending on the window) category,
+		// size, slot, volume, details, estimated price.
+		parsed.name = fields[0]
+		var ok bool
+		if parsed.quantity, ok = parseQuantity(fields[1]); !ok {
+			return parsed, evego.BadQuantity, false
+		}
+		if len(fields) > 2 {
+			parsed.group = fields[2]
+		}
+		if len(fields) > 3 {
+			parsed.category = fields[3]
+		}
+		for _, f := range fields[2:] {
+			if strings.EqualFold(f, "Blueprint Copy") {
+				parsed.copy = true
+			}
+			if price, ok := parsePrice(f); ok {
+				parsed.price = price
+			}
+		}
+	}
+	if matches := copySuffix.FindStringSubmatch(parsed.name); matches != nil {
+		parsed.name, parsed.copy = matches[1], true
+	}
+	if parsed.name == "" {
+		return parsed, evego.UnrecognizedLine, false
+	}
+	return parsed, 0, true
+}
+
 // ParseInventory extracts a item inventory copied from the EVE client.
 // This can be from:
-// * contract
-// * ship/station/container inventory
+// * contract (including blueprint copies)
+// * ship/station/container inventory, in the list or details view
 // * personal assets view
 // * industry tab of item info
-func ParseInventory(pasted string, database evego.Database) []evego.InventoryLine {
-	results := []evego.InventoryLine{}
-	// Break into individual lines.
-	reader := csv.NewReader(strings.NewReader(pasted))
-	reader.Comma = '\t'
-	lines, err := reader.ReadAll()
-	if err != nil {
-		// Unable to parse.
-		return results
-	}
-	for _, line := range lines {
-		var (
-			quantity int
-			itemName string
-		)
-		// Line is a []string with the fields in the line.
-		if len(line) == 1 {
-			// "123,456,789x Something"
-			matches := industryLine.FindStringSubmatch(line[0])
-			if matches == nil {
-				continue
-			}
-			var err error
-			_, err = fmt.Sscanf(removeNonNumeric(matches[1]), "%d", &quantity)
-			if err != nil {
-				continue
-			}
-			itemName = matches[2]
-		} else {
-			itemName = line[0]
-			numScanned, _ := fmt.Sscanf(line[1], "%d", &quantity)
-			if numScanned != 1 {
-				// Couldn't scan a valid quantity.
-				continue
-			}
+// * cargo scan results
+//
+// Lines that can't be read are skipped, and described in the result's
+// diagnostics. An error is returned only if the database fails.
+func ParseInventory(pasted string, database evego.Database) (*evego.PastedInventory, error) {
+	results := &evego.PastedInventory{
+		Items:       []evego.PastedItem{},
+		Diagnostics: []evego.ParseDiagnostic{},
+	}
+	for i, line := range strings.Split(pasted, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
 		}
-		// FIXME: If available, use group to disambiguate item.
-		// group := line[2]
-		// See if we have a match.
-		item, err := database.ItemForName(itemName)
+		diagnostic := evego.ParseDiagnostic{LineNumber: i + 1, Line: line}
+		parsed, problem, ok := splitLine(line)
+		if !ok {
+			diagnostic.Problem = problem
+			results.Diagnostics = append(results.Diagnostics, diagnostic)
+			continue
+		}
+		item, candidates, err := findItem(parsed.name, parsed.group, parsed.category, database)
 		if err != nil {
-			// Didn't find a matching item.
+			return nil, err
+		}
+		if item == nil {
+			diagnostic.Problem = evego.UnknownItem
+			if candidates != nil {
+				diagnostic.Problem = evego.AmbiguousName
+				diagnostic.Candidates = candidates
+			}
+			results.Diagnostics = append(results.Diagnostics, diagnostic)
 			continue
 		}
-		results = append(results, evego.InventoryLine{Item: item, Quantity: quantity})
-
+		pastedItem := evego.PastedItem{
+			InventoryLine:  evego.InventoryLine{Item: item, Quantity: parsed.quantity},
+			LineNumber:     i + 1,
+			EstimatedPrice: parsed.price,
+		}
+		switch {
+		case parsed.copy:
+			pastedItem.BlueprintType = evego.BlueprintCopy
+		case item.Category == "Blueprint":
+			pastedItem.BlueprintType = evego.BlueprintOriginal
+		}
+		results.Items = append(results.Items, pastedItem)
 	}
-	return results
+	return results, nil
 }
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/backerman/evego"
 	"github.com/backerman/evego/pkg/dbaccess"
 	"github.com/backerman/evego/pkg/parsing"
 	. "github.com/backerman/evego/pkg/test"
@@ -50,12 +51,14 @@ func TestInventoryCopyPaste(t *testing.T) {
 		inventoryStr := string(inventory)
 		So(err, ShouldBeNil)
 
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		defer db.Close()
 
 		Convey("It is correctly parsed.", func() {
-			parsed := parsing.ParseInventory(inventoryStr, db)
-			So(parsed, ShouldHaveComposition, []Component{
+			parsed, err := parsing.ParseInventory(inventoryStr, db)
+			So(err, ShouldBeNil)
+			So(parsed.Lines(), ShouldHaveComposition, []Component{
 				{"Medium Automated Structural Restoration", 2},
 				{"Large Asymmetric Remote Capacitor Transmitter", 1},
 				{"Tripped Power Circuit", 42},
@@ -78,12 +81,14 @@ func TestIndustryCopyPaste(t *testing.T) {
 		inventoryStr := string(inventory)
 		So(err, ShouldBeNil)
 
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		defer db.Close()
 
 		Convey("It is correctly parsed.", func() {
-			parsed := parsing.ParseInventory(inventoryStr, db)
-			So(parsed, ShouldHaveComposition, []Component{
+			parsed, err := parsing.ParseInventory(inventoryStr, db)
+			So(err, ShouldBeNil)
+			So(parsed.Lines(), ShouldHaveComposition, []Component{
 				{"Tritanium", 1367093},
 				{"Pyerite", 630827},
 				{"Mexallon", 60890},
@@ -96,15 +101,122 @@ func TestIndustryCopyPaste(t *testing.T) {
 	})
 }
 
+func TestMisspelledCopyPaste(t *testing.T) {
+	Convey("Given an inventory with misspelled item names", t, func() {
+		inventoryStr := "tritanium\t100\nPyerit\t200\nMegacyte Ore\t10\n"
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("Close matches are found, but partial names are not.", func() {
+			parsed, err := parsing.ParseInventory(inventoryStr, db)
+			So(err, ShouldBeNil)
+			So(parsed.Lines(), ShouldHaveComposition, []Component{
+				{"Tritanium", 100},
+				{"Pyerite", 200},
+			})
+		})
+
+		Convey("Names that aren't found are reported.", func() {
+			parsed, err := parsing.ParseInventory(inventoryStr, db)
+			So(err, ShouldBeNil)
+			So(parsed.Diagnostics, ShouldResemble, []evego.ParseDiagnostic{
+				{LineNumber: 3, Line: "Megacyte Ore\t10", Problem: evego.UnknownItem},
+			})
+		})
+	})
+}
+
 func TestBadCopyPaste(t *testing.T) {
 	Convey("Given completely malformed input", t, func() {
 		inventoryStr := "fred"
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 		defer db.Close()
 
 		Convey("It returns an empty result.", func() {
-			parsed := parsing.ParseInventory(inventoryStr, db)
-			So(parsed, ShouldBeEmpty)
+			parsed, err := parsing.ParseInventory(inventoryStr, db)
+			So(err, ShouldBeNil)
+			So(parsed.Items, ShouldBeEmpty)
+			So(parsed.Diagnostics, ShouldHaveLength, 1)
+			So(parsed.Diagnostics[0].Problem, ShouldEqual, evego.UnknownItem)
+		})
+	})
+}
+
+func TestInventoryFormats(t *testing.T) {
+	Convey("Given a database", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("The details view's estimated prices are read.", func() {
+			parsed, err := parsing.ParseInventory(
+				"Tritanium\t1,000\tMineral\tMaterial\t\t\t10 m3\t5,432.10 ISK\n", db)
+			So(err, ShouldBeNil)
+			So(parsed.Items, ShouldHaveLength, 1)
+			So(parsed.Items[0].Quantity, ShouldEqual, 1000)
+			So(parsed.Items[0].EstimatedPrice, ShouldAlmostEqual, 5432.10)
+			So(parsed.Items[0].BlueprintType, ShouldEqual, evego.NotBlueprint)
+		})
+
+		Convey("Blueprint copies in contracts are recognized.", func() {
+			parsed, err := parsing.ParseInventory("Vexor Blueprint\t1\tCruiser Blueprint\tBlueprint\tBlueprint Copy\n"+
+				"Vexor Blueprint (Copy)\t2\n"+
+				"Vexor Blueprint\t1\tCruiser Blueprint\tBlueprint\n", db)
+			So(err, ShouldBeNil)
+			So(parsed.Items, ShouldHaveLength, 3)
+			for _, item := range parsed.Items {
+				So(item.Item.Name, ShouldEqual, "Vexor Blueprint")
+			}
+			So(parsed.Items[0].BlueprintType, ShouldEqual, evego.BlueprintCopy)
+			So(parsed.Items[1].BlueprintType, ShouldEqual, evego.BlueprintCopy)
+			So(parsed.Items[1].Quantity, ShouldEqual, 2)
+			So(parsed.Items[2].BlueprintType, ShouldEqual, evego.BlueprintOriginal)
+		})
+
+		Convey("Cargo scan results are read.", func() {
+			parsed, err := parsing.ParseInventory("100 Tritanium\nPyerite x 50\nVexor\n", db)
+			So(err, ShouldBeNil)
+			So(parsed.Lines(), ShouldHaveComposition, []Component{
+				{"Tritanium", 100},
+				{"Pyerite", 50},
+				{"Vexor", 1},
+			})
+			So(parsed.Items[2].LineNumber, ShouldEqual, 3)
+		})
+
+		Convey("Bad quantities are reported.", func() {
+			parsed, err := parsing.ParseInventory("Tritanium\tlots\n\nPyerite\t5\n", db)
+			So(err, ShouldBeNil)
+			So(parsed.Items, ShouldHaveLength, 1)
+			So(parsed.Items[0].LineNumber, ShouldEqual, 3)
+			So(parsed.Diagnostics, ShouldResemble, []evego.ParseDiagnostic{
+				{LineNumber: 1, Line: "Tritanium\tlots", Problem: evego.BadQuantity},
+			})
+		})
+
+		Convey("The group column chooses between similar names.", func() {
+			parsed, err := parsing.ParseInventory("Carbon Polymer\t5\n"+
+				"Carbon Polymer\t5\tComposite Reaction Formulae\n", db)
+			So(err, ShouldBeNil)
+			So(parsed.Items, ShouldHaveLength, 2)
+			So(parsed.Items[0].Item.Name, ShouldEqual, "Carbon Polymers")
+			So(parsed.Items[1].Item.Name, ShouldEqual, "Carbon Polymers Reaction Formula")
+		})
+
+		Convey("Ambiguous names are reported with their candidates.", func() {
+			parsed, err := parsing.ParseInventory("EMP X\t100\tProjectile Ammo\n", db)
+			So(err, ShouldBeNil)
+			So(parsed.Items, ShouldBeEmpty)
+			So(parsed.Diagnostics, ShouldHaveLength, 1)
+			diag := parsed.Diagnostics[0]
+			So(diag.Problem, ShouldEqual, evego.AmbiguousName)
+			var names []string
+			for _, c := range diag.Candidates {
+				names = append(names, c.Name)
+			}
+			So(names, ShouldResemble, []string{"EMP M", "EMP S"})
 		})
 	})
 }
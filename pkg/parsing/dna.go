@@ -0,0 +1,290 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+// Matches an in-game fitting link, e.g.
+// "<url=fitting:626:3831;1::>Test Vexor</url>".
+var fittingLink = regexp.MustCompile(`(?i)<url=fitting:([^>]*)>(.*?)</url>`)
+
+// DNA entries with this suffix on the type ID are in the cargo hold.
+const dnaCargo = "_"
+
+// The order in which DNA lists the racks of modules.
+var dnaRacks = []evego.InventoryFlag{
+	evego.InvSubSystem0, evego.InvHiSlot0, evego.InvMedSlot0, evego.InvLoSlot0, evego.InvRigSlot0,
+}
+
+var chargeGroupAttributes = []int{
+	evego.AttributeChargeGroup1, evego.AttributeChargeGroup2, evego.AttributeChargeGroup3,
+	evego.AttributeChargeGroup4, evego.AttributeChargeGroup5,
+}
+
+// dnaEntry is one "typeID;quantity" entry in a DNA string.
+type dnaEntry struct {
+	typeID   int
+	quantity int
+	cargo    bool
+}
+
+func parseDNAEntry(s string) (dnaEntry, error) {
+	entry := dnaEntry{quantity: 1}
+	idStr, qtyStr := s, ""
+	if semi := strings.Index(s, ";"); semi != -1 {
+		idStr, qtyStr = s[:semi], s[semi+1:]
+	}
+	if strings.HasSuffix(idStr, dnaCargo) {
+		entry.cargo = true
+		idStr = strings.TrimSuffix(idStr, dnaCargo)
+	}
+	var err error
+	entry.typeID, err = strconv.Atoi(idStr)
+	if err != nil {
+		return entry, fmt.Errorf("Invalid type ID %q in DNA", idStr)
+	}
+	if qtyStr != "" {
+		entry.quantity, err = strconv.Atoi(qtyStr)
+		if err != nil || entry.quantity < 1 {
+			return entry, fmt.Errorf("Invalid quantity %q in DNA", qtyStr)
+		}
+	}
+	return entry, nil
+}
+
+// ParseDNA decodes a ship DNA string, such as "626:3831;1:2456;5::", or an
+// in-game fitting link containing one. Modules fill their racks in the order
+// listed, and charges are loaded into the modules that can use them; any
+// charges left over go in the cargo hold. DNA doesn't record whether modules
+// are offline, so none of them are.
+//
+// If the DNA refers to type IDs that aren't in the database, the error
+// returned is an *UnknownTypesError listing them.
+func ParseDNA(dna string, database evego.Database) (*evego.Fitting, error) {
+	fit := &evego.Fitting{}
+	dna = strings.TrimSpace(dna)
+	if matches := fittingLink.FindStringSubmatch(dna); matches != nil {
+		dna, fit.Name = matches[1], strings.TrimSpace(matches[2])
+	}
+	dna = strings.TrimPrefix(dna, "fitting:")
+	fields := strings.Split(strings.TrimRight(dna, ":"), ":")
+	shipID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ship type ID %q in DNA", fields[0])
+	}
+	entries := make([]dnaEntry, 0, len(fields)-1)
+	ids := []int{shipID}
+	for _, f := range fields[1:] {
+		if f == "" {
+			continue
+		}
+		entry, err := parseDNAEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		ids = append(ids, entry.typeID)
+	}
+
+	found, err := database.ItemsForIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[int]*evego.Item, len(found))
+	for _, item := range found {
+		items[item.ID] = item
+	}
+	var unknown []int
+	for _, id := range ids {
+		if items[id] == nil {
+			unknown = append(unknown, id)
+			// Only report each ID once.
+			items[id] = &evego.Item{}
+		}
+	}
+	if unknown != nil {
+		sort.Ints(unknown)
+		return nil, &UnknownTypesError{IDs: unknown}
+	}
+	fit.Ship = items[shipID]
+	if fit.Name == "" {
+		fit.Name = fit.Ship.Name
+	}
+
+	slots := newSlotter(database)
+	var charges []evego.InventoryLine
+	for _, entry := range entries {
+		item := items[entry.typeID]
+		stack := evego.InventoryLine{Item: item, Quantity: entry.quantity}
+		if entry.cargo {
+			fit.Cargo = append(fit.Cargo, stack)
+			continue
+		}
+		switch item.Category {
+		case "Drone", "Fighter":
+			fit.Drones = append(fit.Drones, stack)
+			continue
+		case "Charge":
+			charges = append(charges, stack)
+			continue
+		}
+		r, err := slots.rack(item)
+		if err != nil {
+			return nil, err
+		}
+		if r == -1 {
+			fit.Cargo = append(fit.Cargo, stack)
+			continue
+		}
+		for i := 0; i < entry.quantity; i++ {
+			slot, ok := slots.place(r)
+			if !ok {
+				return nil, fmt.Errorf("Too many %s slot modules in DNA", strings.ToLower(racks[r].name))
+			}
+			fit.Modules = append(fit.Modules, evego.FittedModule{Item: item, Slot: slot})
+		}
+	}
+	if err := loadCharges(fit, charges, database); err != nil {
+		return nil, err
+	}
+	return fit, nil
+}
+
+// loadCharges loads charges into the fitting's modules that use them, and
+// puts the rest into the cargo hold.
+func loadCharges(fit *evego.Fitting, charges []evego.InventoryLine, database evego.Database) error {
+	if len(charges) == 0 {
+		return nil
+	}
+	moduleIDs := make([]int, len(fit.Modules))
+	for i, m := range fit.Modules {
+		moduleIDs[i] = m.Item.ID
+	}
+	attrs, err := database.ItemsAttributes(moduleIDs)
+	if err != nil {
+		return err
+	}
+	// The charge groups that each module type can use.
+	chargeGroups := make(map[int]map[int]bool)
+	for typeID, typeAttrs := range attrs {
+		for _, a := range typeAttrs {
+			for _, id := range chargeGroupAttributes {
+				if a.AttributeID == id {
+					if chargeGroups[typeID] == nil {
+						chargeGroups[typeID] = make(map[int]bool)
+					}
+					chargeGroups[typeID][int(a.Value)] = true
+				}
+			}
+		}
+	}
+	for _, c := range charges {
+		for i := range fit.Modules {
+			m := &fit.Modules[i]
+			if c.Quantity == 0 {
+				break
+			}
+			if m.Charge == nil && chargeGroups[m.Item.ID][c.Item.GroupID] {
+				m.Charge = c.Item
+				c.Quantity--
+			}
+		}
+		if c.Quantity > 0 {
+			fit.Cargo = append(fit.Cargo, c)
+		}
+	}
+	return nil
+}
+
+// dnaCounts holds the number of each type in a part of a DNA string, in the
+// order that they first appear.
+type dnaCounts struct {
+	ids    []int
+	counts map[int]int
+}
+
+func (d *dnaCounts) add(id, quantity int) {
+	if d.counts == nil {
+		d.counts = make(map[int]int)
+	}
+	if _, ok := d.counts[id]; !ok {
+		d.ids = append(d.ids, id)
+	}
+	d.counts[id] += quantity
+}
+
+func (d *dnaCounts) write(buf *bytes.Buffer, suffix string) {
+	for _, id := range d.ids {
+		fmt.Fprintf(buf, ":%d%s;%d", id, suffix, d.counts[id])
+	}
+}
+
+// DNA returns the ship DNA string for a fitting. Modules are listed in the
+// order subsystems, high, medium, low, and rig slots, followed by loaded
+// charges, drones, and cargo.
+func DNA(fit *evego.Fitting) string {
+	modules := make(modulesBySlot, len(fit.Modules))
+	copy(modules, fit.Modules)
+	sort.Sort(modules)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d", fit.Ship.ID)
+	var charges dnaCounts
+	for _, first := range dnaRacks {
+		var rackModules dnaCounts
+		for _, m := range modules {
+			if rackForSlot(m.Slot) == rackForSlot(first) {
+				rackModules.add(m.Item.ID, 1)
+				if m.Charge != nil {
+					charges.add(m.Charge.ID, 1)
+				}
+			}
+		}
+		rackModules.write(&buf, "")
+	}
+	charges.write(&buf, "")
+	var drones, cargo dnaCounts
+	for _, d := range fit.Drones {
+		drones.add(d.Item.ID, d.Quantity)
+	}
+	drones.write(&buf, "")
+	for _, c := range fit.Cargo {
+		cargo.add(c.Item.ID, c.Quantity)
+	}
+	cargo.write(&buf, dnaCargo)
+	buf.WriteString("::")
+	return buf.String()
+}
+
+// FittingLink returns an in-game link to a fitting, suitable for pasting
+// into chat or an EVE mail.
+func FittingLink(fit *evego.Fitting) string {
+	name := fit.Name
+	if name == "" {
+		name = fit.Ship.Name
+	}
+	return fmt.Sprintf("<url=fitting:%s>%s</url>", DNA(fit), name)
+}
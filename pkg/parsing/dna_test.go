@@ -0,0 +1,145 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// The DNA for testdata/test-fitting.eft.
+const testDNA = "626:7247;2:3831;1:380;1:11269;2:31360;1:222;2:2456;5:222_;200::"
+
+func TestDNAFitting(t *testing.T) {
+	Convey("Given a fitting", t, func() {
+		eft, err := ioutil.ReadFile("../../testdata/test-fitting.eft")
+		So(err, ShouldBeNil)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		fit, err := parsing.ParseEFT(string(eft), db)
+		So(err, ShouldBeNil)
+
+		Convey("Its DNA is correct.", func() {
+			So(parsing.DNA(fit), ShouldEqual, testDNA)
+		})
+
+		Convey("Its fitting link is correct.", func() {
+			So(parsing.FittingLink(fit), ShouldEqual, "<url=fitting:"+testDNA+">Test Vexor</url>")
+		})
+	})
+
+	Convey("Given a DNA string", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		fit, err := parsing.ParseDNA(testDNA, db)
+		So(err, ShouldBeNil)
+
+		Convey("The hull is parsed and names the fitting.", func() {
+			So(fit.Ship.Name, ShouldEqual, "Vexor")
+			So(fit.Name, ShouldEqual, "Vexor")
+		})
+
+		Convey("Modules fill their racks, and charges are loaded.", func() {
+			type slot struct {
+				name   string
+				slot   evego.InventoryFlag
+				charge string
+			}
+			expected := []slot{
+				{"150mm Prototype Gauss Gun", evego.InvHiSlot0, "Antimatter Charge M"},
+				{"150mm Prototype Gauss Gun", evego.InvHiSlot1, "Antimatter Charge M"},
+				{"Medium Shield Extender II", evego.InvMedSlot0, ""},
+				{"Small Supplemental Barrier Emitter I", evego.InvMedSlot1, ""},
+				{"Limited Kinetic Plating I", evego.InvLoSlot0, ""},
+				{"Limited Kinetic Plating I", evego.InvLoSlot1, ""},
+				{"Medium Hybrid Burst Aerator I", evego.InvRigSlot0, ""},
+			}
+			So(fit.Modules, ShouldHaveLength, len(expected))
+			for i, m := range fit.Modules {
+				So(m.Item.Name, ShouldEqual, expected[i].name)
+				So(m.Slot, ShouldEqual, expected[i].slot)
+				So(m.Offline, ShouldBeFalse)
+				if expected[i].charge == "" {
+					So(m.Charge, ShouldBeNil)
+				} else {
+					So(m.Charge.Name, ShouldEqual, expected[i].charge)
+				}
+			}
+		})
+
+		Convey("Drones and cargo are separated.", func() {
+			So(fit.Drones, ShouldHaveLength, 1)
+			So(fit.Drones[0].Item.Name, ShouldEqual, "Hobgoblin II")
+			So(fit.Drones[0].Quantity, ShouldEqual, 5)
+			So(fit.Cargo, ShouldHaveLength, 1)
+			So(fit.Cargo[0].Item.Name, ShouldEqual, "Antimatter Charge M")
+			So(fit.Cargo[0].Quantity, ShouldEqual, 200)
+		})
+
+		Convey("Encoding it again produces the same DNA.", func() {
+			So(parsing.DNA(fit), ShouldEqual, testDNA)
+		})
+	})
+
+	Convey("Given a fitting link", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("The fitting's name is taken from the link.", func() {
+			fit, err := parsing.ParseDNA("<url=fitting:626:11269;1::>My Vexor</url>", db)
+			So(err, ShouldBeNil)
+			So(fit.Name, ShouldEqual, "My Vexor")
+			So(fit.Modules, ShouldHaveLength, 1)
+			So(parsing.FittingLink(fit), ShouldEqual, "<url=fitting:626:11269;1::>My Vexor</url>")
+		})
+	})
+
+	Convey("Given bad DNA", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("Unknown type IDs are reported.", func() {
+			_, err := parsing.ParseDNA("626:99999999;1:11269;1:88888888;2::", db)
+			var unknown *parsing.UnknownTypesError
+			So(errors.As(err, &unknown), ShouldBeTrue)
+			So(unknown.IDs, ShouldResemble, []int{88888888, 99999999})
+		})
+
+		Convey("Malformed entries are rejected.", func() {
+			_, err := parsing.ParseDNA("", db)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseDNA("626:fred;1::", db)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseDNA("626:11269;0::", db)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
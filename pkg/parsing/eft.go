@@ -0,0 +1,224 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+var (
+	// Matches the first line of a fitting, e.g. "[Vexor, Armor Vexor]".
+	eftHeader = regexp.MustCompile(`^\[\s*([^,\]]+?)\s*(?:,\s*(.*?)\s*)?\]$`)
+	// Matches an empty slot, e.g. "[Empty Low slot]".
+	eftEmptySlot = regexp.MustCompile(`(?i)^\[\s*empty\s+(\w+)\s+slot\s*\]$`)
+	// Matches a stack of drones or cargo, e.g. "Hobgoblin II x5".
+	eftStack = regexp.MustCompile(`^(.*?)\s+x(\d+)$`)
+)
+
+const eftOffline = "/offline"
+
+// eftParser holds the state of a fitting being parsed.
+type eftParser struct {
+	db    evego.Database
+	fit   *evego.Fitting
+	slots *slotter
+}
+
+// item looks up an item by name, allowing for typos and differences in case.
+func (p *eftParser) item(name string, lineNum int) (*evego.Item, error) {
+	item, err := p.db.ItemForName(name)
+	if err == nil {
+		return item, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+	item = closestItem(name, p.db)
+	if item == nil {
+		return nil, fmt.Errorf("Unknown item %q on line %d", name, lineNum)
+	}
+	return item, nil
+}
+
+// parseLine adds one line of a fitting, other than the header, to the
+// fitting.
+func (p *eftParser) parseLine(line string, lineNum int) error {
+	if matches := eftEmptySlot.FindStringSubmatch(line); matches != nil {
+		for i, r := range racks {
+			if strings.EqualFold(matches[1], r.name) {
+				p.slots.skip(i)
+				return nil
+			}
+		}
+		return fmt.Errorf("Unknown kind of slot %q on line %d", matches[1], lineNum)
+	}
+
+	if matches := eftStack.FindStringSubmatch(line); matches != nil {
+		item, err := p.item(matches[1], lineNum)
+		if err != nil {
+			return err
+		}
+		quantity, _ := strconv.Atoi(matches[2])
+		stack := evego.InventoryLine{Item: item, Quantity: quantity}
+		if item.Category == "Drone" || item.Category == "Fighter" {
+			p.fit.Drones = append(p.fit.Drones, stack)
+		} else {
+			p.fit.Cargo = append(p.fit.Cargo, stack)
+		}
+		return nil
+	}
+
+	// Anything else is a module, possibly with a charge and/or offline.
+	offline := false
+	if strings.HasSuffix(strings.ToLower(line), eftOffline) {
+		offline = true
+		line = strings.TrimSpace(line[:len(line)-len(eftOffline)])
+	}
+	moduleName, chargeName := line, ""
+	if comma := strings.Index(line, ","); comma != -1 {
+		moduleName = strings.TrimSpace(line[:comma])
+		chargeName = strings.TrimSpace(line[comma+1:])
+	}
+	item, err := p.item(moduleName, lineNum)
+	if err != nil {
+		return err
+	}
+	r, err := p.slots.rack(item)
+	if err != nil {
+		return err
+	}
+	if r == -1 {
+		// Not a module; EFT also lists things like implants on their own.
+		p.fit.Cargo = append(p.fit.Cargo, evego.InventoryLine{Item: item, Quantity: 1})
+		return nil
+	}
+	slot, ok := p.slots.place(r)
+	if !ok {
+		return fmt.Errorf("Too many %s slot modules on line %d", strings.ToLower(racks[r].name), lineNum)
+	}
+	module := evego.FittedModule{Item: item, Slot: slot, Offline: offline}
+	if chargeName != "" {
+		module.Charge, err = p.item(chargeName, lineNum)
+		if err != nil {
+			return err
+		}
+	}
+	p.fit.Modules = append(p.fit.Modules, module)
+	return nil
+}
+
+// ParseEFT parses a ship fitting in the format used by EFT and the EVE
+// client's fitting window. Modules are assigned to slots in the order in which
+// they're listed; empty slot markers such as "[Empty Low slot]" leave a gap.
+func ParseEFT(text string, database evego.Database) (*evego.Fitting, error) {
+	p := &eftParser{
+		db:    database,
+		fit:   &evego.Fitting{},
+		slots: newSlotter(database),
+	}
+	sawHeader := false
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !sawHeader {
+			matches := eftHeader.FindStringSubmatch(line)
+			if matches == nil {
+				return nil, fmt.Errorf("Expected [ship, fitting name] on line %d", lineNum)
+			}
+			ship, err := p.item(matches[1], lineNum)
+			if err != nil {
+				return nil, err
+			}
+			p.fit.Ship = ship
+			p.fit.Name = matches[2]
+			sawHeader = true
+			continue
+		}
+		if err := p.parseLine(line, lineNum); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !sawHeader {
+		return nil, fmt.Errorf("No fitting found")
+	}
+	return p.fit, nil
+}
+
+// WriteEFT writes a fitting in EFT format. Racks without any modules are left
+// out, and empty slots are only marked where they come before a fitted module.
+func WriteEFT(w io.Writer, fit *evego.Fitting) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "[%s, %s]\n", fit.Ship.Name, fit.Name)
+
+	byRack := make([][]evego.FittedModule, len(racks))
+	for _, m := range fit.Modules {
+		r := rackForSlot(m.Slot)
+		if r == -1 {
+			return fmt.Errorf("Module %v is in slot %v, which isn't in a rack", m.Item.Name, m.Slot)
+		}
+		byRack[r] = append(byRack[r], m)
+	}
+	for r, modules := range byRack {
+		if len(modules) == 0 {
+			continue
+		}
+		sort.Sort(modulesBySlot(modules))
+		fmt.Fprintln(bw)
+		next := racks[r].first
+		for _, m := range modules {
+			for ; next < m.Slot; next++ {
+				fmt.Fprintf(bw, "[Empty %s slot]\n", racks[r].name)
+			}
+			next = m.Slot + 1
+			bw.WriteString(m.Item.Name)
+			if m.Charge != nil {
+				fmt.Fprintf(bw, ", %s", m.Charge.Name)
+			}
+			if m.Offline {
+				bw.WriteString(" " + eftOffline)
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+
+	for _, stacks := range [][]evego.InventoryLine{fit.Drones, fit.Cargo} {
+		if len(stacks) == 0 {
+			continue
+		}
+		fmt.Fprintln(bw)
+		for _, s := range stacks {
+			fmt.Fprintf(bw, "%s x%d\n", s.Item.Name, s.Quantity)
+		}
+	}
+	return bw.Flush()
+}
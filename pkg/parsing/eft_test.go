@@ -0,0 +1,149 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEFTFitting(t *testing.T) {
+	Convey("Given a fitting in EFT format", t, func() {
+		eft, err := ioutil.ReadFile("../../testdata/test-fitting.eft")
+		So(err, ShouldBeNil)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		fit, err := parsing.ParseEFT(string(eft), db)
+		So(err, ShouldBeNil)
+
+		Convey("The hull and name are parsed.", func() {
+			So(fit.Ship.Name, ShouldEqual, "Vexor")
+			So(fit.Name, ShouldEqual, "Test Vexor")
+		})
+
+		Convey("Modules are placed in their slots.", func() {
+			type slot struct {
+				name    string
+				slot    evego.InventoryFlag
+				charge  string
+				offline bool
+			}
+			expected := []slot{
+				{"Limited Kinetic Plating I", evego.InvLoSlot0, "", false},
+				{"Limited Kinetic Plating I", evego.InvLoSlot2, "", true},
+				{"Medium Shield Extender II", evego.InvMedSlot0, "", false},
+				{"Small Supplemental Barrier Emitter I", evego.InvMedSlot1, "", false},
+				{"150mm Prototype Gauss Gun", evego.InvHiSlot0, "Antimatter Charge M", false},
+				{"150mm Prototype Gauss Gun", evego.InvHiSlot2, "Antimatter Charge M", false},
+				{"Medium Hybrid Burst Aerator I", evego.InvRigSlot0, "", false},
+			}
+			So(fit.Modules, ShouldHaveLength, len(expected))
+			for i, m := range fit.Modules {
+				So(m.Item.Name, ShouldEqual, expected[i].name)
+				So(m.Slot, ShouldEqual, expected[i].slot)
+				So(m.Offline, ShouldEqual, expected[i].offline)
+				if expected[i].charge == "" {
+					So(m.Charge, ShouldBeNil)
+				} else {
+					So(m.Charge.Name, ShouldEqual, expected[i].charge)
+				}
+			}
+		})
+
+		Convey("Drones and cargo are separated.", func() {
+			So(fit.Drones, ShouldHaveLength, 1)
+			So(fit.Drones[0].Item.Name, ShouldEqual, "Hobgoblin II")
+			So(fit.Drones[0].Quantity, ShouldEqual, 5)
+			So(fit.Cargo, ShouldHaveLength, 1)
+			So(fit.Cargo[0].Item.Name, ShouldEqual, "Antimatter Charge M")
+			So(fit.Cargo[0].Quantity, ShouldEqual, 200)
+		})
+
+		Convey("Writing it out again produces the same text.", func() {
+			var buf bytes.Buffer
+			err := parsing.WriteEFT(&buf, fit)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, string(eft))
+		})
+	})
+
+	Convey("Given malformed fittings", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("A fitting without a header is rejected.", func() {
+			_, err := parsing.ParseEFT("Limited Kinetic Plating I\n", db)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseEFT("", db)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Unknown items are reported with their line number.", func() {
+			_, err := parsing.ParseEFT("[Vexor, Bad]\n\nFlux Capacitor II\n", db)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 3")
+		})
+
+		Convey("Misspelled items are found anyway.", func() {
+			fit, err := parsing.ParseEFT("[vexor, Typo]\nlimited kinetic plating I\n", db)
+			So(err, ShouldBeNil)
+			So(fit.Ship.Name, ShouldEqual, "Vexor")
+			So(fit.Modules, ShouldHaveLength, 1)
+			So(fit.Modules[0].Slot, ShouldEqual, evego.InvLoSlot0)
+		})
+
+		Convey("Misspellings are found behind better-ranked matches.", func() {
+			decoy, err := db.ItemForName("Tritanium")
+			So(err, ShouldBeNil)
+			fit, err := parsing.ParseEFT("[Vexor, Typo]\nLimited Kinetic Platin I\n",
+				decoyDb{db, decoy})
+			So(err, ShouldBeNil)
+			So(fit.Modules, ShouldHaveLength, 1)
+			So(fit.Modules[0].Item.Name, ShouldEqual, "Limited Kinetic Plating I")
+		})
+	})
+}
+
+// decoyDb ranks a match that isn't a misspelling ahead of every search's
+// results, as a prefix or substring match would be.
+type decoyDb struct {
+	evego.Database
+	decoy *evego.Item
+}
+
+func (db decoyDb) SearchItems(query string, limit int) ([]evego.ItemMatch, error) {
+	matches, err := db.Database.SearchItems(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	matches = append([]evego.ItemMatch{{Item: db.decoy, Kind: evego.SubstringMatch, Distance: -1}}, matches...)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UnknownTypesError is returned when a DNA string or killmail contains type
+// IDs that aren't in the database.
+type UnknownTypesError struct {
+	IDs []int
+}
+
+func (e *UnknownTypesError) Error() string {
+	ids := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		ids[i] = strconv.Itoa(id)
+	}
+	return "Unknown type IDs: " + strings.Join(ids, ", ")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"github.com/backerman/evego"
+)
+
+// A rack is a set of slots of one kind (low, medium, etc.)
+type rack struct {
+	name   string // as written in EFT's empty slot markers
+	effect int    // the dogma effect that marks a module as fitting here
+	first  evego.InventoryFlag
+}
+
+// racks are listed in the order in which EFT writes them.
+var racks = []rack{
+	{"Low", evego.EffectLoPower, evego.InvLoSlot0},
+	{"Med", evego.EffectMedPower, evego.InvMedSlot0},
+	{"High", evego.EffectHiPower, evego.InvHiSlot0},
+	{"Rig", evego.EffectRigSlot, evego.InvRigSlot0},
+	{"Subsystem", evego.EffectSubSystem, evego.InvSubSystem0},
+}
+
+// Every rack has at most this many slots.
+const rackSize = 8
+
+// rackForSlot returns the index in racks of the rack that contains a slot,
+// or -1 if the slot isn't in any of them.
+func rackForSlot(slot evego.InventoryFlag) int {
+	for i, r := range racks {
+		if slot >= r.first && slot < r.first+rackSize {
+			return i
+		}
+	}
+	return -1
+}
+
+// slotter assigns modules to the slots of a ship being fitted.
+type slotter struct {
+	db    evego.Database
+	next  [5]int      // the next free slot in each of racks
+	racks map[int]int // rack of each module type seen, or -1 if not a module
+}
+
+func newSlotter(db evego.Database) *slotter {
+	return &slotter{db: db, racks: make(map[int]int)}
+}
+
+// rack returns the index in racks of the rack that an item is fitted to, or
+// -1 if the item isn't a module.
+func (s *slotter) rack(item *evego.Item) (int, error) {
+	if r, ok := s.racks[item.ID]; ok {
+		return r, nil
+	}
+	effects, err := s.db.ItemEffects(item.ID)
+	if err != nil {
+		return -1, err
+	}
+	r := -1
+	for _, effect := range effects {
+		for i := range racks {
+			if effect.EffectID == racks[i].effect {
+				r = i
+			}
+		}
+	}
+	s.racks[item.ID] = r
+	return r, nil
+}
+
+// place returns the next free slot in a rack, or false if the rack is full.
+func (s *slotter) place(r int) (evego.InventoryFlag, bool) {
+	if s.next[r] >= rackSize {
+		return 0, false
+	}
+	slot := racks[r].first + evego.InventoryFlag(s.next[r])
+	s.next[r]++
+	return slot, true
+}
+
+// skip leaves the next slot in a rack empty.
+func (s *slotter) skip(r int) {
+	s.next[r]++
+}
+
+// Implementation of sort.Interface
+
+type modulesBySlot []evego.FittedModule
+
+func (m modulesBySlot) Len() int {
+	return len(m)
+}
+
+func (m modulesBySlot) Less(i, j int) bool {
+	return m[i].Slot < m[j].Slot
+}
+
+func (m modulesBySlot) Swap(i, j int) {
+	m[i], m[j] = m[j], m[i]
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/backerman/evego"
+)
+
+// killmailTypes returns the type IDs of a list of killmail items and their
+// contents.
+func killmailTypes(items []evego.KillmailItem) []int {
+	var ids []int
+	for _, item := range items {
+		ids = append(ids, item.TypeID)
+		ids = append(ids, killmailTypes(item.Items)...)
+	}
+	return ids
+}
+
+// setKillmailItems fills in the types of a list of killmail items and their
+// contents.
+func setKillmailItems(items []evego.KillmailItem, types map[int]*evego.Item) {
+	for i := range items {
+		items[i].Item = types[items[i].TypeID]
+		setKillmailItems(items[i].Items, types)
+	}
+}
+
+// ParseKillmail reads a killmail in the JSON format returned by ESI, and
+// looks up the types of the ships, weapons, and items on it.
+//
+// If the killmail refers to type IDs that aren't in the database, the error
+// returned is an *UnknownTypesError listing them.
+func ParseKillmail(data []byte, database evego.Database) (*evego.Killmail, error) {
+	km := &evego.Killmail{}
+	if err := json.Unmarshal(data, km); err != nil {
+		return nil, fmt.Errorf("Unable to parse killmail: %v", err)
+	}
+	if km.Victim.ShipTypeID == 0 {
+		return nil, fmt.Errorf("Killmail has no victim ship")
+	}
+	ids := []int{km.Victim.ShipTypeID}
+	for _, a := range km.Attackers {
+		// NPCs and structures may not have a ship or weapon.
+		if a.ShipTypeID != 0 {
+			ids = append(ids, a.ShipTypeID)
+		}
+		if a.WeaponTypeID != 0 {
+			ids = append(ids, a.WeaponTypeID)
+		}
+	}
+	ids = append(ids, killmailTypes(km.Victim.Items)...)
+
+	found, err := database.ItemsForIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[int]*evego.Item, len(found))
+	for _, item := range found {
+		types[item.ID] = item
+	}
+	var unknown []int
+	reported := make(map[int]bool)
+	for _, id := range ids {
+		if types[id] == nil && !reported[id] {
+			unknown = append(unknown, id)
+			reported[id] = true
+		}
+	}
+	if unknown != nil {
+		sort.Ints(unknown)
+		return nil, &UnknownTypesError{IDs: unknown}
+	}
+
+	km.Victim.Ship = types[km.Victim.ShipTypeID]
+	for i := range km.Attackers {
+		a := &km.Attackers[i]
+		a.Ship, a.Weapon = types[a.ShipTypeID], types[a.WeaponTypeID]
+	}
+	setKillmailItems(km.Victim.Items, types)
+	return km, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestKillmail(t *testing.T) {
+	Convey("Given an ESI killmail", t, func() {
+		data, err := ioutil.ReadFile("../../testdata/test-killmail.json")
+		So(err, ShouldBeNil)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		km, err := parsing.ParseKillmail(data, db)
+		So(err, ShouldBeNil)
+
+		Convey("The kill and its victim are read.", func() {
+			So(km.ID, ShouldEqual, 56789012)
+			So(km.Time, ShouldResemble, time.Date(2016, time.October, 22, 17, 13, 36, 0, time.UTC))
+			So(km.SolarSystemID, ShouldEqual, 30002659)
+			So(km.Victim.CharacterID, ShouldEqual, 94319654)
+			So(km.Victim.Ship.Name, ShouldEqual, "Vexor")
+			So(km.Victim.DamageTaken, ShouldEqual, 4000)
+		})
+
+		Convey("Attackers' ships and weapons are looked up.", func() {
+			So(km.Attackers, ShouldHaveLength, 2)
+			So(km.Attackers[0].FinalBlow, ShouldBeTrue)
+			So(km.Attackers[0].Ship.Name, ShouldEqual, "Ishtar")
+			So(km.Attackers[0].Weapon.Name, ShouldEqual, "Hobgoblin II")
+			So(km.Attackers[1].Ship, ShouldBeNil)
+			So(km.Attackers[1].Weapon, ShouldBeNil)
+		})
+
+		Convey("Items are grouped by their location.", func() {
+			items := km.Victim.ItemsByFlag()
+			So(items[evego.InvHiSlot0], ShouldHaveLength, 2)
+			So(items[evego.InvHiSlot0][0].Item.Name, ShouldEqual, "150mm Prototype Gauss Gun")
+			So(items[evego.InvHiSlot0][0].QuantityDropped, ShouldEqual, 1)
+			So(items[evego.InvHiSlot0][1].Item.Name, ShouldEqual, "Antimatter Charge M")
+			So(items[evego.InvHiSlot0][1].QuantityDestroyed, ShouldEqual, 1)
+			drones := items[evego.InvDroneBay]
+			So(drones, ShouldHaveLength, 1)
+			So(drones[0].QuantityDestroyed, ShouldEqual, 3)
+			So(drones[0].QuantityDropped, ShouldEqual, 2)
+		})
+
+		Convey("The contents of containers are read.", func() {
+			cargo := km.Victim.ItemsByFlag()[evego.InvCargo]
+			So(cargo, ShouldHaveLength, 1)
+			So(cargo[0].Item.Name, ShouldEqual, "Station Container")
+			contents := cargo[0].Items
+			So(contents, ShouldHaveLength, 2)
+			So(contents[0].Item.Name, ShouldEqual, "Tritanium")
+			So(contents[0].BlueprintCopy(), ShouldBeFalse)
+			So(contents[1].Item.Name, ShouldEqual, "Vexor Blueprint")
+			So(contents[1].BlueprintCopy(), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given bad killmails", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("Unknown type IDs are reported.", func() {
+			_, err := parsing.ParseKillmail([]byte(`{"victim": {"ship_type_id": 626, "items": [
+				{"flag": 5, "item_type_id": 99999999, "quantity_dropped": 1},
+				{"flag": 5, "item_type_id": 17366, "quantity_dropped": 1,
+				 "items": [{"item_type_id": 88888888, "quantity_dropped": 1}]}]}}`), db)
+			var unknown *parsing.UnknownTypesError
+			So(errors.As(err, &unknown), ShouldBeTrue)
+			So(unknown.IDs, ShouldResemble, []int{88888888, 99999999})
+		})
+
+		Convey("Malformed killmails are rejected.", func() {
+			_, err := parsing.ParseKillmail([]byte(`{"victim": `), db)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseKillmail([]byte(`{"killmail_id": 1}`), db)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+// ParseLocal resolves the member list of a chat channel, such as Local,
+// copied from the EVE client. Each line has one pilot's name; blank lines are
+// skipped. Pilots are returned in the order listed, with their corporations
+// and alliances, and names that aren't those of characters are omitted.
+func ParseLocal(pasted string, resolver evego.CharacterResolver) ([]evego.Character, error) {
+	return ParseLocalContext(context.Background(), pasted, resolver)
+}
+
+// ParseLocalContext is ParseLocal with a context that cancels the lookups.
+func ParseLocalContext(ctx context.Context, pasted string, resolver evego.CharacterResolver) ([]evego.Character, error) {
+	var names []string
+	for _, line := range strings.Split(pasted, "\n") {
+		name := strings.TrimSpace(line)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return []evego.Character{}, nil
+	}
+	return resolver.CharactersForNamesContext(ctx, names)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// nameResolver resolves every name to a character in the same corporation,
+// recording the names it was asked for.
+type nameResolver struct {
+	asked []string
+}
+
+func (r *nameResolver) CharactersForNames(names []string) ([]evego.Character, error) {
+	return r.CharactersForNamesContext(context.Background(), names)
+}
+
+func (r *nameResolver) CharactersForNamesContext(ctx context.Context, names []string) ([]evego.Character, error) {
+	r.asked = append(r.asked, names...)
+	chars := make([]evego.Character, len(names))
+	for i, name := range names {
+		chars[i] = evego.Character{Name: name, ID: i + 1, Corporation: "Test Corp"}
+	}
+	return chars, nil
+}
+
+func (r *nameResolver) Close() error {
+	return nil
+}
+
+func TestLocalChat(t *testing.T) {
+	Convey("Given a pasted member list", t, func() {
+		pasted := "Arjun Kansene\r\n  All reps on Cain \n\nPilot 3\n"
+		resolver := &nameResolver{}
+
+		Convey("Each pilot's name is resolved.", func() {
+			chars, err := parsing.ParseLocal(pasted, resolver)
+			So(err, ShouldBeNil)
+			So(resolver.asked, ShouldResemble, []string{"Arjun Kansene", "All reps on Cain", "Pilot 3"})
+			So(chars, ShouldHaveLength, 3)
+			So(chars[1].Name, ShouldEqual, "All reps on Cain")
+		})
+	})
+
+	Convey("Given an empty member list", t, func() {
+		resolver := &nameResolver{}
+
+		Convey("Nothing is looked up.", func() {
+			chars, err := parsing.ParseLocal(" \n", resolver)
+			So(err, ShouldBeNil)
+			So(chars, ShouldBeEmpty)
+			So(resolver.asked, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/backerman/evego"
+)
+
+// The format of the issue dates in market exports.
+const marketLogTime = "2006-01-02 15:04:05.000"
+
+// The columns that must be present in a market export.
+var marketLogColumns = []string{
+	"price", "volRemaining", "typeID", "range", "minVolume", "bid",
+	"issueDate", "duration", "stationID", "regionID", "solarSystemID",
+}
+
+// marketLogReader holds the state of a market export being read.
+type marketLogReader struct {
+	db       evego.Database
+	columns  map[string]int
+	items    map[int]*evego.Item
+	stations map[int]*evego.Station
+}
+
+func (m *marketLogReader) field(row []string, name string) string {
+	i := m.columns[name]
+	if i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func (m *marketLogReader) intField(row []string, name string) (int, error) {
+	// Quantities are exported as floats, e.g. "1000.0".
+	f, err := strconv.ParseFloat(m.field(row, name), 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s %q in market export", name, m.field(row, name))
+	}
+	return int(f), nil
+}
+
+func (m *marketLogReader) item(typeID int) (*evego.Item, error) {
+	if item, ok := m.items[typeID]; ok {
+		return item, nil
+	}
+	item, err := m.db.ItemForID(typeID)
+	if err != nil {
+		return nil, err
+	}
+	m.items[typeID] = item
+	return item, nil
+}
+
+// station returns the station with the given ID. Stations that aren't in the
+// static database are outposts; for these, we know only the location.
+func (m *marketLogReader) station(stationID, systemID, regionID int) (*evego.Station, error) {
+	if sta, ok := m.stations[stationID]; ok {
+		return sta, nil
+	}
+	sta, err := m.db.StationForID(stationID)
+	if err == sql.ErrNoRows {
+		sta, err = &evego.Station{
+			Name:     fmt.Sprintf("Unknown Station (ID %d)", stationID),
+			ID:       stationID,
+			SystemID: systemID,
+			RegionID: regionID,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.stations[stationID] = sta
+	return sta, nil
+}
+
+// order converts one row of a market export to an order.
+func (m *marketLogReader) order(row []string) (evego.Order, error) {
+	var (
+		o    evego.Order
+		err  error
+		ints = make(map[string]int)
+	)
+	for _, name := range []string{"volRemaining", "typeID", "range", "minVolume",
+		"duration", "stationID", "regionID", "solarSystemID"} {
+		if ints[name], err = m.intField(row, name); err != nil {
+			return o, err
+		}
+	}
+	o.Price, err = strconv.ParseFloat(m.field(row, "price"), 64)
+	if err != nil {
+		return o, fmt.Errorf("Invalid price %q in market export", m.field(row, "price"))
+	}
+	issued, err := time.Parse(marketLogTime, m.field(row, "issueDate"))
+	if err != nil {
+		return o, fmt.Errorf("Invalid issue date %q in market export", m.field(row, "issueDate"))
+	}
+	o.Expiration = issued.AddDate(0, 0, ints["duration"])
+	o.Quantity = ints["volRemaining"]
+	if o.Item, err = m.item(ints["typeID"]); err != nil {
+		return o, err
+	}
+	o.Station, err = m.station(ints["stationID"], ints["solarSystemID"], ints["regionID"])
+	if err != nil {
+		return o, err
+	}
+	o.Type = evego.Sell
+	if strings.EqualFold(m.field(row, "bid"), "True") {
+		// Set the fields specific to buy orders.
+		o.Type = evego.Buy
+		o.MinQuantity = ints["minVolume"]
+		switch r := ints["range"]; r {
+		case 32767, 65535:
+			o.JumpRange = evego.BuyRegion
+		case -1:
+			o.JumpRange = evego.BuyStation
+		case 0:
+			o.JumpRange = evego.BuySystem
+		default:
+			o.JumpRange = evego.BuyNumberJumps
+			o.NumJumps = r
+		}
+	}
+	return o, nil
+}
+
+// ParseMarketLog reads a market export saved by the EVE client (in the
+// Documents/EVE/logs/Marketlogs directory) and returns its orders, in the
+// order that they were exported. Stations that aren't in the database, such
+// as outposts, are given a placeholder name.
+func ParseMarketLog(r io.Reader, database evego.Database) ([]evego.Order, error) {
+	reader := csv.NewReader(r)
+	// Each line, including the header, ends with a comma.
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("Market export is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read market export: %v", err)
+	}
+	m := &marketLogReader{
+		db:       database,
+		columns:  make(map[string]int),
+		items:    make(map[int]*evego.Item),
+		stations: make(map[int]*evego.Station),
+	}
+	for i, name := range header {
+		m.columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range marketLogColumns {
+		if _, ok := m.columns[name]; !ok {
+			return nil, fmt.Errorf("Market export has no %s column", name)
+		}
+	}
+	orders := []evego.Order{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read market export: %v", err)
+		}
+		o, err := m.order(row)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
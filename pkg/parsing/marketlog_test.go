@@ -0,0 +1,92 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testMarketLog = "../../testdata/marketlogs/Sinq Laison-Tritanium-2015.02.17 032750.txt"
+
+func TestMarketLog(t *testing.T) {
+	Convey("Given a market export", t, func() {
+		f, err := os.Open(testMarketLog)
+		So(err, ShouldBeNil)
+		defer f.Close()
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		orders, err := parsing.ParseMarketLog(f, db)
+		So(err, ShouldBeNil)
+		So(orders, ShouldHaveLength, 7)
+
+		Convey("Sell orders are read.", func() {
+			o := orders[0]
+			So(o.Type, ShouldEqual, evego.Sell)
+			So(o.Item.Name, ShouldEqual, "Tritanium")
+			So(o.Quantity, ShouldEqual, 1000)
+			So(o.Price, ShouldAlmostEqual, 5.5)
+			So(o.Station.Name, ShouldEqual, "Dodixie IX - Moon 20 - Federation Navy Assembly Plant")
+			So(o.Expiration, ShouldResemble, time.Date(2015, time.May, 11, 12, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Buy orders' ranges are read.", func() {
+			So(orders[3].Type, ShouldEqual, evego.Buy)
+			So(orders[3].JumpRange, ShouldEqual, evego.BuyRegion)
+			So(orders[4].JumpRange, ShouldEqual, evego.BuyStation)
+			So(orders[4].MinQuantity, ShouldEqual, 10)
+			So(orders[5].JumpRange, ShouldEqual, evego.BuyNumberJumps)
+			So(orders[5].NumJumps, ShouldEqual, 5)
+			So(orders[6].JumpRange, ShouldEqual, evego.BuySystem)
+		})
+
+		Convey("Stations not in the database are given their location.", func() {
+			sta := orders[2].Station
+			So(sta.Name, ShouldEqual, "Unknown Station (ID 61000001)")
+			So(sta.SystemID, ShouldEqual, 30002659)
+			So(sta.RegionID, ShouldEqual, 10000032)
+		})
+	})
+
+	Convey("Given malformed market exports", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("They are rejected.", func() {
+			_, err := parsing.ParseMarketLog(strings.NewReader(""), db)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseMarketLog(strings.NewReader("price,volRemaining,\n"), db)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseMarketLog(strings.NewReader(
+				"price,volRemaining,typeID,range,orderID,volEntered,minVolume,bid,issueDate,duration,stationID,regionID,solarSystemID,jumps,\n"+
+					"lots,1.0,34,32767,1,1,1,False,2015-02-10 12:00:00.000,90,60011866,10000032,30002659,0,\n"), db)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,277 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/backerman/evego"
+)
+
+// Matches a distance such as "1,234 km" or "12.3 AU".
+var scanDistance = regexp.MustCompile(`^([\d.,\s\x{a0}]+?)\s*(m|km|AU)$`)
+
+// The length of an astronomical unit in metres.
+const metresPerAU = 149597870700
+
+var distanceUnits = map[string]float64{
+	"m":  1,
+	"km": 1000,
+	"AU": metresPerAU,
+}
+
+// The probe scanner's scan groups for signatures that aren't objects with a
+// type.
+var siteScanGroups = map[string]bool{
+	"Cosmic Signature": true,
+	"Cosmic Anomaly":   true,
+}
+
+// parseDistance converts a distance shown by a scanner to metres. A distance
+// of "-" means that there isn't one, and is returned as -1.
+func parseDistance(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "-" || s == "" {
+		return -1, nil
+	}
+	matches := scanDistance.FindStringSubmatch(s)
+	if matches == nil {
+		return 0, fmt.Errorf("Invalid distance %q", s)
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r == '.' || r == ',' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, matches[1])
+	// Depending on the client's language, the decimal separator may be a point
+	// or a comma. The last separator is the decimal one unless it's followed by
+	// a group of three digits and there's no separator of the other kind
+	// before it, as in "1,234 km".
+	whole, fraction := digits, ""
+	if i := strings.LastIndexAny(digits, ".,"); i >= 0 {
+		other := ","
+		if digits[i] == ',' {
+			other = "."
+		}
+		if len(digits)-i-1 != 3 || strings.Contains(digits[:i], other) {
+			whole, fraction = digits[:i], digits[i+1:]
+		}
+	}
+	number := strings.NewReplacer(".", "", ",", "").Replace(whole)
+	if fraction != "" {
+		number += "." + fraction
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid distance %q", s)
+	}
+	return value * distanceUnits[matches[2]], nil
+}
+
+// scanLines splits pasted scanner output into lines of tab-separated fields,
+// skipping blank lines.
+func scanLines(pasted string) [][]string {
+	var lines [][]string
+	for _, line := range strings.Split(pasted, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, strings.Split(line, "\t"))
+	}
+	return lines
+}
+
+// typeNamer looks up items by their exact name, remembering the results.
+type typeNamer struct {
+	db    evego.Database
+	items map[string]*evego.Item
+}
+
+// item returns the item with the given name, or nil if there isn't one.
+func (t *typeNamer) item(name string) (*evego.Item, error) {
+	if item, ok := t.items[name]; ok {
+		return item, nil
+	}
+	item, err := t.db.ItemForName(name)
+	if err == sql.ErrNoRows {
+		item, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.items[name] = item
+	return item, nil
+}
+
+// ParseDScan extracts the results of a directional scan copied from the EVE
+// client. Lines can be in either the current format (type ID, name, type,
+// distance) or the older one without the type ID. Lines that are in neither
+// are skipped.
+func ParseDScan(pasted string, database evego.Database) ([]evego.DScanResult, error) {
+	results := []evego.DScanResult{}
+	// The type ID of each result, or 0 if the scanner didn't show it.
+	var typeIDs []int
+	for _, fields := range scanLines(pasted) {
+		typeID := 0
+		if len(fields) == 4 {
+			id, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+			if err != nil {
+				continue
+			}
+			typeID = id
+			fields = fields[1:]
+		}
+		if len(fields) != 3 {
+			continue
+		}
+		distance, err := parseDistance(fields[2])
+		if err != nil {
+			continue
+		}
+		results = append(results, evego.DScanResult{
+			Name:     strings.TrimSpace(fields[0]),
+			Type:     strings.TrimSpace(fields[1]),
+			Distance: distance,
+		})
+		typeIDs = append(typeIDs, typeID)
+	}
+
+	// Look up all of the type IDs at once.
+	var ids []int
+	for _, id := range typeIDs {
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	byID := make(map[int]*evego.Item)
+	if len(ids) > 0 {
+		items, err := database.ItemsForIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			byID[item.ID] = item
+		}
+	}
+	namer := &typeNamer{db: database, items: make(map[string]*evego.Item)}
+	for i := range results {
+		if typeIDs[i] != 0 {
+			results[i].Item = byID[typeIDs[i]]
+			continue
+		}
+		item, err := namer.item(results[i].Type)
+		if err != nil {
+			return nil, err
+		}
+		results[i].Item = item
+	}
+	return results, nil
+}
+
+// ParseProbeScan extracts the results of a probe scan copied from the EVE
+// client. Each line has the signature ID, scan group, group, name, signal
+// strength, and distance; lines that don't are skipped.
+func ParseProbeScan(pasted string, database evego.Database) ([]evego.ProbeScanResult, error) {
+	results := []evego.ProbeScanResult{}
+	namer := &typeNamer{db: database, items: make(map[string]*evego.Item)}
+	for _, fields := range scanLines(pasted) {
+		if len(fields) != 6 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		signal, err := strconv.ParseFloat(strings.TrimSuffix(fields[4], "%"), 64)
+		if err != nil {
+			continue
+		}
+		distance, err := parseDistance(fields[5])
+		if err != nil {
+			continue
+		}
+		result := evego.ProbeScanResult{
+			SignatureID: fields[0],
+			ScanGroup:   fields[1],
+			Group:       fields[2],
+			Name:        fields[3],
+			Signal:      signal,
+			Distance:    distance,
+		}
+		if result.Name != "" && !siteScanGroups[result.ScanGroup] {
+			result.Item, err = namer.item(result.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SummarizeDScan counts the objects in a directional scan by their group
+// (e.g. the ship's class) and category. Objects whose type isn't in the
+// database are counted by their type name, with an empty category.
+//
+// The counts are sorted by category, and within a category from the most
+// objects to the fewest.
+func SummarizeDScan(results []evego.DScanResult) []evego.DScanCount {
+	type key struct{ category, group string }
+	counts := make(map[key]int)
+	for _, r := range results {
+		k := key{group: r.Type}
+		if r.Item != nil {
+			k = key{r.Item.Category, r.Item.Group}
+		}
+		counts[k]++
+	}
+	summary := make(dscanCounts, 0, len(counts))
+	for k, n := range counts {
+		summary = append(summary, evego.DScanCount{Category: k.category, Group: k.group, Count: n})
+	}
+	sort.Sort(summary)
+	return summary
+}
+
+// Implementation of sort.Interface
+
+type dscanCounts []evego.DScanCount
+
+func (d dscanCounts) Len() int {
+	return len(d)
+}
+
+func (d dscanCounts) Less(i, j int) bool {
+	switch {
+	case d[i].Category != d[j].Category:
+		return d[i].Category < d[j].Category
+	case d[i].Count != d[j].Count:
+		return d[i].Count > d[j].Count
+	}
+	return d[i].Group < d[j].Group
+}
+
+func (d dscanCounts) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
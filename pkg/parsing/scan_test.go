@@ -0,0 +1,139 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDScan(t *testing.T) {
+	Convey("Given a pasted directional scan", t, func() {
+		dscan, err := ioutil.ReadFile("../../testdata/test-dscan.txt")
+		So(err, ShouldBeNil)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		results, err := parsing.ParseDScan(string(dscan), db)
+		So(err, ShouldBeNil)
+		So(results, ShouldHaveLength, 8)
+
+		Convey("Names, types, and distances are parsed.", func() {
+			So(results[0].Name, ShouldEqual, "Bob's Vexor")
+			So(results[0].Item.Name, ShouldEqual, "Vexor")
+			So(results[0].Item.Group, ShouldEqual, "Cruiser")
+			So(results[0].Distance, ShouldEqual, 1234000)
+			So(results[1].Distance, ShouldEqual, 12.5*149597870700)
+			So(results[2].Item.Name, ShouldEqual, "Ishtar")
+			So(results[2].Distance, ShouldEqual, 9876)
+			So(results[3].Distance, ShouldEqual, -1)
+		})
+
+		Convey("Unknown types are kept without an item.", func() {
+			So(results[7].Type, ShouldEqual, "Caldari Navy Assembly Plant")
+			So(results[7].Item, ShouldBeNil)
+		})
+
+		Convey("The summary groups results by category and class.", func() {
+			So(parsing.SummarizeDScan(results), ShouldResemble, []evego.DScanCount{
+				{Category: "", Group: "Caldari Navy Assembly Plant", Count: 1},
+				{Category: "Drone", Group: "Combat Drone", Count: 3},
+				{Category: "Ship", Group: "Cruiser", Count: 2},
+				{Category: "Ship", Group: "Frigate", Count: 1},
+				{Category: "Ship", Group: "Heavy Assault Cruiser", Count: 1},
+			})
+		})
+	})
+
+	Convey("Given a directional scan in the older format", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("Types are looked up by name, and bad lines are skipped.", func() {
+			results, err := parsing.ParseDScan("Bob's Vexor\tVexor\t1,234 km\nfred\nCorvette\tReaper\t12 parsecs\n", db)
+			So(err, ShouldBeNil)
+			So(results, ShouldHaveLength, 1)
+			So(results[0].Item.ID, ShouldEqual, 626)
+			So(results[0].Distance, ShouldEqual, 1234000)
+		})
+
+		Convey("Distances may use a decimal comma.", func() {
+			pasted := "Vexor\tVexor\t12,3 AU\n" +
+				"Vexor\tVexor\t1.234,5 km\n" +
+				"Vexor\tVexor\t1.234 km\n" +
+				"Vexor\tVexor\t1\u00a0234,56 km\n" +
+				"Vexor\tVexor\t1,234,567.8 km\n"
+			results, err := parsing.ParseDScan(pasted, db)
+			So(err, ShouldBeNil)
+			So(results, ShouldHaveLength, 5)
+			So(results[0].Distance, ShouldEqual, 12.3*149597870700)
+			So(results[1].Distance, ShouldEqual, 1234500)
+			So(results[2].Distance, ShouldEqual, 1234000)
+			So(results[3].Distance, ShouldEqual, 1234560)
+			So(results[4].Distance, ShouldEqual, 1234567800)
+		})
+	})
+}
+
+func TestProbeScan(t *testing.T) {
+	Convey("Given a pasted probe scan", t, func() {
+		scan, err := ioutil.ReadFile("../../testdata/test-probescan.txt")
+		So(err, ShouldBeNil)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		results, err := parsing.ParseProbeScan(string(scan), db)
+		So(err, ShouldBeNil)
+		So(results, ShouldHaveLength, 4)
+
+		Convey("Scanned-down signatures are fully parsed.", func() {
+			So(results[0], ShouldResemble, evego.ProbeScanResult{
+				SignatureID: "ABC-123",
+				ScanGroup:   "Cosmic Signature",
+				Group:       "Data Site",
+				Name:        "Central Guristas Survey Site",
+				Signal:      100,
+				Distance:    12.34 * 149597870700,
+			})
+		})
+
+		Convey("Weak signatures have no group or name.", func() {
+			So(results[1].SignatureID, ShouldEqual, "DEF-456")
+			So(results[1].Group, ShouldBeEmpty)
+			So(results[1].Name, ShouldBeEmpty)
+			So(results[1].Signal, ShouldEqual, 4.5)
+			So(results[1].Distance, ShouldEqual, 1234500)
+		})
+
+		Convey("Ships are matched to their types.", func() {
+			So(results[2].Item, ShouldBeNil)
+			So(results[3].Item, ShouldNotBeNil)
+			So(results[3].Item.Name, ShouldEqual, "Vexor")
+			So(results[3].Distance, ShouldEqual, 5000)
+		})
+	})
+}
@@ -0,0 +1,209 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/character"
+)
+
+// Matches a line of a plain text skill plan, e.g. "Gunnery V" or "Gunnery 5".
+var skillPlanLine = regexp.MustCompile(`^(.*?)\s+([IV]+|\d)$`)
+
+// skillLevel converts a level in either Roman or Arabic numerals to a number.
+func skillLevel(level string) (int, bool) {
+	if n, err := strconv.Atoi(level); err == nil {
+		return n, n >= 1 && n <= character.MaxSkillLevel
+	}
+	for n := 1; n <= character.MaxSkillLevel; n++ {
+		if level == evego.RomanNumeral(n) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// skill looks up a skill by ID or, if the ID is zero, by name.
+func skill(db evego.Database, id int, name string) (*evego.Item, error) {
+	var (
+		item *evego.Item
+		err  error
+	)
+	if id != 0 {
+		item, err = db.ItemForID(id)
+	} else {
+		item, err = db.ItemForName(name)
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("Unknown skill %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if item.Category != "Skill" {
+		return nil, fmt.Errorf("%v is not a skill", item.Name)
+	}
+	return item, nil
+}
+
+// completePlan inserts missing prerequisites into a plan and removes the
+// levels that the character has already trained. A nil sheet is a character
+// without any skills.
+func completePlan(plan evego.SkillPlan, db evego.Database, sheet *evego.CharacterSheet) (evego.SkillPlan, error) {
+	if sheet == nil {
+		sheet = &evego.CharacterSheet{}
+	}
+	trainer, err := character.NewTrainer(db, sheet)
+	if err != nil {
+		return nil, err
+	}
+	return trainer.CompletePlan(plan)
+}
+
+// ParseSkillPlan parses a plain text skill plan with one skill level per line,
+// e.g. "Gunnery V". Prerequisites that the plan leaves out are inserted before
+// the skills that need them, and levels that are already on the character
+// sheet are removed.
+func ParseSkillPlan(text string, db evego.Database, sheet *evego.CharacterSheet) (evego.SkillPlan, error) {
+	plan := evego.SkillPlan{}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		matches := skillPlanLine.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("Expected a skill and level on line %d", lineNum)
+		}
+		level, ok := skillLevel(matches[2])
+		if !ok {
+			return nil, fmt.Errorf("Invalid skill level %q on line %d", matches[2], lineNum)
+		}
+		sk, err := skill(db, 0, matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("%v on line %d", err, lineNum)
+		}
+		plan = append(plan, evego.PlannedSkill{Skill: sk, Level: level})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return completePlan(plan, db, sheet)
+}
+
+// WriteSkillPlan writes a skill plan as plain text, one skill level per line.
+func WriteSkillPlan(w io.Writer, plan evego.SkillPlan) error {
+	bw := bufio.NewWriter(w)
+	for _, p := range plan {
+		fmt.Fprintln(bw, p)
+	}
+	return bw.Flush()
+}
+
+// evemonPlan is the XML format of an EVEMon skill plan.
+type evemonPlan struct {
+	XMLName xml.Name      `xml:"plan"`
+	Name    string        `xml:"name,attr"`
+	Entries []evemonEntry `xml:"entry"`
+}
+
+type evemonEntry struct {
+	SkillID  int    `xml:"skillID,attr"`
+	Skill    string `xml:"skill,attr"`
+	Level    int    `xml:"level,attr"`
+	Priority int    `xml:"priority,attr"`
+	Type     string `xml:"type,attr"`
+}
+
+// ParseEVEMonPlan parses a skill plan exported by EVEMon, which may be
+// compressed (as .emp files are) or plain XML. It returns the plan's name and
+// its skills; as with ParseSkillPlan, missing prerequisites are inserted and
+// trained levels removed.
+func ParseEVEMonPlan(r io.Reader, db evego.Database, sheet *evego.CharacterSheet) (string, evego.SkillPlan, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, err
+		}
+		data, err = ioutil.ReadAll(gz)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	var emp evemonPlan
+	if err := xml.Unmarshal(data, &emp); err != nil {
+		return "", nil, err
+	}
+	plan := make(evego.SkillPlan, 0, len(emp.Entries))
+	for i, e := range emp.Entries {
+		if e.Level < 1 || e.Level > character.MaxSkillLevel {
+			return "", nil, fmt.Errorf("Invalid skill level %d in entry %d", e.Level, i+1)
+		}
+		sk, err := skill(db, e.SkillID, e.Skill)
+		if err != nil {
+			return "", nil, fmt.Errorf("%v in entry %d", err, i+1)
+		}
+		plan = append(plan, evego.PlannedSkill{Skill: sk, Level: e.Level})
+	}
+	plan, err = completePlan(plan, db, sheet)
+	if err != nil {
+		return "", nil, err
+	}
+	return emp.Name, plan, nil
+}
+
+// WriteEVEMonPlan writes a skill plan as uncompressed EVEMon XML, which
+// EVEMon can import.
+func WriteEVEMonPlan(w io.Writer, name string, plan evego.SkillPlan) error {
+	emp := evemonPlan{Name: name}
+	for _, p := range plan {
+		emp.Entries = append(emp.Entries, evemonEntry{
+			SkillID:  p.Skill.ID,
+			Skill:    p.Skill.Name,
+			Level:    p.Level,
+			Priority: 3,
+			Type:     "Planned",
+		})
+	}
+	out, err := xml.MarshalIndent(emp, "", "  ")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.Write(out)
+	fmt.Fprintln(bw)
+	return bw.Flush()
+}
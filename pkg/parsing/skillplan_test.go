@@ -0,0 +1,140 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package parsing_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/parsing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// planText returns a skill plan as written by WriteSkillPlan.
+func planText(plan evego.SkillPlan) string {
+	var buf bytes.Buffer
+	So(parsing.WriteSkillPlan(&buf, plan), ShouldBeNil)
+	return buf.String()
+}
+
+func TestSkillPlans(t *testing.T) {
+	Convey("Given skill plans", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+		text, err := ioutil.ReadFile("../../testdata/test-skillplan.txt")
+		So(err, ShouldBeNil)
+		emp, err := ioutil.ReadFile("../../testdata/test-skillplan.xml")
+		So(err, ShouldBeNil)
+		sheet := &evego.CharacterSheet{
+			Skills: []evego.Skill{
+				{TypeID: 3327, NumSkillpoints: 45255, Level: 4},
+				{TypeID: 3436, NumSkillpoints: 100000, Level: 4},
+			},
+		}
+
+		Convey("A plain text plan has its prerequisites inserted.", func() {
+			plan, err := parsing.ParseSkillPlan(string(text), db, nil)
+			So(err, ShouldBeNil)
+			So(planText(plan), ShouldEqual, `Spaceship Command I
+Gallente Frigate I
+Gallente Frigate II
+Gallente Frigate III
+Gallente Cruiser I
+Gallente Frigate IV
+Spaceship Command II
+Spaceship Command III
+Spaceship Command IV
+Spaceship Command V
+Drones I
+Light Drone Operation I
+Light Drone Operation II
+`)
+		})
+
+		Convey("Levels the character has trained are left out.", func() {
+			plan, err := parsing.ParseSkillPlan(string(text), db, sheet)
+			So(err, ShouldBeNil)
+			So(planText(plan), ShouldEqual, `Gallente Frigate I
+Gallente Frigate II
+Gallente Frigate III
+Gallente Cruiser I
+Gallente Frigate IV
+Spaceship Command V
+Light Drone Operation I
+Light Drone Operation II
+`)
+		})
+
+		Convey("Bad lines in plain text plans are reported.", func() {
+			_, err := parsing.ParseSkillPlan("Gunnery VI", db, nil)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseSkillPlan("Gunnery 3\nNo Such Skill IV", db, nil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 2")
+			_, err = parsing.ParseSkillPlan("Vexor I", db, nil)
+			So(err, ShouldNotBeNil)
+			_, err = parsing.ParseSkillPlan("Gunnery", db, nil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("An EVEMon plan is read.", func() {
+			name, plan, err := parsing.ParseEVEMonPlan(bytes.NewReader(emp), db, sheet)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Vexor")
+			So(planText(plan), ShouldEqual, `Gallente Frigate I
+Gallente Frigate II
+Gallente Frigate III
+Gallente Cruiser I
+Light Drone Operation I
+Light Drone Operation II
+`)
+		})
+
+		Convey("A compressed EVEMon plan is read.", func() {
+			var compressed bytes.Buffer
+			gz := gzip.NewWriter(&compressed)
+			gz.Write(emp)
+			So(gz.Close(), ShouldBeNil)
+			name, plan, err := parsing.ParseEVEMonPlan(&compressed, db, nil)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Vexor")
+			So(plan, ShouldHaveLength, 8)
+		})
+
+		Convey("An EVEMon plan can be written and read back.", func() {
+			plan, err := parsing.ParseSkillPlan(string(text), db, sheet)
+			So(err, ShouldBeNil)
+			var buf bytes.Buffer
+			So(parsing.WriteEVEMonPlan(&buf, "Cruisers", plan), ShouldBeNil)
+			So(buf.String(), ShouldStartWith, "<?xml")
+			So(buf.String(), ShouldContainSubstring,
+				`<entry skillID="3335" skill="Gallente Cruiser" level="1" priority="3" type="Planned"></entry>`)
+			name, readBack, err := parsing.ParseEVEMonPlan(strings.NewReader(buf.String()), db, sheet)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Cruisers")
+			So(readBack, ShouldResemble, plan)
+		})
+	})
+}
@@ -19,6 +19,7 @@ limitations under the License.
 package routing
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -63,35 +64,46 @@ func EveCentralRouter(endpoint string, aCache evego.Cache) evego.Router {
 	return &eveCentralRouter{endpoint: epURL, respCache: aCache}
 }
 
-func (r *eveCentralRouter) getURL(u string) ([]byte, error) {
+func (r *eveCentralRouter) getURL(ctx context.Context, u string) ([]byte, error) {
 	// Check cache first.
-	cachedBody, found := r.respCache.Get(u)
+	cachedBody, found := r.respCache.GetContext(ctx, u)
 	if found {
 		return cachedBody, nil
 	}
 
-	req, err := http.NewRequest("GET", u, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("User-Agent", "evego (https://github.com/backerman/evego)")
 	resp, err := r.http.Do(req)
-	defer resp.Body.Close()
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 	// EVE-Central doesn't specify a caching time to use, so we're picking
 	// five minutes at random.
-	r.respCache.Put(u, body, time.Now().Add(5*time.Minute))
-	return body, err
+	r.respCache.PutContext(ctx, u, body, time.Now().Add(5*time.Minute))
+	return body, nil
 }
 
 func (r *eveCentralRouter) NumJumps(fromSystem, toSystem *evego.SolarSystem) (int, error) {
-	return r.NumJumpsID(fromSystem.ID, toSystem.ID)
+	return r.NumJumpsContext(context.Background(), fromSystem, toSystem)
+}
+
+func (r *eveCentralRouter) NumJumpsContext(ctx context.Context, fromSystem, toSystem *evego.SolarSystem) (int, error) {
+	return r.NumJumpsIDContext(ctx, fromSystem.ID, toSystem.ID)
 }
 
 func (r *eveCentralRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
+	return r.NumJumpsIDContext(context.Background(), fromSystemID, toSystemID)
+}
+
+func (r *eveCentralRouter) NumJumpsIDContext(ctx context.Context, fromSystemID, toSystemID int) (int, error) {
 	// Don't even query the server if the start and end are identical.
 	if fromSystemID == toSystemID {
 		return 0, nil
@@ -99,7 +111,7 @@ func (r *eveCentralRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error)
 	// Copy the endpoint.
 	queryURL := *r.endpoint
 	queryURL.Path += fmt.Sprintf("/from/%d/to/%d", fromSystemID, toSystemID)
-	respJSON, err := r.getURL(queryURL.String())
+	respJSON, err := r.getURL(ctx, queryURL.String())
 	if err != nil {
 		return 0, err
 	}
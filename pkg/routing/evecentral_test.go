@@ -19,6 +19,8 @@ package routing_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -26,6 +28,7 @@ import (
 	"os"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/backerman/evego/pkg/cache"
 	"github.com/backerman/evego/pkg/dbaccess"
@@ -101,7 +104,8 @@ func TestEVECentralRouting(t *testing.T) {
 		defer ts.Close()
 
 		router := routing.EveCentralRouter(ts.URL, cache.NilCache())
-		db := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
 
 		defer db.Close()
 		defer router.Close()
@@ -160,3 +164,27 @@ func TestEVECentralRouting(t *testing.T) {
 
 	})
 }
+
+func TestEVECentralRoutingCanceled(t *testing.T) {
+	Convey("Given a server that never answers", t, func() {
+		done := make(chan struct{})
+		ts := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-done:
+				}
+			}))
+		defer ts.Close()
+		defer close(done)
+		router := routing.EveCentralRouter(ts.URL, cache.NilCache())
+		defer router.Close()
+
+		Convey("The request is abandoned when its context expires.", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := router.NumJumpsIDContext(ctx, 30003830, 30003829)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+	})
+}
@@ -19,6 +19,7 @@ limitations under the License.
 package routing
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -86,30 +87,34 @@ func SQLRouter(driver, dataSource string, aCache evego.Cache) evego.Router {
 }
 
 func (r *sqlRouter) NumJumps(fromSystem, toSystem *evego.SolarSystem) (int, error) {
+	return r.NumJumpsContext(context.Background(), fromSystem, toSystem)
+}
+
+func (r *sqlRouter) NumJumpsContext(ctx context.Context, fromSystem, toSystem *evego.SolarSystem) (int, error) {
 	if fromSystem == nil {
 		return 0, errors.New("Starting system must be non-nil")
 	}
 	if toSystem == nil {
 		return 0, errors.New("Ending system must be non-nil")
 	}
-	return r.NumJumpsID(fromSystem.ID, toSystem.ID)
+	return r.NumJumpsIDContext(ctx, fromSystem.ID, toSystem.ID)
 }
 
 // putCache adds a routing result to the cache. The expiration time is
 // arbitrarily set to one day.
-func (r *sqlRouter) putCache(fromSystemID, toSystemID, numJumps int) error {
+func (r *sqlRouter) putCache(ctx context.Context, fromSystemID, toSystemID, numJumps int) error {
 	key := "numjumps:" + strconv.Itoa(fromSystemID) +
 		":" + strconv.Itoa(toSystemID)
 	val := []byte(strconv.Itoa(numJumps))
-	return r.cache.Put(key, val, time.Now().Add(24*time.Hour))
+	return r.cache.PutContext(ctx, key, val, time.Now().Add(24*time.Hour))
 }
 
 // getCache finds a routing result in the cache. It returns the number of jumps
 // (undefined if not found) and whether the result was contained in the cache.
-func (r *sqlRouter) getCache(fromSystemID, toSystemID int) (int, bool) {
+func (r *sqlRouter) getCache(ctx context.Context, fromSystemID, toSystemID int) (int, bool) {
 	key := "numjumps:" + strconv.Itoa(fromSystemID) +
 		":" + strconv.Itoa(toSystemID)
-	val, found := r.cache.Get(key)
+	val, found := r.cache.GetContext(ctx, key)
 	if found {
 		// Convert cached from []byte to integer and return it.
 		cachedAsInt, err := strconv.Atoi(string(val))
@@ -122,6 +127,10 @@ func (r *sqlRouter) getCache(fromSystemID, toSystemID int) (int, bool) {
 }
 
 func (r *sqlRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
+	return r.NumJumpsIDContext(context.Background(), fromSystemID, toSystemID)
+}
+
+func (r *sqlRouter) NumJumpsIDContext(ctx context.Context, fromSystemID, toSystemID int) (int, error) {
 	// This function will be implemented differently depending on the
 	// backend database.
 	if fromSystemID == toSystemID {
@@ -130,7 +139,7 @@ func (r *sqlRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
 	}
 
 	// Check for this result in the cache; if it's already there, return it.
-	cached, found := r.getCache(fromSystemID, toSystemID)
+	cached, found := r.getCache(ctx, fromSystemID, toSystemID)
 	if found {
 		return cached, nil
 	}
@@ -138,7 +147,7 @@ func (r *sqlRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
 	switch r.dialect {
 	case sqlite:
 		var numRows int
-		err := r.numJumpsStmt.Get(&numRows, fromSystemID, toSystemID)
+		err := r.numJumpsStmt.GetContext(ctx, &numRows, fromSystemID, toSystemID)
 		if err != nil {
 			return 0, err
 		}
@@ -148,14 +157,14 @@ func (r *sqlRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
 		// Therefore, if numRows-1 is 0, there is no route; otherwise, the
 		// route contains numRows-1 jumps.
 		if numRows == 1 {
-			r.putCache(fromSystemID, toSystemID, -1)
+			r.putCache(ctx, fromSystemID, toSystemID, -1)
 			return -1, nil
 		}
-		r.putCache(fromSystemID, toSystemID, numRows-1)
+		r.putCache(ctx, fromSystemID, toSystemID, numRows-1)
 		return numRows - 1, nil
 	case postgres:
 		var numRows int
-		err := r.numJumpsStmt.Get(&numRows, fromSystemID, toSystemID)
+		err := r.numJumpsStmt.GetContext(ctx, &numRows, fromSystemID, toSystemID)
 		if err != nil {
 			return 0, err
 		}
@@ -164,10 +173,10 @@ func (r *sqlRouter) NumJumpsID(fromSystemID, toSystemID int) (int, error) {
 		// same system, and k jumps where k=n+1 if n>=2. Since we've already checked
 		// for the same-system case, that doesn't apply here.
 		if numRows == 0 {
-			r.putCache(fromSystemID, toSystemID, -1)
+			r.putCache(ctx, fromSystemID, toSystemID, -1)
 			return -1, nil
 		}
-		r.putCache(fromSystemID, toSystemID, numRows-1)
+		r.putCache(ctx, fromSystemID, toSystemID, numRows-1)
 		return numRows - 1, nil
 	default:
 		return -1, fmt.Errorf("Routing is not supported for this database type.")
@@ -54,6 +54,7 @@ func TestSQLRouting(t *testing.T) {
 	Convey("Open a database connection.", t, func() {
 		var router evego.Router
 		var db evego.Database
+		var err error
 		cacheData := &CacheData{}
 		switch testDbDriver {
 		case "sqlite3":
@@ -69,10 +70,12 @@ func TestSQLRouting(t *testing.T) {
 					})
 			})
 			router = routing.SQLRouter("sqlite3_spatialite", testDbPath, Cache(cacheData))
-			db = dbaccess.SQLDatabase("sqlite3_spatialite", testDbPath)
+			db, err = dbaccess.SQLDatabase("sqlite3_spatialite", testDbPath)
+			So(err, ShouldBeNil)
 		case "postgres":
 			router = routing.SQLRouter(testDbDriver, testDbPath, Cache(cacheData))
-			db = dbaccess.SQLDatabase(testDbDriver, testDbPath)
+			db, err = dbaccess.SQLDatabase(testDbDriver, testDbPath)
+			So(err, ShouldBeNil)
 		default:
 			Println("The database under test does not yet support routing; skipping.")
 			return
@@ -0,0 +1,382 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+// Package sdediff compares two versions of the static data export.
+package sdediff
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"text/template"
+
+	"github.com/backerman/evego"
+)
+
+// Type is an item type in a report.
+type Type struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func typeOf(item *evego.Item) Type {
+	return Type{ID: item.ID, Name: item.Name}
+}
+
+// Rename is an item type whose name has changed.
+type Rename struct {
+	ID      int    `json:"id"`
+	OldName string `json:"oldName"`
+	NewName string `json:"newName"`
+}
+
+// QuantityChange is a material whose quantity has changed. A quantity of zero
+// means that the material was added or removed.
+type QuantityChange struct {
+	Type
+	Old int `json:"old"`
+	New int `json:"new"`
+}
+
+// CompositionChange is an item whose reprocessing materials have changed.
+type CompositionChange struct {
+	Type      Type             `json:"type"`
+	Materials []QuantityChange `json:"materials"`
+}
+
+// BlueprintChange is a blueprint whose inputs or outputs for an activity have
+// changed. If the activity was added or removed, OldQuantity or NewQuantity is
+// zero.
+type BlueprintChange struct {
+	Blueprint   Type               `json:"blueprint"`
+	Activity    evego.ActivityType `json:"activity"`
+	Product     Type               `json:"product"`
+	OldQuantity int                `json:"oldQuantity"`
+	NewQuantity int                `json:"newQuantity"`
+	Inputs      []QuantityChange   `json:"inputs"`
+}
+
+// StationChange is a station whose reprocessing yield has changed.
+type StationChange struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	OldYield float64 `json:"oldYield"`
+	NewYield float64 `json:"newYield"`
+}
+
+// Report is the set of differences between two versions of the SDE.
+type Report struct {
+	AddedTypes   []Type              `json:"addedTypes"`
+	RemovedTypes []Type              `json:"removedTypes"`
+	RenamedTypes []Rename            `json:"renamedTypes"`
+	Compositions []CompositionChange `json:"compositions"`
+	Blueprints   []BlueprintChange   `json:"blueprints"`
+	Stations     []StationChange     `json:"stations"`
+}
+
+// allItems returns every item in the database, keyed by type ID.
+func allItems(db evego.Database) (map[int]*evego.Item, error) {
+	ids, err := db.ItemIDs()
+	if err != nil {
+		return nil, err
+	}
+	found, err := db.ItemsForIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[int]*evego.Item, len(found))
+	for _, item := range found {
+		items[item.ID] = item
+	}
+	return items, nil
+}
+
+// sortedIDs returns the keys of an item map in ascending order.
+func sortedIDs(items map[int]*evego.Item) []int {
+	ids := make([]int, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// quantities converts a list of materials into a map of type ID to quantity,
+// also recording each material's name.
+func quantities(lines []evego.InventoryLine, names map[int]string) map[int]int {
+	result := make(map[int]int)
+	for _, l := range lines {
+		result[l.Item.ID] += l.Quantity
+		names[l.Item.ID] = l.Item.Name
+	}
+	return result
+}
+
+// quantityChanges returns the differences between two lists of materials,
+// sorted by type ID.
+func quantityChanges(oldLines, newLines []evego.InventoryLine) []QuantityChange {
+	names := make(map[int]string)
+	oldQty := quantities(oldLines, names)
+	newQty := quantities(newLines, names)
+	var ids []int
+	for id := range names {
+		if oldQty[id] != newQty[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	changes := make([]QuantityChange, 0, len(ids))
+	for _, id := range ids {
+		changes = append(changes, QuantityChange{
+			Type: Type{ID: id, Name: names[id]},
+			Old:  oldQty[id],
+			New:  newQty[id],
+		})
+	}
+	return changes
+}
+
+// Compare returns the differences between two versions of the SDE.
+func Compare(oldDb, newDb evego.Database) (*Report, error) {
+	report := &Report{}
+	oldItems, err := allItems(oldDb)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get items from old database: %v", err)
+	}
+	newItems, err := allItems(newDb)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get items from new database: %v", err)
+	}
+	oldComps, err := oldDb.ItemsComposition(sortedIDs(oldItems))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get compositions from old database: %v", err)
+	}
+	newComps, err := newDb.ItemsComposition(sortedIDs(newItems))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get compositions from new database: %v", err)
+	}
+
+	for _, id := range sortedIDs(oldItems) {
+		oldItem := oldItems[id]
+		newItem, ok := newItems[id]
+		if !ok {
+			report.RemovedTypes = append(report.RemovedTypes, typeOf(oldItem))
+			continue
+		}
+		if oldItem.Name != newItem.Name {
+			report.RenamedTypes = append(report.RenamedTypes, Rename{
+				ID:      id,
+				OldName: oldItem.Name,
+				NewName: newItem.Name,
+			})
+		}
+		if changes := quantityChanges(oldComps[id], newComps[id]); len(changes) > 0 {
+			report.Compositions = append(report.Compositions, CompositionChange{
+				Type:      typeOf(newItem),
+				Materials: changes,
+			})
+		}
+	}
+	for _, id := range sortedIDs(newItems) {
+		if _, ok := oldItems[id]; !ok {
+			report.AddedTypes = append(report.AddedTypes, typeOf(newItems[id]))
+		}
+	}
+
+	report.Blueprints, err = compareBlueprints(oldDb, newDb)
+	if err != nil {
+		return nil, err
+	}
+	report.Stations, err = compareStations(oldDb, newDb)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// blueprintKey identifies one output of a blueprint activity.
+type blueprintKey struct {
+	blueprint int
+	activity  evego.ActivityType
+	product   int
+}
+
+// blueprintActivities returns all of the database's blueprint activities.
+func blueprintActivities(db evego.Database) (map[blueprintKey]evego.IndustryActivity, error) {
+	activities, err := db.BlueprintOutputs("%")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[blueprintKey]evego.IndustryActivity, len(activities))
+	for _, a := range activities {
+		key := blueprintKey{a.InputItem.ID, a.ActivityType, a.OutputItem.ID}
+		result[key] = a
+	}
+	return result, nil
+}
+
+// blueprintInputs returns the inputs for every activity of the blueprints in
+// activities.
+func blueprintInputs(db evego.Database, activities map[blueprintKey]evego.IndustryActivity) (map[evego.BlueprintActivity][]evego.InventoryLine, error) {
+	seen := make(map[int]bool)
+	var ids []int
+	for key := range activities {
+		if !seen[key.blueprint] {
+			seen[key.blueprint] = true
+			ids = append(ids, key.blueprint)
+		}
+	}
+	sort.Ints(ids)
+	return db.BlueprintsInputs(ids)
+}
+
+type blueprintChanges []BlueprintChange
+
+func (b blueprintChanges) Len() int      { return len(b) }
+func (b blueprintChanges) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b blueprintChanges) Less(i, j int) bool {
+	switch {
+	case b[i].Blueprint.ID != b[j].Blueprint.ID:
+		return b[i].Blueprint.ID < b[j].Blueprint.ID
+	case b[i].Activity != b[j].Activity:
+		return b[i].Activity < b[j].Activity
+	}
+	return b[i].Product.ID < b[j].Product.ID
+}
+
+func compareBlueprints(oldDb, newDb evego.Database) ([]BlueprintChange, error) {
+	oldActivities, err := blueprintActivities(oldDb)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get blueprints from old database: %v", err)
+	}
+	newActivities, err := blueprintActivities(newDb)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get blueprints from new database: %v", err)
+	}
+	oldInputs, err := blueprintInputs(oldDb, oldActivities)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get blueprint inputs from old database: %v", err)
+	}
+	newInputs, err := blueprintInputs(newDb, newActivities)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get blueprint inputs from new database: %v", err)
+	}
+	keys := make(map[blueprintKey]bool)
+	for k := range oldActivities {
+		keys[k] = true
+	}
+	for k := range newActivities {
+		keys[k] = true
+	}
+
+	var changes []BlueprintChange
+	for key := range keys {
+		change := BlueprintChange{Activity: key.activity}
+		inputsKey := evego.BlueprintActivity{BlueprintID: key.blueprint, Activity: key.activity}
+		var oldActivityInputs, newActivityInputs []evego.InventoryLine
+		if a, ok := oldActivities[key]; ok {
+			oldActivityInputs = oldInputs[inputsKey]
+			change.Blueprint = typeOf(a.InputItem)
+			change.Product = typeOf(a.OutputItem)
+			change.OldQuantity = a.OutputQuantity
+		}
+		if a, ok := newActivities[key]; ok {
+			newActivityInputs = newInputs[inputsKey]
+			change.Blueprint = typeOf(a.InputItem)
+			change.Product = typeOf(a.OutputItem)
+			change.NewQuantity = a.OutputQuantity
+		}
+		change.Inputs = quantityChanges(oldActivityInputs, newActivityInputs)
+		if change.OldQuantity != change.NewQuantity || len(change.Inputs) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	sort.Sort(blueprintChanges(changes))
+	return changes, nil
+}
+
+// allStations returns every station in the database, keyed by station ID.
+func allStations(db evego.Database) (map[int]evego.Station, error) {
+	stations, err := db.StationsForName("%")
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	result := make(map[int]evego.Station, len(stations))
+	for _, s := range stations {
+		result[s.ID] = s
+	}
+	return result, nil
+}
+
+func compareStations(oldDb, newDb evego.Database) ([]StationChange, error) {
+	oldStations, err := allStations(oldDb)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get stations from old database: %v", err)
+	}
+	newStations, err := allStations(newDb)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get stations from new database: %v", err)
+	}
+	var ids []int
+	for id, oldStation := range oldStations {
+		newStation, ok := newStations[id]
+		if ok && newStation.ReprocessingEfficiency != oldStation.ReprocessingEfficiency {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	changes := make([]StationChange, 0, len(ids))
+	for _, id := range ids {
+		changes = append(changes, StationChange{
+			ID:       id,
+			Name:     newStations[id].Name,
+			OldYield: oldStations[id].ReprocessingEfficiency,
+			NewYield: newStations[id].ReprocessingEfficiency,
+		})
+	}
+	return changes, nil
+}
+
+var reportTmpl = template.Must(template.New("report").Parse(
+	`{{with .AddedTypes}}Added types:
+{{range .}}  {{.Name}} ({{.ID}})
+{{end}}{{end}}{{with .RemovedTypes}}Removed types:
+{{range .}}  {{.Name}} ({{.ID}})
+{{end}}{{end}}{{with .RenamedTypes}}Renamed types:
+{{range .}}  {{.OldName}} -> {{.NewName}} ({{.ID}})
+{{end}}{{end}}{{with .Compositions}}Reprocessing materials:
+{{range .}}  {{.Type.Name}} ({{.Type.ID}})
+{{range .Materials}}    {{.Name}}: {{.Old}} -> {{.New}}
+{{end}}{{end}}{{end}}{{with .Blueprints}}Blueprints:
+{{range .}}  {{.Blueprint.Name}} ({{.Blueprint.ID}}) {{.Activity}} -> {{.Product.Name}}: {{.OldQuantity}} -> {{.NewQuantity}}
+{{range .Inputs}}    {{.Name}}: {{.Old}} -> {{.New}}
+{{end}}{{end}}{{end}}{{with .Stations}}Station reprocessing yields:
+{{range .}}  {{.Name}} ({{.ID}}): {{.OldYield}} -> {{.NewYield}}
+{{end}}{{end}}`))
+
+// WriteText writes a human-readable version of the report.
+func (r *Report) WriteText(w io.Writer) error {
+	return reportTmpl.Execute(w, r)
+}
+
+// Empty returns true if the report contains no differences.
+func (r *Report) Empty() bool {
+	return len(r.AddedTypes) == 0 && len(r.RemovedTypes) == 0 &&
+		len(r.RenamedTypes) == 0 && len(r.Compositions) == 0 &&
+		len(r.Blueprints) == 0 && len(r.Stations) == 0
+}
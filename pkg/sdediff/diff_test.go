@@ -0,0 +1,175 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package sdediff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/backerman/evego"
+	"github.com/backerman/evego/pkg/dbaccess"
+	"github.com/backerman/evego/pkg/sdediff"
+	"github.com/spf13/viper"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	// Register SQLite3 and PgSQL drivers
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testDbDriver, testDbPath string
+
+func init() {
+	viper.SetDefault("DBDriver", "sqlite3")
+	viper.SetDefault("DBPath", "../../testdb.sqlite")
+	viper.SetEnvPrefix("EVEGO_TEST")
+	viper.AutomaticEnv()
+	testDbDriver = viper.GetString("DBDriver")
+	testDbPath = viper.GetString("DBPath")
+}
+
+const (
+	tritaniumID = 34
+	scorditeID  = 1228
+)
+
+// patchedDb is a database that differs from the test database in a few
+// known ways.
+type patchedDb struct {
+	evego.Database
+	gunID int
+}
+
+// ItemIDs drops Scordite.
+func (db patchedDb) ItemIDs() ([]int, error) {
+	ids, err := db.Database.ItemIDs()
+	if err != nil {
+		return nil, err
+	}
+	var result []int
+	for _, id := range ids {
+		if id != scorditeID {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// ItemsForIDs renames Tritanium.
+func (db patchedDb) ItemsForIDs(ids []int) ([]*evego.Item, error) {
+	items, err := db.Database.ItemsForIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if item.ID == tritaniumID {
+			renamed := *item
+			renamed.Name = "Tritanium II"
+			items[i] = &renamed
+		}
+	}
+	return items, nil
+}
+
+// ItemsComposition doubles the materials from reprocessing a module.
+func (db patchedDb) ItemsComposition(ids []int) (map[int][]evego.InventoryLine, error) {
+	comps, err := db.Database.ItemsComposition(ids)
+	if err != nil {
+		return nil, err
+	}
+	var doubled []evego.InventoryLine
+	for _, m := range comps[db.gunID] {
+		doubled = append(doubled, evego.InventoryLine{Quantity: 2 * m.Quantity, Item: m.Item})
+	}
+	if doubled != nil {
+		comps[db.gunID] = doubled
+	}
+	return comps, nil
+}
+
+// StationsForName improves every station's reprocessing yield.
+func (db patchedDb) StationsForName(name string) ([]evego.Station, error) {
+	stations, err := db.Database.StationsForName(name)
+	for i := range stations {
+		stations[i].ReprocessingEfficiency += 0.1
+	}
+	return stations, err
+}
+
+func TestCompare(t *testing.T) {
+	Convey("Open a database connection", t, func() {
+		db, err := dbaccess.SQLDatabase(testDbDriver, testDbPath)
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("Comparing the database to itself finds no differences.", func() {
+			report, err := sdediff.Compare(db, db)
+			So(err, ShouldBeNil)
+			So(report.Empty(), ShouldBeTrue)
+		})
+
+		Convey("Comparing the database to a modified version", func() {
+			gun, err := db.ItemForName("150mm Prototype Gauss Gun")
+			So(err, ShouldBeNil)
+			report, err := sdediff.Compare(db, patchedDb{db, gun.ID})
+			So(err, ShouldBeNil)
+
+			Convey("The removed and renamed types are found.", func() {
+				So(report.AddedTypes, ShouldBeEmpty)
+				So(report.RemovedTypes, ShouldResemble, []sdediff.Type{
+					{ID: scorditeID, Name: "Scordite"},
+				})
+				So(report.RenamedTypes, ShouldResemble, []sdediff.Rename{
+					{ID: tritaniumID, OldName: "Tritanium", NewName: "Tritanium II"},
+				})
+			})
+
+			Convey("The changed reprocessing materials are found.", func() {
+				So(report.Compositions, ShouldHaveLength, 1)
+				change := report.Compositions[0]
+				So(change.Type.ID, ShouldEqual, gun.ID)
+				So(change.Materials, ShouldNotBeEmpty)
+				for _, m := range change.Materials {
+					So(m.New, ShouldEqual, 2*m.Old)
+				}
+			})
+
+			Convey("The changed station yields are found.", func() {
+				So(report.Stations, ShouldNotBeEmpty)
+				for _, s := range report.Stations {
+					So(s.NewYield, ShouldAlmostEqual, s.OldYield+0.1)
+				}
+			})
+
+			Convey("Blueprints are unchanged.", func() {
+				So(report.Blueprints, ShouldBeEmpty)
+			})
+
+			Convey("The report can be written as text and JSON.", func() {
+				var buf bytes.Buffer
+				So(report.WriteText(&buf), ShouldBeNil)
+				So(buf.String(), ShouldContainSubstring, "Tritanium -> Tritanium II (34)")
+				encoded, err := json.Marshal(report)
+				So(err, ShouldBeNil)
+				So(string(encoded), ShouldContainSubstring, `"newName":"Tritanium II"`)
+			})
+		})
+	})
+}
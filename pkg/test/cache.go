@@ -18,6 +18,7 @@ limitations under the License.
 package test
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -62,6 +63,14 @@ func (c *testCache) Put(key string, val []byte, expires time.Time) error {
 	return nil
 }
 
+func (c *testCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	return c.Get(key)
+}
+
+func (c *testCache) PutContext(ctx context.Context, key string, val []byte, expires time.Time) error {
+	return c.Put(key, val, expires)
+}
+
 func (c *testCache) Close() error {
 	return nil
 }
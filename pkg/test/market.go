@@ -0,0 +1,106 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package test
+
+import (
+	"context"
+
+	"github.com/backerman/evego"
+)
+
+// MarketData holds the prices offered by the test market, keyed by type ID.
+// Items without a price have no orders of that type.
+type MarketData struct {
+	BuyPrices, SellPrices map[int]float64
+}
+
+type testMarket struct {
+	data *MarketData
+}
+
+// Market returns a market object used for testing. Every item has at most a
+// single region-wide buy order and a single sell order, in whatever station
+// is asked about.
+func Market(data *MarketData) evego.Market {
+	return &testMarket{data: data}
+}
+
+func (m *testMarket) order(item *evego.Item, station *evego.Station, t evego.OrderType) (evego.Order, bool) {
+	prices := m.data.SellPrices
+	if t == evego.Buy {
+		prices = m.data.BuyPrices
+	}
+	price, ok := prices[item.ID]
+	if !ok {
+		return evego.Order{}, false
+	}
+	return evego.Order{
+		Type:      t,
+		Item:      item,
+		Quantity:  1000000,
+		Price:     price,
+		Station:   station,
+		JumpRange: evego.BuyRegion,
+	}, true
+}
+
+func (m *testMarket) OrdersForItem(item *evego.Item, location string, orderType evego.OrderType) (*[]evego.Order, error) {
+	station := &evego.Station{Name: location}
+	orders := []evego.Order{}
+	for _, t := range []evego.OrderType{evego.Sell, evego.Buy} {
+		if orderType != evego.AllOrders && orderType != t {
+			continue
+		}
+		if o, ok := m.order(item, station, t); ok {
+			orders = append(orders, o)
+		}
+	}
+	return &orders, nil
+}
+
+func (m *testMarket) BuyInStation(item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	orders := []evego.Order{}
+	if o, ok := m.order(item, location, evego.Buy); ok {
+		orders = append(orders, o)
+	}
+	return &orders, nil
+}
+
+func (m *testMarket) OrdersInStation(item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	orders, _ := m.BuyInStation(item, location)
+	if o, ok := m.order(item, location, evego.Sell); ok {
+		*orders = append(*orders, o)
+	}
+	return orders, nil
+}
+
+func (m *testMarket) OrdersForItemContext(ctx context.Context, item *evego.Item, location string, orderType evego.OrderType) (*[]evego.Order, error) {
+	return m.OrdersForItem(item, location, orderType)
+}
+
+func (m *testMarket) BuyInStationContext(ctx context.Context, item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	return m.BuyInStation(item, location)
+}
+
+func (m *testMarket) OrdersInStationContext(ctx context.Context, item *evego.Item, location *evego.Station) (*[]evego.Order, error) {
+	return m.OrdersInStation(item, location)
+}
+
+func (m *testMarket) Close() error {
+	return nil
+}
@@ -18,17 +18,22 @@ limitations under the License.
 package evego
 
 import (
+	"context"
 	"io"
 )
 
 // Router is the interface for the backing service that provides a router.
+// NumJumpsContext and NumJumpsIDContext stop waiting for a route once their
+// context is done.
 type Router interface {
 	io.Closer
 	// NumJumps returns the number of jumps in the shortest path from
 	// fromSystem to toSystem, or -1 if the destination is unreachable
 	// from the start.
 	NumJumps(fromSystem, toSystem *SolarSystem) (int, error)
+	NumJumpsContext(ctx context.Context, fromSystem, toSystem *SolarSystem) (int, error)
 
 	// NumJumpsID is a convenience method for NumJumps. Or is it the reverse?
 	NumJumpsID(fromSystemID, toSystemID int) (int, error)
+	NumJumpsIDContext(ctx context.Context, fromSystemID, toSystemID int) (int, error)
 }
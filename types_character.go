@@ -17,7 +17,10 @@ limitations under the License.
 
 package evego
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+)
 
 // Character represents one EVE player toon.
 type Character struct {
@@ -33,7 +36,21 @@ type Character struct {
 // as provied by the /char/CharacterSheet.xml.aspx endpoint.
 type CharacterSheet struct {
 	Character
-	Skills []Skill `json:"skills"`
+	Skills     []Skill    `json:"skills"`
+	Attributes Attributes `json:"attributes"`
+	// Implants holds the type IDs of the character's active implants.
+	Implants []int `json:"implants"`
+}
+
+// Attributes are a character's attributes, which determine how quickly
+// skills are trained. The character sheet's attributes don't include the
+// bonuses from implants.
+type Attributes struct {
+	Intelligence int `json:"intelligence"`
+	Memory       int `json:"memory"`
+	Charisma     int `json:"charisma"`
+	Perception   int `json:"perception"`
+	Willpower    int `json:"willpower"`
 }
 
 // Skill is one of a character's injected skills.
@@ -47,6 +64,29 @@ type Skill struct {
 	Published      bool   `json:"isPublished"    xml:"published,attr"`
 }
 
+// PlannedSkill is a skill level to be trained as part of a skill plan.
+type PlannedSkill struct {
+	Skill *Item `json:"skill"`
+	Level int   `json:"level"`
+}
+
+func (p PlannedSkill) String() string {
+	return fmt.Sprintf("%s %s", p.Skill.Name, RomanNumeral(p.Level))
+}
+
+// SkillPlan is an ordered list of skill levels to train.
+type SkillPlan []PlannedSkill
+
+var numMap = [...]string{"0", "I", "II", "III", "IV", "V"}
+
+// RomanNumeral returns a skill level in the Roman numerals used by the game.
+func RomanNumeral(n int) string {
+	if n < 0 || n >= len(numMap) {
+		return fmt.Sprintf("(out of range: %d)", n)
+	}
+	return numMap[n]
+}
+
 // Wrappers to sort skills using the standard library's sort package.
 type skillsSorted []Skill
 
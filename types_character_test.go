@@ -47,3 +47,13 @@ func TestSkillSort(t *testing.T) {
 		})
 	})
 }
+
+func TestSkillLevels(t *testing.T) {
+	Convey("Skill levels are shown in Roman numerals.", t, func() {
+		So(RomanNumeral(0), ShouldEqual, "0")
+		So(RomanNumeral(4), ShouldEqual, "IV")
+		So(RomanNumeral(6), ShouldEqual, "(out of range: 6)")
+		planned := PlannedSkill{Skill: &Item{Name: "Gunnery"}, Level: 5}
+		So(planned.String(), ShouldEqual, "Gunnery V")
+	})
+}
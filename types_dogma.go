@@ -0,0 +1,123 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package evego
+
+import "fmt"
+
+// Well-known dogma attribute IDs.
+const (
+	AttributeMass                = 4
+	AttributePowerOutput         = 11
+	AttributeLowSlots            = 12
+	AttributeMedSlots            = 13
+	AttributeHiSlots             = 14
+	AttributePower               = 30
+	AttributeCapacity            = 38
+	AttributeCPUOutput           = 48
+	AttributeCPU                 = 50
+	AttributeLauncherSlotsLeft   = 101
+	AttributeTurretSlotsLeft     = 102
+	AttributeVolume              = 161
+	AttributeCharisma            = 164
+	AttributeIntelligence        = 165
+	AttributeMemory              = 166
+	AttributePerception          = 167
+	AttributeWillpower           = 168
+	AttributeCharismaBonus       = 175
+	AttributeIntelligenceBonus   = 176
+	AttributeMemoryBonus         = 177
+	AttributePerceptionBonus     = 178
+	AttributeWillpowerBonus      = 179
+	AttributePrimaryAttribute    = 180
+	AttributeSecondaryAttribute  = 181
+	AttributeRequiredSkill1      = 182
+	AttributeRequiredSkill2      = 183
+	AttributeRequiredSkill3      = 184
+	AttributeSkillTimeConstant   = 275
+	AttributeRequiredSkill1Level = 277
+	AttributeRequiredSkill2Level = 278
+	AttributeRequiredSkill3Level = 279
+	AttributeTechLevel           = 422
+	AttributeChargeGroup1        = 604
+	AttributeChargeGroup2        = 605
+	AttributeChargeGroup3        = 606
+	AttributeChargeGroup4        = 609
+	AttributeChargeGroup5        = 610
+	AttributeMetaLevel           = 633
+	AttributeUpgradeCapacity     = 1132
+	AttributeRigSlots            = 1137
+	AttributeRequiredSkill4      = 1285
+	AttributeRequiredSkill4Level = 1286
+	AttributeRequiredSkill5Level = 1287
+	AttributeRequiredSkill6Level = 1288
+	AttributeRequiredSkill5      = 1289
+	AttributeRequiredSkill6      = 1290
+)
+
+// Well-known dogma effect IDs.
+const (
+	EffectLoPower        = 11
+	EffectHiPower        = 12
+	EffectMedPower       = 13
+	EffectLauncherFitted = 40
+	EffectTurretFitted   = 42
+	EffectRigSlot        = 2663
+	EffectSubSystem      = 3772
+)
+
+// DogmaAttribute is the definition of a dogma attribute (from dgmAttributeTypes).
+type DogmaAttribute struct {
+	ID           int     `db:"attributeID"`
+	Name         string  `db:"attributeName"`
+	DisplayName  string  `db:"displayName"`
+	Description  string  `db:"description"`
+	DefaultValue float64 `db:"defaultValue"`
+	UnitID       int     `db:"unitID"`
+	Published    bool    `db:"published"`
+	Stackable    bool    `db:"stackable"`
+	HighIsGood   bool    `db:"highIsGood"`
+}
+
+func (a DogmaAttribute) String() string {
+	return fmt.Sprintf("Attribute: %s (%d)", a.Name, a.ID)
+}
+
+// ItemAttribute is the value of a dogma attribute on an item type.
+type ItemAttribute struct {
+	TypeID      int     `db:"typeID"`
+	AttributeID int     `db:"attributeID"`
+	Name        string  `db:"attributeName"`
+	Value       float64 `db:"value"`
+}
+
+func (a ItemAttribute) String() string {
+	return fmt.Sprintf("[%d: %s (%d) = %v]", a.TypeID, a.Name, a.AttributeID, a.Value)
+}
+
+// ItemEffect is a dogma effect on an item type.
+type ItemEffect struct {
+	TypeID    int    `db:"typeID"`
+	EffectID  int    `db:"effectID"`
+	Name      string `db:"effectName"`
+	Category  int    `db:"effectCategory"`
+	IsDefault bool   `db:"isDefault"`
+}
+
+func (e ItemEffect) String() string {
+	return fmt.Sprintf("[%d: %s (%d)]", e.TypeID, e.Name, e.EffectID)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package evego
+
+// Fitting is a ship and the modules, drones, and cargo fitted to it.
+type Fitting struct {
+	// Name is the name given to the fitting, e.g. "Armor Vexor".
+	Name string
+	Ship *Item
+	// Modules are the fitted modules, each in its own slot.
+	Modules []FittedModule
+	Drones  []InventoryLine
+	Cargo   []InventoryLine
+}
+
+// FittedModule is a module in one of a ship's slots.
+type FittedModule struct {
+	Item *Item
+	// Slot is the slot that the module occupies: InvLoSlot0 through
+	// InvLoSlot7, InvMedSlot0 through InvMedSlot7, InvHiSlot0 through
+	// InvHiSlot7, InvRigSlot0 through InvRigSlot7, or InvSubSystem0 through
+	// InvSubSystem7.
+	Slot InventoryFlag
+	// Charge is the charge loaded into the module, or nil if there isn't one.
+	Charge *Item
+	// Offline is true if the module is fitted but offline.
+	Offline bool
+}
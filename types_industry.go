@@ -18,7 +18,10 @@ limitations under the License.
 
 package evego
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ActivityType is an industrial activity performed on or resulting in
 // a blueprint.
@@ -35,6 +38,7 @@ const (
 	Duplicating
 	ReverseEngineering
 	Invention
+	Reactions
 )
 
 // IndustryActivity is an action (e.g. invention) taken on an input item
@@ -50,3 +54,25 @@ func (i IndustryActivity) String() string {
 	return fmt.Sprintf("Activity %v: %v -> %d x %v", i.ActivityType, i.InputItem,
 		i.OutputQuantity, i.OutputItem)
 }
+
+// BlueprintActivity identifies one of the activities that can be performed
+// with a blueprint.
+type BlueprintActivity struct {
+	BlueprintID int
+	Activity    ActivityType
+}
+
+// Reaction is a reaction formula along with the materials consumed and
+// produced by one run of the reaction.
+type Reaction struct {
+	Formula *Item
+	Inputs  []InventoryLine
+	Outputs []InventoryLine
+	// Time is the duration of one run before any skill or facility bonuses.
+	Time time.Duration
+}
+
+func (r Reaction) String() string {
+	return fmt.Sprintf("Reaction %v: %v -> %v (%v)", r.Formula, r.Inputs,
+		r.Outputs, r.Time)
+}
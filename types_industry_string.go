@@ -4,9 +4,9 @@ package evego
 
 import "fmt"
 
-const _ActivityType_name = "NoneManufacturingResearchingTechnologyResearchingTEResearchingMECopyingDuplicatingReverseEngineeringInvention"
+const _ActivityType_name = "NoneManufacturingResearchingTechnologyResearchingTEResearchingMECopyingDuplicatingReverseEngineeringInventionReactions"
 
-var _ActivityType_index = [...]uint8{0, 4, 17, 38, 51, 64, 71, 82, 100, 109}
+var _ActivityType_index = [...]uint8{0, 4, 17, 38, 51, 64, 71, 82, 100, 109, 118}
 
 func (i ActivityType) String() string {
 	if i < 0 || i >= ActivityType(len(_ActivityType_index)-1) {
@@ -14,7 +14,7 @@
 	limitations under the License.
 
 */
-//go:generate stringer -output types_items_string.go -type=ItemType
+//go:generate stringer -output types_items_string.go -type=ItemType,MatchKind
 
 package evego
 
@@ -41,6 +41,14 @@ type Item struct {
 	Group     string `db:"groupName"`    // e.g. Omber, Logistic Drone, Footwear
 	GroupID   int    `db:"groupID"`
 	BatchSize int    `db:"portionSize"`
+	// Volume is the item's volume in m³. For ships and containers, it's the
+	// assembled volume; PackagedVolume is the volume when repackaged, and is
+	// the same as Volume for all other items.
+	Volume         float64 `db:"volume"`
+	PackagedVolume float64 `db:"packagedVolume"`
+	Capacity       float64 `db:"capacity"` // cargo or container capacity in m³
+	Mass           float64 `db:"mass"`     // in kg
+	BasePrice      float64 `db:"basePrice"`
 }
 
 func (i Item) String() string {
@@ -54,10 +62,53 @@ type InventoryLine struct {
 	Item     *Item
 }
 
+// Volume returns the total volume in m³ of the items in this line, assuming
+// that any ships or containers are packaged.
+func (i InventoryLine) Volume() float64 {
+	volume := i.Item.PackagedVolume
+	if volume == 0 {
+		volume = i.Item.Volume
+	}
+	return float64(i.Quantity) * volume
+}
+
+// AssembledVolume returns the total volume in m³ of the items in this line,
+// assuming that any ships or containers are assembled.
+func (i InventoryLine) AssembledVolume() float64 {
+	return float64(i.Quantity) * i.Item.Volume
+}
+
 func (i InventoryLine) String() string {
 	return fmt.Sprintf("[%vx %v (%v)]", i.Quantity, i.Item.Name, i.Item.ID)
 }
 
+// MatchKind is the way in which an item search result matched the query.
+type MatchKind int
+
+// MatchKind is the way in which an item search result matched the query. The
+// kinds are listed from best to worst.
+const (
+	ExactMatch     MatchKind = iota // Same name, ignoring case
+	PrefixMatch                     // The name begins with the query
+	SubstringMatch                  // The name contains the query
+	FuzzyMatch                      // The name is within a small edit distance of the query
+)
+
+// ItemMatch is a result of an item search.
+type ItemMatch struct {
+	Item *Item
+	Kind MatchKind
+	// Distance is the edit distance between the item's name and the query,
+	// or -1 if the name is too different for the query to be a misspelling.
+	Distance  int
+	Published bool // the item is published in the game
+	OnMarket  bool // the item can be traded on the market
+}
+
+func (m ItemMatch) String() string {
+	return fmt.Sprintf("%v [%v, distance %d]", m.Item, m.Kind, m.Distance)
+}
+
 // MarketGroup is a group of items in the EVE market.
 type MarketGroup struct {
 	ID          int
@@ -75,3 +126,35 @@ func (m MarketGroup) String() string {
 	}
 	return result
 }
+
+// MarketGroupNode is a market group in the full market hierarchy, along with
+// its subgroups and the items listed directly in it. The group's Parent is the
+// group of the node's parent, if any.
+type MarketGroupNode struct {
+	MarketGroup
+	Children []*MarketGroupNode
+	Items    []*Item
+}
+
+// Find returns the first group named name in this node's subtree (including
+// the node itself), searching depth-first, or nil if there is none.
+func (n *MarketGroupNode) Find(name string) *MarketGroupNode {
+	if n.Name == name {
+		return n
+	}
+	for _, c := range n.Children {
+		if found := c.Find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// AllItems returns the items in this group and all of its subgroups.
+func (n *MarketGroupNode) AllItems() []*Item {
+	items := append([]*Item{}, n.Items...)
+	for _, c := range n.Children {
+		items = append(items, c.AllItems()...)
+	}
+	return items
+}
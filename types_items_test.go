@@ -0,0 +1,50 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package evego_test
+
+import (
+	"testing"
+
+	. "github.com/backerman/evego"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInventoryVolume(t *testing.T) {
+	Convey("Given some inventory", t, func() {
+		tritanium := &Item{Name: "Tritanium", ID: 34, Volume: 0.01, PackagedVolume: 0.01}
+		vexor := &Item{Name: "Vexor", ID: 626, Volume: 115000, PackagedVolume: 10000}
+		legacy := &Item{Name: "Widget", ID: 1, Volume: 5}
+
+		Convey("Ordinary items report their volume.", func() {
+			line := InventoryLine{Quantity: 1000, Item: tritanium}
+			So(line.Volume(), ShouldAlmostEqual, 10.0)
+			So(line.AssembledVolume(), ShouldAlmostEqual, 10.0)
+		})
+
+		Convey("Ships are packaged unless otherwise specified.", func() {
+			line := InventoryLine{Quantity: 2, Item: vexor}
+			So(line.Volume(), ShouldEqual, 20000)
+			So(line.AssembledVolume(), ShouldEqual, 230000)
+		})
+
+		Convey("Items without a packaged volume use their volume.", func() {
+			line := InventoryLine{Quantity: 3, Item: legacy}
+			So(line.Volume(), ShouldEqual, 15)
+		})
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package evego
+
+import "time"
+
+// Killmail is the record of a ship's destruction, in the format published by
+// ESI.
+type Killmail struct {
+	ID            int                `json:"killmail_id"`
+	Time          time.Time          `json:"killmail_time"`
+	SolarSystemID int                `json:"solar_system_id"`
+	Victim        KillmailVictim     `json:"victim"`
+	Attackers     []KillmailAttacker `json:"attackers"`
+}
+
+// KillmailVictim is the pilot (or structure) that was destroyed.
+type KillmailVictim struct {
+	CharacterID   int `json:"character_id"`
+	CorporationID int `json:"corporation_id"`
+	AllianceID    int `json:"alliance_id"`
+	FactionID     int `json:"faction_id"`
+	ShipTypeID    int `json:"ship_type_id"`
+	// Ship is the type of the ship that was destroyed, once looked up.
+	Ship        *Item          `json:"-"`
+	DamageTaken int            `json:"damage_taken"`
+	Items       []KillmailItem `json:"items"`
+}
+
+// ItemsByFlag returns the victim's items, grouped by where in the ship they
+// were.
+func (v *KillmailVictim) ItemsByFlag() map[InventoryFlag][]KillmailItem {
+	items := make(map[InventoryFlag][]KillmailItem)
+	for _, item := range v.Items {
+		items[item.Flag] = append(items[item.Flag], item)
+	}
+	return items
+}
+
+// KillmailAttacker is one of the pilots (or NPCs) credited with a kill.
+type KillmailAttacker struct {
+	CharacterID   int `json:"character_id"`
+	CorporationID int `json:"corporation_id"`
+	AllianceID    int `json:"alliance_id"`
+	FactionID     int `json:"faction_id"`
+	ShipTypeID    int `json:"ship_type_id"`
+	// Ship is the type of the attacker's ship, once looked up.
+	Ship         *Item `json:"-"`
+	WeaponTypeID int   `json:"weapon_type_id"`
+	// Weapon is the type of the attacker's weapon, once looked up.
+	Weapon         *Item   `json:"-"`
+	DamageDone     int     `json:"damage_done"`
+	FinalBlow      bool    `json:"final_blow"`
+	SecurityStatus float64 `json:"security_status"`
+}
+
+// KillmailItem is a stack of items that was in the victim's ship.
+type KillmailItem struct {
+	TypeID int `json:"item_type_id"`
+	// Item is the stack's type, once looked up.
+	Item *Item         `json:"-"`
+	Flag InventoryFlag `json:"flag"`
+	// The quantities of the stack that were destroyed and that dropped.
+	QuantityDestroyed int `json:"quantity_destroyed"`
+	QuantityDropped   int `json:"quantity_dropped"`
+	// Singleton is 2 for a blueprint copy.
+	Singleton int `json:"singleton"`
+	// Items are the contents of a container.
+	Items []KillmailItem `json:"items"`
+}
+
+// BlueprintCopy returns true if the stack is a blueprint copy.
+func (k *KillmailItem) BlueprintCopy() bool {
+	return k.Singleton == 2
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+//go:generate stringer -output types_npc_string.go -type=AgentType
+
+package evego
+
+// Faction is one of the NPC factions in EVE.
+type Faction struct {
+	Name        string `db:"factionName"`
+	ID          int    `db:"factionID"`
+	Description string `db:"description"`
+	// SolarSystemID is the faction's home system.
+	SolarSystemID int `db:"solarSystemID"`
+	// CorporationID is the faction's executor corporation, and
+	// MilitiaCorporationID its factional warfare militia (0 if none).
+	CorporationID        int `db:"corporationID"`
+	MilitiaCorporationID int `db:"militiaCorporationID"`
+}
+
+// NPCCorp is an NPC corporation and the faction it belongs to.
+type NPCCorp struct {
+	Name          string `db:"corporationName"`
+	ID            int    `db:"corporationID"`
+	FactionID     int    `db:"factionID"` // 0 if the corporation has no faction
+	Faction       string `db:"factionName"`
+	SolarSystemID int    `db:"solarSystemID"` // the corporation's headquarters
+}
+
+// AgentType is the kind of missions an agent offers.
+type AgentType int
+
+// The AgentType values, which match the IDs in agtAgentTypes.
+const (
+	UnknownAgentType AgentType = iota
+	NonAgent
+	BasicAgent
+	TutorialAgent
+	ResearchAgent
+	CONCORDAgent
+	GenericStorylineMissionAgent
+	StorylineMissionAgent
+	EventMissionAgent
+	FactionalWarfareAgent
+	EpicArcAgent
+	AuraAgent
+	CareerAgent
+	HeraldryAgent
+)
+
+// Agent is an NPC agent.
+type Agent struct {
+	Name          string    `db:"agentName"`
+	ID            int       `db:"agentID"`
+	Type          AgentType `db:"agentTypeID"`
+	CorporationID int       `db:"corporationID"`
+	DivisionID    int       `db:"divisionID"`
+	Division      string    `db:"divisionName"` // e.g. Security, Distribution
+	Level         int       `db:"level"`
+	// StationID is the station in which the agent is found. Agents in space
+	// have the ID of their solar system instead.
+	StationID int `db:"locationID"`
+}
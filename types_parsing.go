@@ -0,0 +1,76 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+//go:generate stringer -output types_parsing_string.go -type=ParseProblem
+
+package evego
+
+import "fmt"
+
+// PastedInventory is an inventory copied from the EVE client, along with any
+// problems found while reading it.
+type PastedInventory struct {
+	Items       []PastedItem
+	Diagnostics []ParseDiagnostic
+}
+
+// Lines returns the items in the inventory.
+func (p *PastedInventory) Lines() []InventoryLine {
+	lines := make([]InventoryLine, len(p.Items))
+	for i, item := range p.Items {
+		lines[i] = item.InventoryLine
+	}
+	return lines
+}
+
+// PastedItem is one line of a pasted inventory.
+type PastedItem struct {
+	InventoryLine
+	// LineNumber is the line of the pasted text that this item was read
+	// from, starting at 1.
+	LineNumber int
+	// BlueprintType is whether a blueprint is an original or a copy, or
+	// NotBlueprint for other items. Blueprints that the pasted text doesn't
+	// mark as copies are taken to be originals.
+	BlueprintType BlueprintType
+	// EstimatedPrice is the client's estimate of the price of the whole
+	// stack in ISK, or 0 if it wasn't shown.
+	EstimatedPrice float64
+}
+
+// ParseProblem is the reason that a line of pasted text couldn't be read.
+type ParseProblem int
+
+// ParseProblem is the reason that a line of pasted text couldn't be read.
+const (
+	UnrecognizedLine ParseProblem = iota // The line isn't in any known format
+	BadQuantity                          // The quantity isn't a number
+	UnknownItem                          // No item has the given name
+	AmbiguousName                        // Several items are equally close to the given name
+)
+
+// ParseDiagnostic describes a line of pasted text that couldn't be read.
+type ParseDiagnostic struct {
+	LineNumber int // starting at 1
+	Line       string
+	Problem    ParseProblem
+	// Candidates are the items that an ambiguous name could refer to.
+	Candidates []*Item
+}
+
+func (d ParseDiagnostic) String() string {
+	return fmt.Sprintf("Line %d: %v (%q)", d.LineNumber, d.Problem, d.Line)
+}
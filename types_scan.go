@@ -0,0 +1,56 @@
+/*
+Copyright © 2014–5 Brad Ackerman.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package evego
+
+// DScanResult is an object seen on the directional scanner.
+type DScanResult struct {
+	// Name is the object's name, e.g. "Bob's Vexor" or "Jita IV - Moon 4".
+	Name string
+	// Type is the name of the object's type as shown by the scanner.
+	Type string
+	// Item is the object's type, or nil if it isn't in the database.
+	Item *Item
+	// Distance is the distance to the object in metres, or -1 if the scanner
+	// didn't show one.
+	Distance float64
+}
+
+// ProbeScanResult is a signature found by the probe scanner.
+type ProbeScanResult struct {
+	SignatureID string // e.g. "ABC-123"
+	ScanGroup   string // e.g. Cosmic Signature, Cosmic Anomaly, Ship
+	// Group and Name are empty until the signature's signal strength is high
+	// enough for the scanner to show them.
+	Group string // e.g. Data Site, Cruiser
+	Name  string // e.g. Central Guristas Survey Site, Vexor
+	// Item is the signature's type for ships, drones, and structures, or nil
+	// if it isn't known.
+	Item *Item
+	// Signal is the signal strength as a percentage.
+	Signal float64
+	// Distance is the distance to the signature in metres.
+	Distance float64
+}
+
+// DScanCount is the number of objects of one group seen on the directional
+// scanner.
+type DScanCount struct {
+	Category string // e.g. Ship, Drone
+	Group    string // e.g. Cruiser, Combat Drone
+	Count    int
+}
@@ -17,6 +17,8 @@ limitations under the License.
 
 package evego
 
+import "math"
+
 // SolarSystem is a solar system within the EVE universe.
 type SolarSystem struct {
 	Name            string `db:"solarSystemName"`
@@ -28,6 +30,69 @@ type SolarSystem struct {
 	Security        float64
 }
 
+// Position is a location in space, in metres. Constellations are positioned
+// within the universe, and the objects in a solar system relative to its sun.
+type Position struct {
+	X float64 `db:"x"`
+	Y float64 `db:"y"`
+	Z float64 `db:"z"`
+}
+
+// Distance returns the distance in metres between two positions.
+func (p Position) Distance(q Position) float64 {
+	dx, dy, dz := p.X-q.X, p.Y-q.Y, p.Z-q.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// Constellation is a group of solar systems within a region.
+type Constellation struct {
+	Name     string `db:"constellationName"`
+	ID       int    `db:"constellationID"`
+	Region   string `db:"regionName"`
+	RegionID int    `db:"regionID"`
+	Position
+}
+
+// Stargate is a gate linking a solar system to one of its neighbours.
+type Stargate struct {
+	Name     string `db:"itemName"` // e.g. Stargate (Botane)
+	ID       int    `db:"itemID"`
+	SystemID int    `db:"solarSystemID"`
+	// DestinationID is the stargate at the other end of the jump, in the
+	// system DestinationSystemID.
+	DestinationID       int `db:"destinationID"`
+	DestinationSystemID int `db:"destinationSystemID"`
+	Position
+}
+
+// Planet is a planet within a solar system.
+type Planet struct {
+	Name     string `db:"itemName"`
+	ID       int    `db:"itemID"`
+	SystemID int    `db:"solarSystemID"`
+	TypeID   int    `db:"typeID"`
+	Type     string `db:"typeName"` // e.g. Planet (Temperate)
+	Position
+}
+
+// Moon is a moon orbiting a planet.
+type Moon struct {
+	Name     string `db:"itemName"`
+	ID       int    `db:"itemID"`
+	SystemID int    `db:"solarSystemID"`
+	PlanetID int    `db:"orbitID"`
+	Position
+}
+
+// AsteroidBelt is an asteroid belt orbiting a planet.
+type AsteroidBelt struct {
+	Name     string `db:"itemName"`
+	ID       int    `db:"itemID"`
+	SystemID int    `db:"solarSystemID"`
+	PlanetID int    `db:"orbitID"`
+	Position
+}
+
 // Region is one of the regions in the EVE universe.
 type Region struct {
 	Name string `db:"regionName"`